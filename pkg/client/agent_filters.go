@@ -0,0 +1,155 @@
+/*
+ *  *******************************************************************************
+ *  * Copyright (c) 2019 Edgeworx, Inc.
+ *  *
+ *  * This program and the accompanying materials are made available under the
+ *  * terms of the Eclipse Public License v. 2.0 which is available at
+ *  * http://www.eclipse.org/legal/epl-2.0
+ *  *
+ *  * SPDX-License-Identifier: EPL-2.0
+ *  *******************************************************************************
+ *
+ */
+
+package client
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	json "github.com/json-iterator/go"
+)
+
+// Conditions of AgentListFilter understood by the Controller
+const (
+	FilterEquals = "equals"
+	FilterHas    = "has"
+)
+
+type agentPredicate func(agent *AgentInfo) bool
+
+// AgentQuery builds a ListAgentsRequest from typed filters.
+// Filters on text fields are sent to the Controller and checked again on the response in case the Controller ignores them,
+// the other filters are only evaluated by the client.
+type AgentQuery struct {
+	request ListAgentsRequest
+}
+
+// NewAgentQuery starts an empty query, matching every non system Agent
+func NewAgentQuery() *AgentQuery {
+	return &AgentQuery{}
+}
+
+// System includes system Agents instead of regular ones
+func (query *AgentQuery) System(system bool) *AgentQuery {
+	query.request.System = system
+	return query
+}
+
+// Name matches Agents named exactly name
+func (query *AgentQuery) Name(name string) *AgentQuery {
+	return query.filter("name", name, FilterEquals)
+}
+
+// NameContains matches Agents whose name contains substr
+func (query *AgentQuery) NameContains(substr string) *AgentQuery {
+	return query.filter("name", substr, FilterHas)
+}
+
+// DaemonStatus matches Agents whose daemon reports status, e.g. AgentStatusRunning
+func (query *AgentQuery) DaemonStatus(status string) *AgentQuery {
+	return query.filter("daemonStatus", status, FilterEquals)
+}
+
+// Version matches Agents running the given version
+func (query *AgentQuery) Version(version string) *AgentQuery {
+	return query.filter("version", version, FilterEquals)
+}
+
+// Tag matches Agents tagged with tag
+func (query *AgentQuery) Tag(tag string) *AgentQuery {
+	return query.filter("tags", tag, FilterHas)
+}
+
+// FogType matches Agents of the given type: 0 for auto, 1 for x86 and 2 for arm
+func (query *AgentQuery) FogType(fogType int) *AgentQuery {
+	return query.match(func(agent *AgentInfo) bool {
+		return agent.FogType == fogType
+	})
+}
+
+// LastActiveSince matches Agents which were active at or after since
+func (query *AgentQuery) LastActiveSince(since time.Time) *AgentQuery {
+	sinceMs := since.UnixNano() / int64(time.Millisecond)
+	return query.match(func(agent *AgentInfo) bool {
+		return agent.LastActive >= sinceMs
+	})
+}
+
+// Request returns the ListAgentsRequest to pass to ListAgents
+func (query *AgentQuery) Request() ListAgentsRequest {
+	request := query.request
+	request.Filters = append([]AgentListFilter{}, query.request.Filters...)
+	request.predicates = append([]agentPredicate{}, query.request.predicates...)
+	return request
+}
+
+func (query *AgentQuery) filter(key, value, condition string) *AgentQuery {
+	query.request.Filters = append(query.request.Filters, AgentListFilter{Key: key, Value: value, Condition: condition})
+	return query
+}
+
+func (query *AgentQuery) match(predicate agentPredicate) *AgentQuery {
+	query.request.predicates = append(query.request.predicates, predicate)
+	return query
+}
+
+func matchesAgent(agent *AgentInfo, request ListAgentsRequest) bool {
+	for _, predicate := range request.predicates {
+		if !predicate(agent) {
+			return false
+		}
+	}
+	if len(request.Filters) == 0 {
+		return true
+	}
+
+	// Evaluate filters against the JSON fields, like the Controller does
+	fields := make(map[string]interface{})
+	agentBytes, err := json.Marshal(agent)
+	if err != nil {
+		return true
+	}
+	if err := json.Unmarshal(agentBytes, &fields); err != nil {
+		return true
+	}
+	for _, filter := range request.Filters {
+		if !matchesAgentFilter(fields[filter.Key], filter) {
+			return false
+		}
+	}
+	return true
+}
+
+func matchesAgentFilter(value interface{}, filter AgentListFilter) bool {
+	switch filter.Condition {
+	case FilterEquals:
+		return value != nil && fmt.Sprint(value) == filter.Value
+	case FilterHas:
+		switch typed := value.(type) {
+		case string:
+			return strings.Contains(typed, filter.Value)
+		case []interface{}:
+			for _, elem := range typed {
+				if fmt.Sprint(elem) == filter.Value {
+					return true
+				}
+			}
+		}
+		return false
+	default:
+		// Leave conditions unknown to the client to the Controller
+		return true
+	}
+}
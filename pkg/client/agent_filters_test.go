@@ -0,0 +1,56 @@
+/*
+ *  *******************************************************************************
+ *  * Copyright (c) 2019 Edgeworx, Inc.
+ *  *
+ *  * This program and the accompanying materials are made available under the
+ *  * terms of the Eclipse Public License v. 2.0 which is available at
+ *  * http://www.eclipse.org/legal/epl-2.0
+ *  *
+ *  * SPDX-License-Identifier: EPL-2.0
+ *  *******************************************************************************
+ *
+ */
+
+package client_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/eclipse-iofog/iofog-go-sdk/v3/pkg/client"
+	"github.com/eclipse-iofog/iofog-go-sdk/v3/pkg/client/fake/faketest"
+)
+
+func TestAgentQuery(t *testing.T) {
+	ctrl, clt := faketest.NewLoggedIn(t, client.Options{})
+	x86, arm := int64(1), int64(2)
+	agents := []client.AgentUpdateRequest{
+		{Name: "edge-x86", FogType: &x86, Tags: &[]string{"edge", "gpu"}},
+		{Name: "edge-arm", FogType: &arm, Tags: &[]string{"edge"}},
+		{Name: "cloud-x86", FogType: &x86, Tags: &[]string{"cloud"}},
+	}
+	for idx := range agents {
+		if _, err := clt.CreateAgent(&client.CreateAgentRequest{AgentUpdateRequest: agents[idx]}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	query := client.NewAgentQuery().Tag("edge").FogType(1).Request()
+	assertAgents := func(desc string) {
+		response, err := clt.ListAgents(query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(response.Agents) != 1 || response.Agents[0].Name != "edge-x86" {
+			t.Errorf("%s: expected only edge-x86, got %v", desc, response.Agents)
+		}
+	}
+	assertAgents("Controller filters")
+
+	// Controllers which ignore filters must still yield the same result
+	ctrl.Intercept(func(w http.ResponseWriter, r *http.Request) bool {
+		r.URL.RawQuery = "system=false"
+		return false
+	})
+	assertAgents("Client filters")
+}
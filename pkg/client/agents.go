@@ -14,23 +14,33 @@
 package client
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net/url"
 	"strings"
 )
 
 // CreateAgent creates an ioFog Agent using Controller REST API
 func (clt *Client) CreateAgent(request *CreateAgentRequest) (response CreateAgentResponse, err error) {
+	return clt.CreateAgentWithContext(context.Background(), request)
+}
+
+// CreateAgentWithContext is CreateAgent with a context that can cancel the request or bound its deadline
+func (clt *Client) CreateAgentWithContext(ctx context.Context, request *CreateAgentRequest) (response CreateAgentResponse, err error) {
+	ctx = withOperation(ctx, "CreateAgent")
 	if !clt.isLoggedIn() {
 		err = NewError("Controller client must be logged into perform Create Agent request")
 		return
 	}
 
 	// Send request
-	body, err := clt.doRequest("POST", "/iofog", request)
+	body, err := clt.doRequest(ctx, "POST", "/iofog", request)
 	if err != nil {
 		return
 	}
+	clt.names.invalidate(agentNames)
 
 	// TODO: Determine full type returned from this endpoint
 	// Read uuid from response
@@ -50,13 +60,19 @@ func (clt *Client) CreateAgent(request *CreateAgentRequest) (response CreateAgen
 
 // GetAgentProvisionKey get a provisioning key for an ioFog Agent using Controller REST API
 func (clt *Client) GetAgentProvisionKey(uuid string) (response GetAgentProvisionKeyResponse, err error) {
+	return clt.GetAgentProvisionKeyWithContext(context.Background(), uuid)
+}
+
+// GetAgentProvisionKeyWithContext is GetAgentProvisionKey with a context that can cancel the request or bound its deadline
+func (clt *Client) GetAgentProvisionKeyWithContext(ctx context.Context, uuid string) (response GetAgentProvisionKeyResponse, err error) {
+	ctx = withOperation(ctx, "GetAgentProvisionKey")
 	if !clt.isLoggedIn() {
 		err = NewError("Controller client must be logged into perform Get Agent Provisioning Key request")
 		return
 	}
 
 	// Send request
-	body, err := clt.doRequest("GET", fmt.Sprintf("/iofog/%s/provisioning-key", uuid), nil)
+	body, err := clt.doRequest(ctx, "GET", fmt.Sprintf("/iofog/%s/provisioning-key", uuid), nil)
 	if err != nil {
 		return
 	}
@@ -69,34 +85,37 @@ func (clt *Client) GetAgentProvisionKey(uuid string) (response GetAgentProvision
 
 // ListAgents returns all ioFog Agents information using Controller REST API
 func (clt *Client) ListAgents(request ListAgentsRequest) (response ListAgentsResponse, err error) {
-	if !clt.isLoggedIn() {
-		err = NewError("Controller client must be logged into perform List Agents request")
-		return
-	}
+	return clt.ListAgentsWithContext(context.Background(), request)
+}
 
-	// Send request
-	body, err := clt.doRequest("GET", generateListAgentURL(request), nil)
-	if err != nil {
-		return
+// ListAgentsWithContext is ListAgents with a context that can cancel the request or bound its deadline
+func (clt *Client) ListAgentsWithContext(ctx context.Context, request ListAgentsRequest) (response ListAgentsResponse, err error) {
+	ctx = withOperation(ctx, "ListAgents")
+	agents := clt.IterateAgents(ctx, request)
+	defer agents.Close()
+	response.Agents = []AgentInfo{}
+	for agents.Next() {
+		response.Agents = append(response.Agents, agents.Agent())
 	}
-
-	// Return body
-	if err = json.Unmarshal(body, &response); err != nil {
-		return
-	}
-
+	err = agents.Err()
 	return
 }
 
 // GetAgentByID returns an ioFog Agent information using Controller REST API
 func (clt *Client) GetAgentByID(uuid string) (response *AgentInfo, err error) {
+	return clt.GetAgentByIDWithContext(context.Background(), uuid)
+}
+
+// GetAgentByIDWithContext is GetAgentByID with a context that can cancel the request or bound its deadline
+func (clt *Client) GetAgentByIDWithContext(ctx context.Context, uuid string) (response *AgentInfo, err error) {
+	ctx = withOperation(ctx, "GetAgentByID")
 	if !clt.isLoggedIn() {
 		err = NewError("Controller client must be logged into perform Get Agent request")
 		return
 	}
 
 	// Send request
-	body, err := clt.doRequest("GET", fmt.Sprintf("/iofog/%s", uuid), nil)
+	body, err := clt.doRequest(ctx, "GET", fmt.Sprintf("/iofog/%s", uuid), nil)
 	if err != nil {
 		return
 	}
@@ -112,98 +131,157 @@ func (clt *Client) GetAgentByID(uuid string) (response *AgentInfo, err error) {
 
 // UpdateAgent patches an ioFog Agent using Controller REST API
 func (clt *Client) UpdateAgent(request *AgentUpdateRequest) (*AgentInfo, error) {
-	_, err := clt.doRequest("PATCH", fmt.Sprintf("/iofog/%s", request.UUID), request)
+	return clt.UpdateAgentWithContext(context.Background(), request)
+}
+
+// UpdateAgentWithContext is UpdateAgent with a context that can cancel the request or bound its deadline
+func (clt *Client) UpdateAgentWithContext(ctx context.Context, request *AgentUpdateRequest) (*AgentInfo, error) {
+	ctx = withOperation(ctx, "UpdateAgent")
+	_, err := clt.doRequest(ctx, "PATCH", fmt.Sprintf("/iofog/%s", request.UUID), request)
 	if err != nil {
 		return nil, err
 	}
-	return clt.GetAgentByID(request.UUID)
+	clt.names.invalidate(agentNames)
+	return clt.GetAgentByIDWithContext(ctx, request.UUID)
 }
 
 // RebootAgent reboots an ioFog Agent using Controller REST API
 func (clt *Client) RebootAgent(uuid string) (err error) {
-	_, err = clt.doRequest("POST", fmt.Sprintf("/iofog/%s/reboot", uuid), nil)
+	return clt.RebootAgentWithContext(context.Background(), uuid)
+}
+
+// RebootAgentWithContext is RebootAgent with a context that can cancel the request or bound its deadline
+func (clt *Client) RebootAgentWithContext(ctx context.Context, uuid string) (err error) {
+	ctx = withOperation(ctx, "RebootAgent")
+	_, err = clt.doRequest(ctx, "POST", fmt.Sprintf("/iofog/%s/reboot", uuid), nil)
 	return
 }
 
 // DeleteAgent removes an ioFog Agent from the Controller using Controller REST API
 func (clt *Client) DeleteAgent(uuid string) error {
+	return clt.DeleteAgentWithContext(context.Background(), uuid)
+}
+
+// DeleteAgentWithContext is DeleteAgent with a context that can cancel the request or bound its deadline
+func (clt *Client) DeleteAgentWithContext(ctx context.Context, uuid string) error {
+	ctx = withOperation(ctx, "DeleteAgent")
 	if !clt.isLoggedIn() {
 		return NewError("Controller client must be logged into perform Delete Agent request")
 	}
 
 	// Send request
-	if _, err := clt.doRequest("DELETE", fmt.Sprintf("/iofog/%s", uuid), nil); err != nil {
+	if _, err := clt.doRequest(ctx, "DELETE", fmt.Sprintf("/iofog/%s", uuid), nil); err != nil {
 		return err
 	}
+	clt.names.invalidate(agentNames)
 
 	return nil
 }
 
-// GetAgentByName retrieve the agent information by getting all agents then searching for the first occurance in the list
+// GetAgentByName retrieves the agent information, filtering agents by name on the Controller.
+// An AmbiguousError is returned when several agents share the name
 func (clt *Client) GetAgentByName(name string, system bool) (*AgentInfo, error) {
-	list, err := clt.ListAgents(ListAgentsRequest{System: system})
+	return clt.GetAgentByNameWithContext(context.Background(), name, system)
+}
+
+// GetAgentByNameWithContext is GetAgentByName with a context that can cancel the request or bound its deadline
+func (clt *Client) GetAgentByNameWithContext(ctx context.Context, name string, system bool) (*AgentInfo, error) {
+	ctx = withOperation(ctx, "GetAgentByName")
+	cacheName := fmt.Sprintf("%t/%s", system, name)
+	if uuid, cached := clt.names.get(agentNames, cacheName); cached {
+		agent, err := clt.GetAgentByIDWithContext(ctx, uuid)
+		if err == nil && agent.Name == name {
+			return agent, nil
+		}
+		if err != nil && !errors.Is(err, ErrNotFound) {
+			return nil, err
+		}
+		clt.names.remove(agentNames, cacheName)
+	}
+
+	list, err := clt.ListAgentsWithContext(ctx, NewAgentQuery().System(system).Name(name).Request())
 	if err != nil {
 		return nil, err
 	}
+	switch len(list.Agents) {
+	case 0:
+		return nil, NewNotFoundError(fmt.Sprintf("Could not find agent: %s", name))
+	case 1:
+		clt.names.set(agentNames, cacheName, list.Agents[0].UUID)
+		return &list.Agents[0], nil
+	}
+	uuids := make([]string, 0, len(list.Agents))
 	for idx := range list.Agents {
-		if list.Agents[idx].Name == name {
-			return &list.Agents[idx], nil
-		}
+		uuids = append(uuids, list.Agents[idx].UUID)
 	}
-	return nil, NewNotFoundError(fmt.Sprintf("Could not find agent: %s", name))
+	return nil, NewAmbiguousError(string(agentNames), name, uuids)
 }
 
 // PruneAgent prunes an ioFog Agent using Controller REST API
 func (clt *Client) PruneAgent(uuid string) (err error) {
-	_, err = clt.doRequest("POST", fmt.Sprintf("/iofog/%s/prune", uuid), nil)
+	return clt.PruneAgentWithContext(context.Background(), uuid)
+}
+
+// PruneAgentWithContext is PruneAgent with a context that can cancel the request or bound its deadline
+func (clt *Client) PruneAgentWithContext(ctx context.Context, uuid string) (err error) {
+	ctx = withOperation(ctx, "PruneAgent")
+	_, err = clt.doRequest(ctx, "POST", fmt.Sprintf("/iofog/%s/prune", uuid), nil)
 	return
 }
 
 func generateListAgentURL(request ListAgentsRequest) string {
 	// Embed request options into URL as query params
-	url := "/iofog-list?system=false"
+	listURL := "/iofog-list?system=false"
 	if request.System {
-		url = strings.Replace(url, "false", "true", 1)
+		listURL = strings.Replace(listURL, "false", "true", 1)
 	}
 	for idx, filter := range request.Filters {
 		params := []string{
-			fmt.Sprintf("&filters[%d][key]=%s", idx, filter.Key),
-			fmt.Sprintf("&filters[%d][value]=%s", idx, filter.Value),
-			fmt.Sprintf("&filters[%d][condition]=%s", idx, filter.Condition),
+			fmt.Sprintf("&filters[%d][key]=%s", idx, url.QueryEscape(filter.Key)),
+			fmt.Sprintf("&filters[%d][value]=%s", idx, url.QueryEscape(filter.Value)),
+			fmt.Sprintf("&filters[%d][condition]=%s", idx, url.QueryEscape(filter.Condition)),
 		}
 		for _, param := range params {
-			url = fmt.Sprintf("%s%s", url, param)
+			listURL = fmt.Sprintf("%s%s", listURL, param)
 		}
 	}
-	return url
+	return listURL
 }
 
 func (clt *Client) UpgradeAgent(name string) error {
+	return clt.UpgradeAgentWithContext(context.Background(), name)
+}
+
+// UpgradeAgentWithContext is UpgradeAgent with a context that can cancel the request or bound its deadline
+func (clt *Client) UpgradeAgentWithContext(ctx context.Context, name string) error {
+	ctx = withOperation(ctx, "UpgradeAgent")
 	// Get Agent uuid
-	agent, err := clt.GetAgentByName(name, false)
+	agent, err := clt.GetAgentByNameWithContext(ctx, name, false)
 	if err != nil {
 		return err
 	}
 
-	// Send request
-	if _, err := clt.doRequest("POST", fmt.Sprintf("/iofog/%s/version/upgrade", agent.UUID), nil); err != nil {
-		return err
-	}
-
-	return nil
+	return clt.changeAgentVersion(ctx, agent.UUID, "upgrade")
 }
 
 func (clt *Client) RollbackAgent(name string) error {
+	return clt.RollbackAgentWithContext(context.Background(), name)
+}
+
+// RollbackAgentWithContext is RollbackAgent with a context that can cancel the request or bound its deadline
+func (clt *Client) RollbackAgentWithContext(ctx context.Context, name string) error {
+	ctx = withOperation(ctx, "RollbackAgent")
 	// Get Agent uuid
-	agent, err := clt.GetAgentByName(name, false)
+	agent, err := clt.GetAgentByNameWithContext(ctx, name, false)
 	if err != nil {
 		return err
 	}
 
-	// Send request
-	if _, err := clt.doRequest("POST", fmt.Sprintf("/iofog/%s/version/rollback", agent.UUID), nil); err != nil {
-		return err
-	}
+	return clt.changeAgentVersion(ctx, agent.UUID, "rollback")
+}
 
-	return nil
+// changeAgentVersion sends an upgrade or rollback command to the Agent
+func (clt *Client) changeAgentVersion(ctx context.Context, uuid, command string) error {
+	_, err := clt.doRequest(ctx, "POST", fmt.Sprintf("/iofog/%s/version/%s", uuid, command), nil)
+	return err
 }
@@ -15,6 +15,7 @@ package client
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -23,7 +24,13 @@ import (
 
 // GetApplicationByName retrieve application information using the Controller REST API
 func (clt *Client) GetApplicationByName(name string) (application *ApplicationInfo, err error) {
-	body, err := clt.doRequest("GET", fmt.Sprintf("/application/%s", name), nil)
+	return clt.GetApplicationByNameWithContext(context.Background(), name)
+}
+
+// GetApplicationByNameWithContext is GetApplicationByName with a context that can cancel the request or bound its deadline
+func (clt *Client) GetApplicationByNameWithContext(ctx context.Context, name string) (application *ApplicationInfo, err error) {
+	ctx = withOperation(ctx, "GetApplicationByName")
+	body, err := clt.doRequest(ctx, "GET", fmt.Sprintf("/application/%s", name), nil)
 	if err != nil {
 		return
 	}
@@ -37,6 +44,12 @@ func (clt *Client) GetApplicationByName(name string) (application *ApplicationIn
 // CreateApplicationFromYAML creates a new application using the Controller REST API
 // It sends the yaml file to Controller REST API
 func (clt *Client) CreateApplicationFromYAML(file io.Reader) (*ApplicationInfo, error) {
+	return clt.CreateApplicationFromYAMLWithContext(context.Background(), file)
+}
+
+// CreateApplicationFromYAMLWithContext is CreateApplicationFromYAML with a context that can cancel the request or bound its deadline
+func (clt *Client) CreateApplicationFromYAMLWithContext(ctx context.Context, file io.Reader) (*ApplicationInfo, error) {
+	ctx = withOperation(ctx, "CreateApplicationFromYAML")
 	requestBody := &bytes.Buffer{}
 	writer := multipart.NewWriter(requestBody)
 	part, _ := writer.CreateFormFile("application", "application.yaml")
@@ -49,7 +62,7 @@ func (clt *Client) CreateApplicationFromYAML(file io.Reader) (*ApplicationInfo,
 	headers := map[string]string{
 		"Content-Type": writer.FormDataContentType(),
 	}
-	body, err := clt.doRequestWithHeaders("POST", "/application/yaml", requestBody, headers)
+	body, err := clt.doRequestWithHeaders(ctx, "POST", "/application/yaml", requestBody, headers)
 
 	if err != nil {
 		return nil, err
@@ -58,12 +71,18 @@ func (clt *Client) CreateApplicationFromYAML(file io.Reader) (*ApplicationInfo,
 	if err := json.Unmarshal(body, &response); err != nil {
 		return nil, err
 	}
-	return clt.GetApplicationByName(response.Name)
+	return clt.GetApplicationByNameWithContext(ctx, response.Name)
 }
 
 // UpdateApplicationFromYAML updates an application using the Controller REST API
 // It sends the yaml file to Controller REST API
 func (clt *Client) UpdateApplicationFromYAML(name string, file io.Reader) (*ApplicationInfo, error) {
+	return clt.UpdateApplicationFromYAMLWithContext(context.Background(), name, file)
+}
+
+// UpdateApplicationFromYAMLWithContext is UpdateApplicationFromYAML with a context that can cancel the request or bound its deadline
+func (clt *Client) UpdateApplicationFromYAMLWithContext(ctx context.Context, name string, file io.Reader) (*ApplicationInfo, error) {
+	ctx = withOperation(ctx, "UpdateApplicationFromYAML")
 	requestBody := &bytes.Buffer{}
 	writer := multipart.NewWriter(requestBody)
 	part, _ := writer.CreateFormFile("application", "application.yaml")
@@ -77,16 +96,22 @@ func (clt *Client) UpdateApplicationFromYAML(name string, file io.Reader) (*Appl
 		"Content-Type": writer.FormDataContentType(),
 	}
 
-	_, err = clt.doRequestWithHeaders("PUT", fmt.Sprintf("/application/yaml/%s", name), requestBody, headers)
+	_, err = clt.doRequestWithHeaders(ctx, "PUT", fmt.Sprintf("/application/yaml/%s", name), requestBody, headers)
 	if err != nil {
 		return nil, err
 	}
-	return clt.GetApplicationByName(name)
+	return clt.GetApplicationByNameWithContext(ctx, name)
 }
 
 // UpdateApplication patches an application using the Controller REST API
 func (clt *Client) PatchApplication(name string, request *ApplicationPatchRequest) (*ApplicationInfo, error) {
-	_, err := clt.doRequest("PATCH", fmt.Sprintf("/application/%s", name), *request)
+	return clt.PatchApplicationWithContext(context.Background(), name, request)
+}
+
+// PatchApplicationWithContext is PatchApplication with a context that can cancel the request or bound its deadline
+func (clt *Client) PatchApplicationWithContext(ctx context.Context, name string, request *ApplicationPatchRequest) (*ApplicationInfo, error) {
+	ctx = withOperation(ctx, "PatchApplication")
+	_, err := clt.doRequest(ctx, "PATCH", fmt.Sprintf("/application/%s", name), *request)
 	if err != nil {
 		return nil, err
 	}
@@ -94,36 +119,61 @@ func (clt *Client) PatchApplication(name string, request *ApplicationPatchReques
 	if request.Name != nil {
 		newName = *request.Name
 	}
-	return clt.GetApplicationByName(newName)
+	return clt.GetApplicationByNameWithContext(ctx, newName)
 }
 
 // StartApplication set the application as active using the Controller REST API
 func (clt *Client) StartApplication(name string) (*ApplicationInfo, error) {
+	return clt.StartApplicationWithContext(context.Background(), name)
+}
+
+// StartApplicationWithContext is StartApplication with a context that can cancel the request or bound its deadline
+func (clt *Client) StartApplicationWithContext(ctx context.Context, name string) (*ApplicationInfo, error) {
+	ctx = withOperation(ctx, "StartApplication")
 	active := true
-	return clt.PatchApplication(name, &ApplicationPatchRequest{IsActivated: &active})
+	return clt.PatchApplicationWithContext(ctx, name, &ApplicationPatchRequest{IsActivated: &active})
 }
 
 // StopApplication set the application as inactive using the Controller REST API
 func (clt *Client) StopApplication(name string) (*ApplicationInfo, error) {
+	return clt.StopApplicationWithContext(context.Background(), name)
+}
+
+// StopApplicationWithContext is StopApplication with a context that can cancel the request or bound its deadline
+func (clt *Client) StopApplicationWithContext(ctx context.Context, name string) (*ApplicationInfo, error) {
+	ctx = withOperation(ctx, "StopApplication")
 	active := false
-	return clt.PatchApplication(name, &ApplicationPatchRequest{IsActivated: &active})
+	return clt.PatchApplicationWithContext(ctx, name, &ApplicationPatchRequest{IsActivated: &active})
 }
 
 // GetAllApplications retrieve all flows information from the Controller REST API
 func (clt *Client) GetAllApplications() (response *ApplicationListResponse, err error) {
-	body, err := clt.doRequest("GET", "/application", nil)
-	if err != nil {
-		return
+	return clt.GetAllApplicationsWithContext(context.Background())
+}
+
+// GetAllApplicationsWithContext is GetAllApplications with a context that can cancel the request or bound its deadline
+func (clt *Client) GetAllApplicationsWithContext(ctx context.Context) (response *ApplicationListResponse, err error) {
+	ctx = withOperation(ctx, "GetAllApplications")
+	applications := clt.IterateApplications(ctx)
+	defer applications.Close()
+	response = &ApplicationListResponse{Applications: []ApplicationInfo{}}
+	for applications.Next() {
+		response.Applications = append(response.Applications, applications.Application())
 	}
-	response = new(ApplicationListResponse)
-	if err = json.Unmarshal(body, response); err != nil {
-		return
+	if err = applications.Err(); err != nil {
+		return nil, err
 	}
 	return response, nil
 }
 
 // DeleteApplication deletes an application using the Controller REST API
 func (clt *Client) DeleteApplication(name string) (err error) {
-	_, err = clt.doRequest("DELETE", fmt.Sprintf("/application/%s", name), nil)
+	return clt.DeleteApplicationWithContext(context.Background(), name)
+}
+
+// DeleteApplicationWithContext is DeleteApplication with a context that can cancel the request or bound its deadline
+func (clt *Client) DeleteApplicationWithContext(ctx context.Context, name string) (err error) {
+	ctx = withOperation(ctx, "DeleteApplication")
+	_, err = clt.doRequest(ctx, "DELETE", fmt.Sprintf("/application/%s", name), nil)
 	return
 }
@@ -0,0 +1,229 @@
+/*
+ *  *******************************************************************************
+ *  * Copyright (c) 2019 Edgeworx, Inc.
+ *  *
+ *  * This program and the accompanying materials are made available under the
+ *  * terms of the Eclipse Public License v. 2.0 which is available at
+ *  * http://www.eclipse.org/legal/epl-2.0
+ *  *
+ *  * SPDX-License-Identifier: EPL-2.0
+ *  *******************************************************************************
+ *
+ */
+
+// Package backup exports the state of a Controller as iofog.org/v3 multi-document YAML
+// and imports it into another Controller, e.g. for disaster recovery or to clone an environment
+package backup
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/eclipse-iofog/iofog-go-sdk/v3/pkg/apps"
+	"github.com/eclipse-iofog/iofog-go-sdk/v3/pkg/client"
+	"gopkg.in/yaml.v2"
+)
+
+// APIVersion of the exported documents
+const APIVersion = "iofog.org/v3"
+
+// Kinds of the exported documents besides apps.ApplicationTemplateKind, apps.ApplicationKind and apps.RouteKind
+const (
+	RegistryKind     apps.Kind = "Registry"
+	CatalogItemKind  apps.Kind = "CatalogItem"
+	AgentConfigKind  apps.Kind = "AgentConfig"
+	EdgeResourceKind apps.Kind = "EdgeResource"
+)
+
+// kindOrder lists the kinds in dependency order: catalog items use registries,
+// applications run on Agents and use catalog items, routes connect microservices of applications
+var kindOrder = []apps.Kind{
+	RegistryKind,
+	CatalogItemKind,
+	AgentConfigKind,
+	EdgeResourceKind,
+	apps.ApplicationTemplateKind,
+	apps.ApplicationKind,
+	apps.RouteKind,
+}
+
+// Registries built into every Controller are referred to by name rather than URL
+const (
+	remoteRegistryID = 1
+	localRegistryID  = 2
+	remoteRegistry   = "remote"
+	localRegistry    = "local"
+)
+
+// Categories of the catalog items built into every Controller, which are not exported.
+// Catalog items created through the API have no category unless one is given
+var builtInCatalogCategories = map[string]bool{
+	"SYSTEM":    true,
+	"UTILITIES": true,
+}
+
+// Agent types of catalog and microservice images
+const (
+	x86AgentType = 1
+	armAgentType = 2
+)
+
+// AgentConfig is the spec of AgentConfig documents, named after the Agent.
+// Agents are created unprovisioned, see client.ProvisionAgent
+type AgentConfig struct {
+	Location                  string    `yaml:"location,omitempty"`
+	Latitude                  float64   `yaml:"latitude,omitempty"`
+	Longitude                 float64   `yaml:"longitude,omitempty"`
+	Description               string    `yaml:"description,omitempty"`
+	FogType                   *int64    `yaml:"agentType,omitempty"`
+	Tags                      *[]string `yaml:"tags,omitempty"`
+	client.AgentConfiguration `yaml:",inline"`
+}
+
+// Application is the spec of Application documents. Description and IsActivated are patched once the application is deployed,
+// IsActivated being left as the Controller sets it when absent
+type Application struct {
+	Description      string `yaml:"description,omitempty"`
+	IsActivated      *bool  `yaml:"isActivated,omitempty"`
+	apps.Application `yaml:",inline"`
+}
+
+// writeDocuments encodes docs as multi-document YAML
+func writeDocuments(w io.Writer, docs []apps.Header) error {
+	for idx := range docs {
+		bytes, err := yaml.Marshal(&docs[idx])
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(w, "---\n"); err != nil {
+			return err
+		}
+		if _, err := w.Write(bytes); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readDocuments decodes multi-document YAML, skipping empty documents
+func readDocuments(r io.Reader) (docs []apps.Header, err error) {
+	decoder := yaml.NewDecoder(r)
+	for {
+		doc := apps.Header{}
+		if err = decoder.Decode(&doc); err == io.EOF {
+			return docs, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if doc.Kind == "" && doc.Spec == nil {
+			continue
+		}
+		docs = append(docs, doc)
+	}
+}
+
+func newDocument(kind apps.Kind, name string, spec interface{}) apps.Header {
+	return apps.Header{
+		APIVersion: APIVersion,
+		Kind:       kind,
+		Metadata:   apps.HeaderMetadata{Name: name},
+		Spec:       spec,
+	}
+}
+
+func label(kind apps.Kind, name string) string {
+	return fmt.Sprintf("%s/%s", kind, name)
+}
+
+// encodeJSONSpec converts client types, which only carry JSON field names, into a spec using the same names
+func encodeJSONSpec(value interface{}) (interface{}, error) {
+	bytes, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	spec := make(map[string]interface{})
+	err = json.Unmarshal(bytes, &spec)
+	return spec, err
+}
+
+// decodeJSONSpec converts a decoded spec into a client type, see encodeJSONSpec
+func decodeJSONSpec(spec, target interface{}) error {
+	bytes, err := json.Marshal(stringKeys(spec))
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(bytes, target)
+}
+
+// decodeYAMLSpec converts a decoded spec into a type with YAML field names
+func decodeYAMLSpec(spec, target interface{}) error {
+	bytes, err := yaml.Marshal(spec)
+	if err != nil {
+		return err
+	}
+	return yaml.Unmarshal(bytes, target)
+}
+
+// stringKeys converts YAML maps to maps which can be encoded as JSON
+func stringKeys(value interface{}) interface{} {
+	switch typed := value.(type) {
+	case map[interface{}]interface{}:
+		converted := make(map[string]interface{}, len(typed))
+		for key, val := range typed {
+			converted[fmt.Sprint(key)] = stringKeys(val)
+		}
+		return converted
+	case map[string]interface{}:
+		converted := make(map[string]interface{}, len(typed))
+		for key, val := range typed {
+			converted[key] = stringKeys(val)
+		}
+		return converted
+	case []interface{}:
+		converted := make([]interface{}, len(typed))
+		for idx, val := range typed {
+			converted[idx] = stringKeys(val)
+		}
+		return converted
+	default:
+		return value
+	}
+}
+
+// registryRef refers to a registry by URL since IDs differ between Controllers
+func registryRef(id int, urls map[int]string) string {
+	switch id {
+	case 0:
+		return ""
+	case remoteRegistryID:
+		return remoteRegistry
+	case localRegistryID:
+		return localRegistry
+	}
+	if url, ok := urls[id]; ok {
+		return url
+	}
+	return strconv.Itoa(id)
+}
+
+// registryID resolves a reference made by registryRef on the target Controller
+func registryID(ref string, ids map[string]int) (int, error) {
+	switch ref {
+	case "":
+		return 0, nil
+	case remoteRegistry:
+		return remoteRegistryID, nil
+	case localRegistry:
+		return localRegistryID, nil
+	}
+	if id, ok := ids[ref]; ok {
+		return id, nil
+	}
+	if id, err := strconv.Atoi(ref); err == nil {
+		return id, nil
+	}
+	return 0, client.NewNotFoundError(fmt.Sprintf("Registry %s does not exist", ref))
+}
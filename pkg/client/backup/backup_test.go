@@ -0,0 +1,242 @@
+/*
+ *  *******************************************************************************
+ *  * Copyright (c) 2019 Edgeworx, Inc.
+ *  *
+ *  * This program and the accompanying materials are made available under the
+ *  * terms of the Eclipse Public License v. 2.0 which is available at
+ *  * http://www.eclipse.org/legal/epl-2.0
+ *  *
+ *  * SPDX-License-Identifier: EPL-2.0
+ *  *******************************************************************************
+ *
+ */
+
+package backup_test
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/eclipse-iofog/iofog-go-sdk/v3/pkg/client"
+	"github.com/eclipse-iofog/iofog-go-sdk/v3/pkg/client/backup"
+	"github.com/eclipse-iofog/iofog-go-sdk/v3/pkg/client/fake"
+	"github.com/eclipse-iofog/iofog-go-sdk/v3/pkg/client/fake/faketest"
+)
+
+const applicationYAML = `apiVersion: iofog.org/v3
+kind: Application
+metadata:
+  name: func-app
+spec:
+  microservices:
+  - name: heart-rate
+    agent:
+      name: agent-1
+    images:
+      x86: edgeworx/healthcare-heart-rate:x86-v1
+      arm: edgeworx/healthcare-heart-rate:arm-v1
+    container:
+      ports:
+      - internal: 80
+        external: 5000
+    config:
+      test_mode: true
+  - name: heart-rate-ui
+    agent:
+      name: agent-1
+    images:
+      x86: edgeworx/healthcare-heart-rate-ui:x86
+  routes:
+  - name: monitor-to-ui
+    from: heart-rate
+    to: heart-rate-ui
+`
+
+const templateYAML = `apiVersion: iofog.org/v3
+kind: ApplicationTemplate
+metadata:
+  name: func-template
+spec:
+  description: Heart rate monitor
+  variables:
+  - key: agent-name
+    description: Agent to deploy to
+    defaultValue: agent-1
+  application:
+    microservices:
+    - name: heart-rate
+      agent:
+        name: "{{agent-name}}"
+      images:
+        x86: edgeworx/healthcare-heart-rate:x86-v1
+`
+
+func TestExportImport(t *testing.T) {
+	_, src := faketest.NewLoggedIn(t, client.Options{})
+	registryID, err := src.CreateRegistry(&client.RegistryCreateRequest{URL: "registry.example.com", Username: "robot", Email: "robot@example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = src.CreateCatalogItem(&client.CatalogItemCreateRequest{
+		Name:       "heart-rate",
+		Images:     []client.CatalogImage{{ContainerImage: "registry.example.com/heart-rate:x86", AgentTypeID: 1}},
+		RegistryID: registryID,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	tags := []string{"lab"}
+	if _, err = src.CreateAgent(&client.CreateAgentRequest{AgentUpdateRequest: client.AgentUpdateRequest{Name: "agent-1", Location: "Lab 3", Tags: &tags}}); err != nil {
+		t.Fatal(err)
+	}
+	if err = src.CreateHTTPEdgeResource(&client.EdgeResourceMetadata{Name: "sensor", Version: "1.0.0", InterfaceProtocol: "http"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = src.CreateApplicationTemplateFromYAML(strings.NewReader(templateYAML)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = src.CreateApplicationFromYAML(strings.NewReader(applicationYAML)); err != nil {
+		t.Fatal(err)
+	}
+	description, deactivated := "Heart rate monitoring", false
+	if _, err = src.PatchApplication("func-app", &client.ApplicationPatchRequest{Description: &description, IsActivated: &deactivated}); err != nil {
+		t.Fatal(err)
+	}
+
+	exported := &bytes.Buffer{}
+	if err = backup.Export(context.Background(), src, exported); err != nil {
+		t.Fatal(err)
+	}
+	for _, kind := range []string{"Registry", "CatalogItem", "AgentConfig", "EdgeResource", "ApplicationTemplate", "Application", "Route"} {
+		if !strings.Contains(exported.String(), "kind: "+kind+"\n") {
+			t.Errorf("expected a %s document in\n%s", kind, exported)
+		}
+	}
+	// Templates are exported in the YAML shape, like applications
+	if !strings.Contains(exported.String(), "name: '{{agent-name}}'\n") || !strings.Contains(exported.String(), "x86: edgeworx/healthcare-heart-rate:x86-v1\n") {
+		t.Errorf("expected the template microservice to be exported as a spec in\n%s", exported)
+	}
+	if strings.Contains(exported.String(), "name: Router\n") {
+		t.Errorf("expected the built-in catalog items to be left out of\n%s", exported)
+	}
+
+	_, dst := faketest.NewLoggedIn(t, client.Options{})
+	report, err := backup.Import(context.Background(), dst, bytes.NewReader(exported.Bytes()), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Created) != 7 || len(report.Skipped) != 0 {
+		t.Errorf("expected every resource to be created, got %+v", report)
+	}
+
+	registries, err := dst.ListRegistries()
+	if err != nil {
+		t.Fatal(err)
+	}
+	importedRegistry := 0
+	for _, registry := range registries.Registries {
+		if registry.URL == "registry.example.com" {
+			importedRegistry = registry.ID
+		}
+	}
+	item, err := dst.GetCatalogItemByName("heart-rate")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if importedRegistry == 0 || item.RegistryID != importedRegistry {
+		t.Errorf("expected the catalog item to use registry %d, got %d", importedRegistry, item.RegistryID)
+	}
+	agent, err := dst.GetAgentByName("agent-1", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if agent.Location != "Lab 3" || agent.Tags == nil || len(*agent.Tags) != 1 {
+		t.Errorf("expected the Agent configuration to be imported, got %+v", agent)
+	}
+	app, err := dst.GetApplicationByName("func-app")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(app.Microservices) != 2 || app.Microservices[0].AgentUUID != agent.UUID {
+		t.Errorf("expected the microservices to run on the imported Agent, got %+v", app.Microservices)
+	}
+	if app.Description != description || app.IsActivated {
+		t.Errorf("expected the description and activation to be imported, got %q, %t", app.Description, app.IsActivated)
+	}
+	if route, err := dst.GetRoute("func-app", "monitor-to-ui"); err != nil || route.From != "heart-rate" || route.To != "heart-rate-ui" {
+		t.Errorf("expected the route to be imported, got %+v: %v", route, err)
+	}
+	template, err := dst.GetApplicationTemplate("func-template")
+	if err != nil {
+		t.Fatalf("expected the template to be imported: %v", err)
+	}
+	if msvcs := template.Application.Microservices; len(msvcs) != 1 || msvcs[0].(map[string]interface{})["agentName"] != "{{agent-name}}" {
+		t.Errorf("expected the template microservice to keep its Agent variable, got %v", msvcs)
+	}
+	if _, err = dst.GetHTTPEdgeResourceByName("sensor", "1.0.0"); err != nil {
+		t.Errorf("expected the Edge Resource to be imported: %v", err)
+	}
+
+	// Importing again leaves existing resources as is unless updating
+	report, err = backup.Import(context.Background(), dst, bytes.NewReader(exported.Bytes()), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Skipped) != 7 {
+		t.Errorf("expected every resource to be skipped, got %+v", report)
+	}
+	report, err = backup.Import(context.Background(), dst, bytes.NewReader(exported.Bytes()), &backup.ImportOptions{Update: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Skipped) != 0 || len(report.Created)+len(report.Updated) != 7 {
+		t.Errorf("expected every resource to be updated, got %+v", report)
+	}
+}
+
+func TestExportSystemAgentMicroservice(t *testing.T) {
+	newSystemAgent := func(ctrl *fake.Controller, clt *client.Client) string {
+		agent, err := clt.CreateAgent(&client.CreateAgentRequest{AgentUpdateRequest: client.AgentUpdateRequest{Name: "system-1"}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		_ = ctrl.UpdateAgent(agent.UUID, func(agent *client.AgentInfo) { agent.RouterMode = "interior" })
+		return agent.UUID
+	}
+	const systemAppYAML = "apiVersion: iofog.org/v3\nkind: Application\nmetadata:\n  name: proxy\nspec:\n  microservices:\n  - name: proxy\n    agent:\n      name: system-1\n    images:\n      x86: edgeworx/proxy:x86\n"
+
+	srcCtrl, src := faketest.NewLoggedIn(t, client.Options{})
+	newSystemAgent(srcCtrl, src)
+	if _, err := src.CreateApplicationFromYAML(strings.NewReader(systemAppYAML)); err != nil {
+		t.Fatal(err)
+	}
+	exported := &bytes.Buffer{}
+	if err := backup.Export(context.Background(), src, exported); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(exported.String(), "kind: AgentConfig\n") || !strings.Contains(exported.String(), "name: system-1\n") {
+		t.Errorf("expected the microservice to refer to the system Agent, which is not exported, in\n%s", exported)
+	}
+
+	dstCtrl, dst := faketest.NewLoggedIn(t, client.Options{})
+	systemUUID := newSystemAgent(dstCtrl, dst)
+	if _, err := backup.Import(context.Background(), dst, bytes.NewReader(exported.Bytes()), nil); err != nil {
+		t.Fatal(err)
+	}
+	app, err := dst.GetApplicationByName("proxy")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(app.Microservices) != 1 || app.Microservices[0].AgentUUID != systemUUID {
+		t.Errorf("expected the microservice to run on the system Agent, got %+v", app.Microservices)
+	}
+}
+
+func TestImportRejectsUnknownKind(t *testing.T) {
+	_, clt := faketest.NewLoggedIn(t, client.Options{})
+	docs := "apiVersion: iofog.org/v3\nkind: Volume\nmetadata:\n  name: data\nspec: {}\n"
+	if _, err := backup.Import(context.Background(), clt, strings.NewReader(docs), nil); err == nil {
+		t.Error("expected an unknown kind to be rejected")
+	}
+}
@@ -0,0 +1,390 @@
+/*
+ *  *******************************************************************************
+ *  * Copyright (c) 2019 Edgeworx, Inc.
+ *  *
+ *  * This program and the accompanying materials are made available under the
+ *  * terms of the Eclipse Public License v. 2.0 which is available at
+ *  * http://www.eclipse.org/legal/epl-2.0
+ *  *
+ *  * SPDX-License-Identifier: EPL-2.0
+ *  *******************************************************************************
+ *
+ */
+
+package backup
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/eclipse-iofog/iofog-go-sdk/v3/pkg/apps"
+	"github.com/eclipse-iofog/iofog-go-sdk/v3/pkg/client"
+)
+
+// Export writes registries, catalog items, Agent configurations, Edge Resources, application templates,
+// applications and routes of the Controller to w, in the order Import recreates them.
+// System Agents and applications are left out, as are built-in catalog items and registry passwords which the Controller does not return.
+// Microservices refer to their Agent by name, including system Agents which must then exist on the target Controller.
+// Edge Resources and application templates are skipped when the Controller does not support them
+func Export(ctx context.Context, clt *client.Client, w io.Writer) error {
+	exporter := exporter{
+		clt:          clt,
+		registryURLs: make(map[int]string),
+		agentNames:   make(map[string]string),
+		appNames:     make(map[string]bool),
+	}
+	steps := []func(ctx context.Context) error{
+		exporter.registries,
+		exporter.catalog,
+		exporter.agents,
+		exporter.edgeResources,
+		exporter.templates,
+		exporter.applications,
+		exporter.routes,
+	}
+	for _, step := range steps {
+		if err := step(ctx); err != nil {
+			return err
+		}
+	}
+	return writeDocuments(w, exporter.docs)
+}
+
+type exporter struct {
+	clt          *client.Client
+	docs         []apps.Header
+	registryURLs map[int]string
+	agentNames   map[string]string
+	appNames     map[string]bool
+}
+
+func (exp *exporter) add(kind apps.Kind, name string, spec interface{}) {
+	exp.docs = append(exp.docs, newDocument(kind, name, spec))
+}
+
+func (exp *exporter) addJSON(kind apps.Kind, name string, value interface{}) error {
+	spec, err := encodeJSONSpec(value)
+	if err != nil {
+		return err
+	}
+	exp.add(kind, name, spec)
+	return nil
+}
+
+func (exp *exporter) registries(ctx context.Context) error {
+	response, err := exp.clt.ListRegistriesWithContext(ctx)
+	if err != nil {
+		return err
+	}
+	for _, registry := range response.Registries {
+		exp.registryURLs[registry.ID] = registry.URL
+		if registry.ID == remoteRegistryID || registry.ID == localRegistryID {
+			continue
+		}
+		spec := client.RegistryCreateRequest{
+			URL:          registry.URL,
+			IsPublic:     registry.IsPublic,
+			Certificate:  registry.Certificate,
+			RequiresCert: registry.RequiresCert,
+			Username:     registry.Username,
+			Email:        registry.Email,
+		}
+		if err := exp.addJSON(RegistryKind, registry.URL, spec); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (exp *exporter) catalog(ctx context.Context) error {
+	response, err := exp.clt.GetCatalogWithContext(ctx)
+	if err != nil {
+		return err
+	}
+	for _, item := range response.CatalogItems {
+		if builtInCatalogCategories[item.Category] {
+			continue
+		}
+		spec := apps.CatalogItem{
+			Name:        item.Name,
+			Description: item.Description,
+			Registry:    registryRef(item.RegistryID, exp.registryURLs),
+		}
+		for _, image := range item.Images {
+			switch image.AgentTypeID {
+			case x86AgentType:
+				spec.X86 = image.ContainerImage
+			case armAgentType:
+				spec.ARM = image.ContainerImage
+			}
+		}
+		exp.add(CatalogItemKind, item.Name, spec)
+	}
+	return nil
+}
+
+func (exp *exporter) agents(ctx context.Context) error {
+	response, err := exp.clt.ListAgentsWithContext(ctx, client.ListAgentsRequest{})
+	if err != nil {
+		return err
+	}
+	for idx := range response.Agents {
+		agent := &response.Agents[idx]
+		exp.agentNames[agent.UUID] = agent.Name
+		exp.add(AgentConfigKind, agent.Name, agentConfig(agent))
+	}
+	// System Agents are not exported but microservices may run on them
+	system, err := exp.clt.ListAgentsWithContext(ctx, client.ListAgentsRequest{System: true})
+	if err != nil {
+		return err
+	}
+	for _, agent := range system.Agents {
+		exp.agentNames[agent.UUID] = agent.Name
+	}
+	return nil
+}
+
+func agentConfig(agent *client.AgentInfo) AgentConfig {
+	fogType := int64(agent.FogType)
+	config := AgentConfig{
+		Location:    agent.Location,
+		Latitude:    agent.Latitude,
+		Longitude:   agent.Longitude,
+		Description: agent.Description,
+		FogType:     &fogType,
+		Tags:        agent.Tags,
+		AgentConfiguration: client.AgentConfiguration{
+			DockerURL:                 &agent.DockerURL,
+			DiskLimit:                 &agent.DiskLimit,
+			DiskDirectory:             &agent.DiskDirectory,
+			MemoryLimit:               &agent.MemoryLimit,
+			CPULimit:                  &agent.CPULimit,
+			LogLimit:                  &agent.LogLimit,
+			LogDirectory:              &agent.LogDirectory,
+			LogFileCount:              &agent.LogFileCount,
+			StatusFrequency:           &agent.StatusFrequency,
+			ChangeFrequency:           &agent.ChangeFrequency,
+			DeviceScanFrequency:       &agent.DeviceScanFrequency,
+			BluetoothEnabled:          &agent.BluetoothEnabled,
+			WatchdogEnabled:           &agent.WatchdogEnabled,
+			AbstractedHardwareEnabled: &agent.AbstractedHardwareEnabled,
+			UpstreamRouters:           agent.UpstreamRouters,
+			NetworkRouter:             agent.NetworkRouter,
+			LogLevel:                  agent.LogLevel,
+			DockerPruningFrequency:    agent.DockerPruningFrequency,
+			AvailableDiskThreshold:    agent.AvailableDiskThreshold,
+			RouterConfig: client.RouterConfig{
+				MessagingPort:   agent.MessagingPort,
+				EdgeRouterPort:  agent.EdgeRouterPort,
+				InterRouterPort: agent.InterRouterPort,
+			},
+		},
+	}
+	if agent.Host != "" {
+		config.Host = &agent.Host
+	}
+	if agent.RouterMode != "" {
+		config.RouterMode = &agent.RouterMode
+	}
+	return config
+}
+
+func (exp *exporter) edgeResources(ctx context.Context) error {
+	response, err := exp.clt.ListEdgeResourcesWithContext(ctx)
+	if errors.Is(err, client.ErrNotSupported) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	for idx := range response.EdgeResources {
+		resource := &response.EdgeResources[idx]
+		if err := exp.addJSON(EdgeResourceKind, resource.Name, resource); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (exp *exporter) templates(ctx context.Context) error {
+	response, err := exp.clt.ListApplicationTemplatesWithContext(ctx)
+	if errors.Is(err, client.ErrNotSupported) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	for idx := range response.ApplicationTemplates {
+		template := &response.ApplicationTemplates[idx]
+		spec, err := exp.template(template)
+		if err != nil {
+			return err
+		}
+		exp.add(apps.ApplicationTemplateKind, template.Name, spec)
+	}
+	return nil
+}
+
+// templateMicroservice is a microservice of an application template, which the Controller returns
+// with the name of its Agent since it may be a template variable
+type templateMicroservice struct {
+	client.MicroserviceInfo
+	AgentName string `json:"agentName"`
+}
+
+// template converts an application template into its spec, its microservices like those of applications
+func (exp *exporter) template(template *client.ApplicationTemplate) (spec apps.ApplicationTemplate, err error) {
+	spec.Description = template.Description
+	for _, variable := range template.Variables {
+		spec.Variables = append(spec.Variables, apps.TemplateVariable{
+			Key:          variable.Key,
+			Description:  variable.Description,
+			DefaultValue: variable.DefaultValue,
+			Value:        variable.Value,
+		})
+	}
+	if template.Application == nil {
+		return
+	}
+	spec.Application = &apps.ApplicationTemplateInfo{}
+	for _, item := range template.Application.Microservices {
+		info := templateMicroservice{}
+		if err = decodeJSONSpec(item, &info); err != nil {
+			return
+		}
+		var msvc apps.Microservice
+		if msvc, err = exp.microservice(&info.MicroserviceInfo); err != nil {
+			return
+		}
+		msvc.Agent.Name = info.AgentName
+		spec.Application.Microservices = append(spec.Application.Microservices, msvc)
+	}
+	for _, item := range template.Application.Routes {
+		route := apps.Route{}
+		if err = decodeJSONSpec(item, &route); err != nil {
+			return
+		}
+		spec.Application.Routes = append(spec.Application.Routes, route)
+	}
+	return
+}
+
+func (exp *exporter) applications(ctx context.Context) error {
+	response, err := exp.clt.GetAllApplicationsWithContext(ctx)
+	if err != nil {
+		return err
+	}
+	for idx := range response.Applications {
+		application := &response.Applications[idx]
+		if application.IsSystem {
+			continue
+		}
+		exp.appNames[application.Name] = true
+		isActivated := application.IsActivated
+		spec := Application{
+			Description: application.Description,
+			IsActivated: &isActivated,
+			Application: apps.Application{Name: application.Name},
+		}
+		for msvcIdx := range application.Microservices {
+			info := &application.Microservices[msvcIdx]
+			agentName, found := exp.agentNames[info.AgentUUID]
+			if !found {
+				return client.NewNotFoundError(fmt.Sprintf("Could not find Agent %s of microservice %s/%s", info.AgentUUID, application.Name, info.Name))
+			}
+			msvc, err := exp.microservice(info)
+			if err != nil {
+				return err
+			}
+			msvc.Agent.Name = agentName
+			spec.Microservices = append(spec.Microservices, msvc)
+		}
+		exp.add(apps.ApplicationKind, application.Name, spec)
+	}
+	return nil
+}
+
+// microservice converts a microservice into its spec, leaving the Agent to the caller. Images are exported rather than
+// the catalog item they come from, since catalog IDs differ between Controllers
+func (exp *exporter) microservice(info *client.MicroserviceInfo) (msvc apps.Microservice, err error) {
+	msvc = apps.Microservice{
+		Name: info.Name,
+		Container: apps.MicroserviceContainer{
+			Commands:       info.Commands,
+			RootHostAccess: info.RootHostAccess,
+		},
+	}
+	if len(info.Images) > 0 {
+		msvc.Images = &apps.MicroserviceImages{Registry: registryRef(info.RegistryID, exp.registryURLs)}
+		for _, image := range info.Images {
+			switch image.AgentTypeID {
+			case x86AgentType:
+				msvc.Images.X86 = image.ContainerImage
+			case armAgentType:
+				msvc.Images.ARM = image.ContainerImage
+			}
+		}
+	}
+	if info.Config != "" {
+		config := apps.NestedMap{}
+		if err = json.Unmarshal([]byte(info.Config), &config); err != nil {
+			return
+		}
+		if len(config) > 0 {
+			msvc.Config = config
+		}
+	}
+	for _, port := range info.Ports {
+		mapping := apps.MicroservicePortMapping{Internal: port.Internal, External: port.External, Protocol: port.Protocol}
+		if port.Public != nil {
+			mapping.Public = &apps.MicroservicePublicPortInfo{
+				Schemes:  port.Public.Schemes,
+				Protocol: port.Public.Protocol,
+				Enabled:  port.Public.Enabled,
+			}
+			if port.Public.Router != nil {
+				mapping.Public.Router = &apps.MicroservicePublicPortRouterInfo{Port: port.Public.Router.Port, Host: port.Public.Router.Host}
+			}
+		}
+		msvc.Container.Ports = append(msvc.Container.Ports, mapping)
+	}
+	if len(info.Volumes) > 0 {
+		volumes := make([]apps.MicroserviceVolumeMapping, 0, len(info.Volumes))
+		for _, volume := range info.Volumes {
+			volumes = append(volumes, apps.MicroserviceVolumeMapping(volume))
+		}
+		msvc.Container.Volumes = &volumes
+	}
+	if len(info.Env) > 0 {
+		env := make([]apps.MicroserviceEnvironment, 0, len(info.Env))
+		for _, variable := range info.Env {
+			env = append(env, apps.MicroserviceEnvironment(variable))
+		}
+		msvc.Container.Env = &env
+	}
+	if len(info.ExtraHosts) > 0 {
+		hosts := make([]apps.MicroserviceExtraHost, 0, len(info.ExtraHosts))
+		for _, host := range info.ExtraHosts {
+			hosts = append(hosts, apps.MicroserviceExtraHost(host))
+		}
+		msvc.Container.ExtraHosts = &hosts
+	}
+	return
+}
+
+func (exp *exporter) routes(ctx context.Context) error {
+	response, err := exp.clt.ListRoutesWithContext(ctx)
+	if err != nil {
+		return err
+	}
+	for _, route := range response.Routes {
+		if !exp.appNames[route.Application] {
+			continue
+		}
+		exp.add(apps.RouteKind, route.Application+"/"+route.Name, apps.Route{Name: route.Name, From: route.From, To: route.To})
+	}
+	return nil
+}
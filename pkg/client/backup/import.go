@@ -0,0 +1,381 @@
+/*
+ *  *******************************************************************************
+ *  * Copyright (c) 2019 Edgeworx, Inc.
+ *  *
+ *  * This program and the accompanying materials are made available under the
+ *  * terms of the Eclipse Public License v. 2.0 which is available at
+ *  * http://www.eclipse.org/legal/epl-2.0
+ *  *
+ *  * SPDX-License-Identifier: EPL-2.0
+ *  *******************************************************************************
+ *
+ */
+
+package backup
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/eclipse-iofog/iofog-go-sdk/v3/pkg/apps"
+	"github.com/eclipse-iofog/iofog-go-sdk/v3/pkg/client"
+	"gopkg.in/yaml.v2"
+)
+
+// ImportOptions configures Import
+type ImportOptions struct {
+	// Update replaces resources which already exist on the Controller, they are left as is otherwise
+	Update bool
+}
+
+// ImportReport lists the imported resources as <kind>/<name>, e.g. "Application/my-app"
+type ImportReport struct {
+	Created []string
+	Updated []string
+	// Skipped resources already existed on the Controller
+	Skipped []string
+}
+
+type outcome int
+
+const (
+	created outcome = iota
+	updated
+	skipped
+)
+
+func (report *ImportReport) add(result outcome, name string) {
+	switch result {
+	case created:
+		report.Created = append(report.Created, name)
+	case updated:
+		report.Updated = append(report.Updated, name)
+	case skipped:
+		report.Skipped = append(report.Skipped, name)
+	}
+}
+
+type importer struct {
+	clt         *client.Client
+	update      bool
+	registryIDs map[string]int
+}
+
+// Import recreates the resources written by Export on the Controller in dependency order, whatever the order
+// of the documents in r. Resources are matched by name and those which already exist are skipped unless opts.Update is set.
+// Import stops at the first failure, the report then lists the resources imported until then
+func Import(ctx context.Context, clt *client.Client, r io.Reader, opts *ImportOptions) (*ImportReport, error) {
+	docs, err := readDocuments(r)
+	if err != nil {
+		return nil, err
+	}
+	importers := map[apps.Kind]func(ctx context.Context, imp *importer, doc *apps.Header) (outcome, error){
+		RegistryKind:                 importRegistry,
+		CatalogItemKind:              importCatalogItem,
+		AgentConfigKind:              importAgentConfig,
+		EdgeResourceKind:             importEdgeResource,
+		apps.ApplicationTemplateKind: importTemplate,
+		apps.ApplicationKind:         importApplication,
+		apps.RouteKind:               importRoute,
+	}
+	byKind := make(map[apps.Kind][]*apps.Header)
+	for idx := range docs {
+		doc := &docs[idx]
+		if doc.APIVersion != APIVersion {
+			return nil, client.NewInputError(fmt.Sprintf("Unsupported apiVersion %s of %s, expected %s", doc.APIVersion, label(doc.Kind, doc.Metadata.Name), APIVersion))
+		}
+		if _, ok := importers[doc.Kind]; !ok {
+			return nil, client.NewInputError(fmt.Sprintf("Unsupported kind %s", doc.Kind))
+		}
+		if doc.Metadata.Name == "" {
+			return nil, client.NewInputError(fmt.Sprintf("A %s document has no metadata.name", doc.Kind))
+		}
+		byKind[doc.Kind] = append(byKind[doc.Kind], doc)
+	}
+
+	imp := &importer{clt: clt, registryIDs: make(map[string]int)}
+	if opts != nil {
+		imp.update = opts.Update
+	}
+	registries, err := clt.ListRegistriesWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, registry := range registries.Registries {
+		imp.registryIDs[registry.URL] = registry.ID
+	}
+
+	report := &ImportReport{}
+	for _, kind := range kindOrder {
+		for _, doc := range byKind[kind] {
+			result, err := importers[kind](ctx, imp, doc)
+			if err != nil {
+				return report, fmt.Errorf("failed to import %s: %w", label(doc.Kind, doc.Metadata.Name), err)
+			}
+			report.add(result, label(doc.Kind, doc.Metadata.Name))
+		}
+	}
+	return report, nil
+}
+
+// exists interprets the error of looking a resource up
+func exists(err error) (bool, error) {
+	switch {
+	case err == nil:
+		return true, nil
+	case errors.Is(err, client.ErrNotFound):
+		return false, nil
+	default:
+		return false, err
+	}
+}
+
+func importRegistry(ctx context.Context, imp *importer, doc *apps.Header) (outcome, error) {
+	spec := client.RegistryCreateRequest{}
+	if err := decodeJSONSpec(doc.Spec, &spec); err != nil {
+		return 0, err
+	}
+	spec.URL = doc.Metadata.Name
+	id, found := imp.registryIDs[spec.URL]
+	if !found {
+		newID, err := imp.clt.CreateRegistryWithContext(ctx, &spec)
+		if err != nil {
+			return 0, err
+		}
+		imp.registryIDs[spec.URL] = newID
+		return created, nil
+	}
+	if !imp.update {
+		return skipped, nil
+	}
+	request := client.RegistryUpdateRequest{
+		ID:           id,
+		URL:          &spec.URL,
+		IsPublic:     &spec.IsPublic,
+		Certificate:  &spec.Certificate,
+		RequiresCert: &spec.RequiresCert,
+		Username:     &spec.Username,
+		Email:        &spec.Email,
+	}
+	if spec.Password != "" {
+		request.Password = &spec.Password
+	}
+	return updated, imp.clt.UpdateRegistryWithContext(ctx, request)
+}
+
+func importCatalogItem(ctx context.Context, imp *importer, doc *apps.Header) (outcome, error) {
+	spec := apps.CatalogItem{}
+	if err := decodeYAMLSpec(doc.Spec, &spec); err != nil {
+		return 0, err
+	}
+	registry, err := registryID(spec.Registry, imp.registryIDs)
+	if err != nil {
+		return 0, err
+	}
+	images := []client.CatalogImage{}
+	if spec.X86 != "" {
+		images = append(images, client.CatalogImage{ContainerImage: spec.X86, AgentTypeID: x86AgentType})
+	}
+	if spec.ARM != "" {
+		images = append(images, client.CatalogImage{ContainerImage: spec.ARM, AgentTypeID: armAgentType})
+	}
+
+	existing, err := imp.clt.GetCatalogItemByNameWithContext(ctx, doc.Metadata.Name)
+	found, err := exists(err)
+	switch {
+	case err != nil:
+		return 0, err
+	case !found:
+		_, err = imp.clt.CreateCatalogItemWithContext(ctx, &client.CatalogItemCreateRequest{
+			Name:        doc.Metadata.Name,
+			Description: spec.Description,
+			Images:      images,
+			RegistryID:  registry,
+		})
+		return created, err
+	case !imp.update:
+		return skipped, nil
+	}
+	_, err = imp.clt.UpdateCatalogItemWithContext(ctx, &client.CatalogItemUpdateRequest{
+		ID:          existing.ID,
+		Name:        doc.Metadata.Name,
+		Description: spec.Description,
+		Images:      images,
+		RegistryID:  registry,
+	})
+	return updated, err
+}
+
+func importAgentConfig(ctx context.Context, imp *importer, doc *apps.Header) (outcome, error) {
+	spec := AgentConfig{}
+	if err := decodeYAMLSpec(doc.Spec, &spec); err != nil {
+		return 0, err
+	}
+	request := client.AgentUpdateRequest{
+		Name:               doc.Metadata.Name,
+		Location:           spec.Location,
+		Latitude:           spec.Latitude,
+		Longitude:          spec.Longitude,
+		Description:        spec.Description,
+		FogType:            spec.FogType,
+		Tags:               spec.Tags,
+		AgentConfiguration: spec.AgentConfiguration,
+	}
+
+	existing, err := imp.clt.GetAgentByNameWithContext(ctx, doc.Metadata.Name, false)
+	found, err := exists(err)
+	switch {
+	case err != nil:
+		return 0, err
+	case !found:
+		_, err = imp.clt.CreateAgentWithContext(ctx, &client.CreateAgentRequest{AgentUpdateRequest: request})
+		return created, err
+	case !imp.update:
+		return skipped, nil
+	}
+	request.UUID = existing.UUID
+	_, err = imp.clt.UpdateAgentWithContext(ctx, &request)
+	return updated, err
+}
+
+func importEdgeResource(ctx context.Context, imp *importer, doc *apps.Header) (outcome, error) {
+	spec := client.EdgeResourceMetadata{}
+	if err := decodeJSONSpec(doc.Spec, &spec); err != nil {
+		return 0, err
+	}
+	spec.Name = doc.Metadata.Name
+
+	_, err := imp.clt.GetHTTPEdgeResourceByNameWithContext(ctx, spec.Name, spec.Version)
+	found, err := exists(err)
+	switch {
+	case err != nil:
+		return 0, err
+	case !found:
+		return created, imp.clt.CreateHTTPEdgeResourceWithContext(ctx, &spec)
+	case !imp.update:
+		return skipped, nil
+	}
+	return updated, imp.clt.UpdateHTTPEdgeResourceWithContext(ctx, spec.Name, &spec)
+}
+
+func importTemplate(ctx context.Context, imp *importer, doc *apps.Header) (outcome, error) {
+	spec := apps.ApplicationTemplate{}
+	if err := decodeYAMLSpec(doc.Spec, &spec); err != nil {
+		return 0, err
+	}
+	if spec.Application != nil {
+		if err := imp.resolveRegistries(spec.Application.Microservices); err != nil {
+			return 0, err
+		}
+	}
+	file, err := yaml.Marshal(newDocument(doc.Kind, doc.Metadata.Name, spec))
+	if err != nil {
+		return 0, err
+	}
+
+	_, err = imp.clt.GetApplicationTemplateWithContext(ctx, doc.Metadata.Name)
+	found, err := exists(err)
+	switch {
+	case err != nil:
+		return 0, err
+	case !found:
+		_, err = imp.clt.CreateApplicationTemplateFromYAMLWithContext(ctx, bytes.NewReader(file))
+		return created, err
+	case !imp.update:
+		return skipped, nil
+	}
+	_, err = imp.clt.UpdateApplicationTemplateFromYAMLWithContext(ctx, doc.Metadata.Name, bytes.NewReader(file))
+	return updated, err
+}
+
+func importApplication(ctx context.Context, imp *importer, doc *apps.Header) (outcome, error) {
+	document := Application{}
+	if err := decodeYAMLSpec(doc.Spec, &document); err != nil {
+		return 0, err
+	}
+	spec := document.Application
+	spec.Name = doc.Metadata.Name
+	if err := imp.resolveRegistries(spec.Microservices); err != nil {
+		return 0, err
+	}
+	file, err := yaml.Marshal(newDocument(doc.Kind, doc.Metadata.Name, spec))
+	if err != nil {
+		return 0, err
+	}
+
+	_, err = imp.clt.GetApplicationByNameWithContext(ctx, doc.Metadata.Name)
+	found, err := exists(err)
+	result := updated
+	switch {
+	case err != nil:
+		return 0, err
+	case !found:
+		result = created
+		_, err = imp.clt.CreateApplicationFromYAMLWithContext(ctx, bytes.NewReader(file))
+	case !imp.update:
+		return skipped, nil
+	default:
+		_, err = imp.clt.UpdateApplicationFromYAMLWithContext(ctx, doc.Metadata.Name, bytes.NewReader(file))
+	}
+	if err != nil {
+		return result, err
+	}
+
+	// The application YAML carries neither the description nor the activation
+	patch := &client.ApplicationPatchRequest{IsActivated: document.IsActivated}
+	if document.Description != "" {
+		patch.Description = &document.Description
+	}
+	if patch.Description == nil && patch.IsActivated == nil {
+		return result, nil
+	}
+	_, err = imp.clt.PatchApplicationWithContext(ctx, doc.Metadata.Name, patch)
+	return result, err
+}
+
+// resolveRegistries replaces the registries of microservices with their IDs, which the Controller expects
+// and which differ from those of the exported Controller
+func (imp *importer) resolveRegistries(microservices []apps.Microservice) error {
+	for idx := range microservices {
+		images := microservices[idx].Images
+		if images == nil || images.Registry == remoteRegistry || images.Registry == localRegistry {
+			continue
+		}
+		id, err := registryID(images.Registry, imp.registryIDs)
+		if err != nil {
+			return err
+		}
+		images.Registry = strconv.Itoa(id)
+	}
+	return nil
+}
+
+// importRoute recreates a route named <application>/<route>
+func importRoute(ctx context.Context, imp *importer, doc *apps.Header) (outcome, error) {
+	spec := apps.Route{}
+	if err := decodeYAMLSpec(doc.Spec, &spec); err != nil {
+		return 0, err
+	}
+	application, name, ok := strings.Cut(doc.Metadata.Name, "/")
+	if !ok || application == "" || name == "" {
+		return 0, client.NewInputError(fmt.Sprintf("Route name %s is not of the form <application>/<route>", doc.Metadata.Name))
+	}
+	route := client.Route{Name: name, Application: application, From: spec.From, To: spec.To}
+
+	_, err := imp.clt.GetRouteWithContext(ctx, application, name)
+	found, err := exists(err)
+	switch {
+	case err != nil:
+		return 0, err
+	case !found:
+		return created, imp.clt.CreateRouteWithContext(ctx, &route)
+	case !imp.update:
+		return skipped, nil
+	}
+	return updated, imp.clt.UpdateRouteWithContext(ctx, &route)
+}
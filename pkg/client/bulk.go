@@ -0,0 +1,299 @@
+/*
+ *  *******************************************************************************
+ *  * Copyright (c) 2019 Edgeworx, Inc.
+ *  *
+ *  * This program and the accompanying materials are made available under the
+ *  * terms of the Eclipse Public License v. 2.0 which is available at
+ *  * http://www.eclipse.org/legal/epl-2.0
+ *  *
+ *  * SPDX-License-Identifier: EPL-2.0
+ *  *******************************************************************************
+ *
+ */
+
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const defaultBulkConcurrency = 10
+
+// AgentSelector selects the Agents of a bulk operation.
+// Names, UUIDs and Query are combined and every Agent is only operated on once
+type AgentSelector struct {
+	// Names of non system Agents
+	Names []string
+	UUIDs []string
+	// Query lists Agents, e.g. NewAgentQuery().Tag("edge").Request()
+	Query *ListAgentsRequest
+}
+
+// BulkOptions configures bulk Agent operations. A nil *BulkOptions uses the defaults
+type BulkOptions struct {
+	// Concurrency bounds the number of Agents operated on at the same time, 10 by default
+	Concurrency int
+	// RateLimit bounds the number of operations started per second, unlimited when 0
+	RateLimit float64
+	// OnResult is called as soon as the operation on an Agent completes. It may be called concurrently
+	OnResult func(result AgentResult)
+}
+
+func (opts *BulkOptions) concurrency() int {
+	if opts == nil || opts.Concurrency <= 0 {
+		return defaultBulkConcurrency
+	}
+	return opts.Concurrency
+}
+
+func (opts *BulkOptions) rateInterval() time.Duration {
+	if opts == nil || opts.RateLimit <= 0 {
+		return 0
+	}
+	return time.Duration(float64(time.Second) / opts.RateLimit)
+}
+
+func (opts *BulkOptions) result(result AgentResult) {
+	if opts != nil && opts.OnResult != nil {
+		opts.OnResult(result)
+	}
+}
+
+// AgentResult is the outcome of a bulk operation on one Agent.
+// UUID or Name is empty when the Agent selected by the other could not be found
+type AgentResult struct {
+	UUID     string
+	Name     string
+	Err      error
+	Duration time.Duration
+}
+
+// BulkReport holds the result of each selected Agent, in selection order
+type BulkReport struct {
+	Results []AgentResult
+}
+
+// Succeeded returns the results without error
+func (report *BulkReport) Succeeded() (results []AgentResult) {
+	for _, result := range report.Results {
+		if result.Err == nil {
+			results = append(results, result)
+		}
+	}
+	return
+}
+
+// Failed returns the results with an error
+func (report *BulkReport) Failed() (results []AgentResult) {
+	for _, result := range report.Results {
+		if result.Err != nil {
+			results = append(results, result)
+		}
+	}
+	return
+}
+
+// Err returns a *BulkError when the operation failed on any Agent, nil otherwise
+func (report *BulkReport) Err() error {
+	failed := report.Failed()
+	if len(failed) == 0 {
+		return nil
+	}
+	return &BulkError{Total: len(report.Results), Failed: failed}
+}
+
+// BulkError summarises the Agents a bulk operation failed on
+type BulkError struct {
+	Total  int
+	Failed []AgentResult
+}
+
+// Error export
+func (err *BulkError) Error() string {
+	msg := fmt.Sprintf("Bulk operation failed on %d of %d Agents", len(err.Failed), err.Total)
+	for _, result := range err.Failed {
+		name := result.Name
+		if name == "" {
+			name = result.UUID
+		}
+		msg += fmt.Sprintf("\n%s: %s", name, result.Err.Error())
+	}
+	return msg
+}
+
+// Is reports whether the operation failed on any Agent with target
+func (err *BulkError) Is(target error) bool {
+	for _, result := range err.Failed {
+		if errors.Is(result.Err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// BulkAgents runs operation on every selected Agent with bounded concurrency.
+// Failures on some Agents do not stop the others and are reported in the BulkReport, see BulkReport.Err.
+// The returned error is only set when the Agents could not be selected
+func (clt *Client) BulkAgents(ctx context.Context, selector AgentSelector, opts *BulkOptions, operation func(ctx context.Context, agent *AgentInfo) error) (*BulkReport, error) {
+	ctx = withOperation(ctx, "BulkAgents")
+	agents, results, err := clt.selectAgents(ctx, selector)
+	if err != nil {
+		return nil, err
+	}
+
+	var limiter <-chan time.Time
+	if interval := opts.rateInterval(); interval > 0 {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		limiter = ticker.C
+	}
+
+	sem := make(chan struct{}, opts.concurrency())
+	wg := sync.WaitGroup{}
+	started := 0
+	for idx := range agents {
+		if agents[idx] == nil {
+			// Selection failed for this Agent
+			opts.result(results[idx])
+			continue
+		}
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			results[idx].Err = ctx.Err()
+			opts.result(results[idx])
+			continue
+		}
+		// The rate limit applies to the operations actually started
+		if err := waitForTurn(ctx, limiter, started == 0); err != nil {
+			<-sem
+			results[idx].Err = err
+			opts.result(results[idx])
+			continue
+		}
+		started++
+		wg.Add(1)
+		go func(idx int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			start := time.Now()
+			results[idx].Err = operation(ctx, agents[idx])
+			results[idx].Duration = time.Since(start)
+			opts.result(results[idx])
+		}(idx)
+	}
+	wg.Wait()
+
+	return &BulkReport{Results: results}, nil
+}
+
+// waitForTurn waits for the rate limiter, the first operation starts immediately
+func waitForTurn(ctx context.Context, limiter <-chan time.Time, first bool) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if limiter == nil || first {
+		return nil
+	}
+	select {
+	case <-limiter:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// selectAgents resolves the selector. Agents which could not be found are nil with the error in their result
+func (clt *Client) selectAgents(ctx context.Context, selector AgentSelector) (agents []*AgentInfo, results []AgentResult, err error) {
+	seen := make(map[string]bool)
+	add := func(agent *AgentInfo, result AgentResult) {
+		if agent != nil {
+			if seen[agent.UUID] {
+				return
+			}
+			seen[agent.UUID] = true
+			result.UUID = agent.UUID
+			result.Name = agent.Name
+		}
+		agents = append(agents, agent)
+		results = append(results, result)
+	}
+
+	for _, name := range selector.Names {
+		agent, err := clt.GetAgentByNameWithContext(ctx, name, false)
+		if err != nil {
+			add(nil, AgentResult{Name: name, Err: err})
+			continue
+		}
+		add(agent, AgentResult{})
+	}
+	for _, uuid := range selector.UUIDs {
+		agent, err := clt.GetAgentByIDWithContext(ctx, uuid)
+		if err != nil {
+			add(nil, AgentResult{UUID: uuid, Err: err})
+			continue
+		}
+		add(agent, AgentResult{})
+	}
+	if selector.Query != nil {
+		list, err := clt.ListAgentsWithContext(ctx, *selector.Query)
+		if err != nil {
+			return nil, nil, err
+		}
+		for idx := range list.Agents {
+			add(&list.Agents[idx], AgentResult{})
+		}
+	}
+	return agents, results, nil
+}
+
+// BulkUpgradeAgents upgrades every selected Agent, see BulkAgents
+func (clt *Client) BulkUpgradeAgents(ctx context.Context, selector AgentSelector, opts *BulkOptions) (*BulkReport, error) {
+	ctx = withOperation(ctx, "BulkUpgradeAgents")
+	return clt.BulkAgents(ctx, selector, opts, func(ctx context.Context, agent *AgentInfo) error {
+		return clt.changeAgentVersion(ctx, agent.UUID, "upgrade")
+	})
+}
+
+// BulkRollbackAgents rolls back every selected Agent, see BulkAgents
+func (clt *Client) BulkRollbackAgents(ctx context.Context, selector AgentSelector, opts *BulkOptions) (*BulkReport, error) {
+	ctx = withOperation(ctx, "BulkRollbackAgents")
+	return clt.BulkAgents(ctx, selector, opts, func(ctx context.Context, agent *AgentInfo) error {
+		return clt.changeAgentVersion(ctx, agent.UUID, "rollback")
+	})
+}
+
+// BulkRebootAgents reboots every selected Agent, see BulkAgents
+func (clt *Client) BulkRebootAgents(ctx context.Context, selector AgentSelector, opts *BulkOptions) (*BulkReport, error) {
+	ctx = withOperation(ctx, "BulkRebootAgents")
+	return clt.BulkAgents(ctx, selector, opts, func(ctx context.Context, agent *AgentInfo) error {
+		return clt.RebootAgentWithContext(ctx, agent.UUID)
+	})
+}
+
+// BulkPruneAgents prunes every selected Agent, see BulkAgents
+func (clt *Client) BulkPruneAgents(ctx context.Context, selector AgentSelector, opts *BulkOptions) (*BulkReport, error) {
+	ctx = withOperation(ctx, "BulkPruneAgents")
+	return clt.BulkAgents(ctx, selector, opts, func(ctx context.Context, agent *AgentInfo) error {
+		return clt.PruneAgentWithContext(ctx, agent.UUID)
+	})
+}
+
+// BulkUpdateAgents applies patch to every selected Agent, see BulkAgents.
+// The UUID of patch is ignored and Name must be empty since Agent names are unique
+func (clt *Client) BulkUpdateAgents(ctx context.Context, selector AgentSelector, patch AgentUpdateRequest, opts *BulkOptions) (*BulkReport, error) {
+	ctx = withOperation(ctx, "BulkUpdateAgents")
+	if patch.Name != "" {
+		return nil, NewInputError("Cannot set the same name on several Agents")
+	}
+	return clt.BulkAgents(ctx, selector, opts, func(ctx context.Context, agent *AgentInfo) error {
+		request := patch
+		request.UUID = agent.UUID
+		_, err := clt.UpdateAgentWithContext(ctx, &request)
+		return err
+	})
+}
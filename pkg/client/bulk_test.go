@@ -0,0 +1,125 @@
+/*
+ *  *******************************************************************************
+ *  * Copyright (c) 2019 Edgeworx, Inc.
+ *  *
+ *  * This program and the accompanying materials are made available under the
+ *  * terms of the Eclipse Public License v. 2.0 which is available at
+ *  * http://www.eclipse.org/legal/epl-2.0
+ *  *
+ *  * SPDX-License-Identifier: EPL-2.0
+ *  *******************************************************************************
+ *
+ */
+
+package client_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/eclipse-iofog/iofog-go-sdk/v3/pkg/client"
+	"github.com/eclipse-iofog/iofog-go-sdk/v3/pkg/client/fake"
+	"github.com/eclipse-iofog/iofog-go-sdk/v3/pkg/client/fake/faketest"
+)
+
+func TestBulkRebootAgents(t *testing.T) {
+	ctrl, clt := faketest.NewLoggedIn(t, client.Options{})
+	uuids := []string{}
+	for _, name := range []string{"edge-1", "edge-2", "edge-3", "edge-4", "cloud-1"} {
+		agent, err := clt.CreateAgent(&client.CreateAgentRequest{AgentUpdateRequest: client.AgentUpdateRequest{Name: name}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		uuids = append(uuids, agent.UUID)
+	}
+
+	// Track how many reboots are in flight
+	mu := sync.Mutex{}
+	inFlight, maxInFlight := 0, 0
+	ctrl.Intercept(func(w http.ResponseWriter, r *http.Request) bool {
+		if !strings.HasSuffix(r.URL.Path, "/reboot") {
+			return false
+		}
+		mu.Lock()
+		inFlight++
+		if inFlight > maxInFlight {
+			maxInFlight = inFlight
+		}
+		mu.Unlock()
+		time.Sleep(20 * time.Millisecond)
+		mu.Lock()
+		inFlight--
+		mu.Unlock()
+		return false
+	})
+
+	query := client.NewAgentQuery().NameContains("edge").Request()
+	selector := client.AgentSelector{
+		Names: []string{"cloud-1", "missing", "edge-1"},
+		Query: &query,
+	}
+	report, err := clt.BulkRebootAgents(context.Background(), selector, &client.BulkOptions{Concurrency: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(report.Results) != 6 {
+		t.Fatalf("Expected 6 results, got %d", len(report.Results))
+	}
+	if len(report.Succeeded()) != 5 {
+		t.Errorf("Expected 5 reboots, got %d", len(report.Succeeded()))
+	}
+	failed := report.Failed()
+	if len(failed) != 1 || failed[0].Name != "missing" || !errors.Is(failed[0].Err, client.ErrNotFound) {
+		t.Errorf("Expected only the missing Agent to fail, got %v", failed)
+	}
+	if !errors.Is(report.Err(), client.ErrNotFound) {
+		t.Errorf("Expected the report error to match ErrNotFound, got %v", report.Err())
+	}
+	for _, uuid := range uuids {
+		if commands := ctrl.AgentCommands(uuid); len(commands) != 1 || commands[0] != fake.RebootCommand {
+			t.Errorf("Expected one reboot for %s, got %v", uuid, commands)
+		}
+	}
+	if maxInFlight > 2 {
+		t.Errorf("Expected at most 2 concurrent reboots, got %d", maxInFlight)
+	}
+}
+
+func TestBulkRateLimit(t *testing.T) {
+	_, clt := faketest.NewLoggedIn(t, client.Options{})
+	for _, name := range []string{"edge-1", "edge-2"} {
+		if _, err := clt.CreateAgent(&client.CreateAgentRequest{AgentUpdateRequest: client.AgentUpdateRequest{Name: name}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Agents which could not be selected do not use the rate limit
+	mu := sync.Mutex{}
+	started := []time.Duration{}
+	start := time.Now()
+	selector := client.AgentSelector{Names: []string{"missing-1", "missing-2", "edge-1", "edge-2"}}
+	report, err := clt.BulkAgents(context.Background(), selector, &client.BulkOptions{RateLimit: 5}, func(ctx context.Context, agent *client.AgentInfo) error {
+		mu.Lock()
+		defer mu.Unlock()
+		started = append(started, time.Since(start))
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Succeeded()) != 2 || len(started) != 2 {
+		t.Fatalf("Expected 2 operations, got %v", report.Results)
+	}
+	if started[0] > 100*time.Millisecond {
+		t.Errorf("Expected the first operation to start immediately, started after %s", started[0])
+	}
+	if started[1] < 150*time.Millisecond {
+		t.Errorf("Expected the second operation to wait for the rate limit, started after %s", started[1])
+	}
+}
@@ -0,0 +1,186 @@
+/*
+ *  *******************************************************************************
+ *  * Copyright (c) 2019 Edgeworx, Inc.
+ *  *
+ *  * This program and the accompanying materials are made available under the
+ *  * terms of the Eclipse Public License v. 2.0 which is available at
+ *  * http://www.eclipse.org/legal/epl-2.0
+ *  *
+ *  * SPDX-License-Identifier: EPL-2.0
+ *  *******************************************************************************
+ *
+ */
+
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Feature is an optional capability of the Controller
+type Feature string
+
+const (
+	// FeatureEdgeResources is probed through HEAD /capabilities/edgeResources
+	FeatureEdgeResources Feature = "edgeResources"
+	// FeatureApplicationTemplates is probed through HEAD /capabilities/applicationTemplates
+	FeatureApplicationTemplates Feature = "applicationTemplates"
+	// FeatureListAllMicroservices is GET /microservices without flow, available from Controller 2.0.2
+	FeatureListAllMicroservices Feature = "listAllMicroservices"
+	// FeatureListPagination is probed through HEAD /capabilities/listPagination
+	FeatureListPagination Feature = "listPagination"
+)
+
+// Features advertised by the Controller capabilities endpoint
+var probedFeatures = map[Feature]string{
+	FeatureEdgeResources:        "/capabilities/edgeResources",
+	FeatureApplicationTemplates: "/capabilities/applicationTemplates",
+	FeatureListPagination:       "/capabilities/listPagination",
+}
+
+// Features deduced from the Controller version
+var versionedFeatures = map[Feature]Version{
+	FeatureListAllMicroservices: {Major: 2, Minor: 0, Patch: 2},
+}
+
+// Version is a semantic version of the Controller
+type Version struct {
+	Major      int
+	Minor      int
+	Patch      int
+	PreRelease string
+}
+
+// ParseVersion parses a semantic version such as 3.0.1, v3.0.1 or 3.0.0-beta1+build
+func ParseVersion(version string) (Version, error) {
+	parsed := Version{}
+	core := strings.TrimPrefix(strings.TrimSpace(version), "v")
+	core = before(core, "+")
+	if idx := strings.Index(core, "-"); idx >= 0 {
+		parsed.PreRelease = core[idx+1:]
+		core = core[:idx]
+	}
+	nums := strings.Split(core, ".")
+	if len(nums) != 3 {
+		return parsed, NewInputError(fmt.Sprintf("Invalid semantic version: %s", version))
+	}
+	for idx, target := range []*int{&parsed.Major, &parsed.Minor, &parsed.Patch} {
+		num, err := strconv.Atoi(nums[idx])
+		if err != nil || num < 0 {
+			return parsed, NewInputError(fmt.Sprintf("Invalid semantic version: %s", version))
+		}
+		*target = num
+	}
+	return parsed, nil
+}
+
+// Compare returns -1, 0 or 1 when v is lower, equal or greater than other. Pre-releases are lower than their release
+func (v Version) Compare(other Version) int {
+	for _, diff := range []int{v.Major - other.Major, v.Minor - other.Minor, v.Patch - other.Patch} {
+		if diff < 0 {
+			return -1
+		}
+		if diff > 0 {
+			return 1
+		}
+	}
+	switch {
+	case v.PreRelease == other.PreRelease:
+		return 0
+	case v.PreRelease == "":
+		return 1
+	case other.PreRelease == "":
+		return -1
+	case v.PreRelease < other.PreRelease:
+		return -1
+	default:
+		return 1
+	}
+}
+
+// AtLeast reports whether v is greater than or equal to major.minor.patch, pre-releases included
+func (v Version) AtLeast(major, minor, patch int) bool {
+	return Version{Major: v.Major, Minor: v.Minor, Patch: v.Patch}.Compare(Version{Major: major, Minor: minor, Patch: patch}) >= 0
+}
+
+// IsDev reports whether v is a development build, which supports every feature
+func (v Version) IsDev() bool {
+	return strings.Contains(v.PreRelease, "dev")
+}
+
+func (v Version) String() string {
+	version := fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+	if v.PreRelease != "" {
+		version += "-" + v.PreRelease
+	}
+	return version
+}
+
+// Supports reports whether the Controller supports feature. Results are cached for the lifetime of the client
+func (clt *Client) Supports(feature Feature) (bool, error) {
+	return clt.SupportsWithContext(context.Background(), feature)
+}
+
+// SupportsWithContext is Supports with a context that can cancel the request or bound its deadline
+func (clt *Client) SupportsWithContext(ctx context.Context, feature Feature) (bool, error) {
+	ctx = withOperation(ctx, "Supports")
+	clt.capabilitiesMu.Lock()
+	supported, cached := clt.capabilities[feature]
+	clt.capabilitiesMu.Unlock()
+	if cached {
+		return supported, nil
+	}
+
+	if path, ok := probedFeatures[feature]; ok {
+		_, err := clt.doRequest(ctx, "HEAD", path, nil)
+		switch {
+		case err == nil:
+			supported = true
+		case errors.Is(err, ErrNotFound):
+			supported = false
+		default:
+			// Transient failures are not cached
+			return false, err
+		}
+	} else if minimum, ok := versionedFeatures[feature]; ok {
+		version, err := clt.ControllerVersionWithContext(ctx)
+		if err != nil {
+			return false, err
+		}
+		supported = version.IsDev() || version.AtLeast(minimum.Major, minimum.Minor, minimum.Patch)
+	} else {
+		return false, NewInputError(fmt.Sprintf("Unknown Controller feature %s", feature))
+	}
+
+	clt.capabilitiesMu.Lock()
+	clt.capabilities[feature] = supported
+	clt.capabilitiesMu.Unlock()
+	return supported, nil
+}
+
+// ResetCapabilities clears the cached capabilities and Controller version, e.g. after the Controller was upgraded
+func (clt *Client) ResetCapabilities() {
+	clt.capabilitiesMu.Lock()
+	clt.capabilities = make(map[Feature]bool)
+	clt.capabilitiesMu.Unlock()
+
+	clt.mu.Lock()
+	clt.status = controllerStatus{}
+	clt.mu.Unlock()
+}
+
+// requireFeature returns a NotSupportedError named after description when the Controller lacks feature
+func (clt *Client) requireFeature(ctx context.Context, feature Feature, description string) error {
+	supported, err := clt.SupportsWithContext(ctx, feature)
+	if err != nil {
+		return err
+	}
+	if !supported {
+		return NewNotSupportedError(description)
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+/*
+ *  *******************************************************************************
+ *  * Copyright (c) 2019 Edgeworx, Inc.
+ *  *
+ *  * This program and the accompanying materials are made available under the
+ *  * terms of the Eclipse Public License v. 2.0 which is available at
+ *  * http://www.eclipse.org/legal/epl-2.0
+ *  *
+ *  * SPDX-License-Identifier: EPL-2.0
+ *  *******************************************************************************
+ *
+ */
+
+package client_test
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/eclipse-iofog/iofog-go-sdk/v3/pkg/client"
+	"github.com/eclipse-iofog/iofog-go-sdk/v3/pkg/client/fake"
+)
+
+func TestParseVersion(t *testing.T) {
+	cases := map[string]client.Version{
+		"3.0.1":            {Major: 3, Minor: 0, Patch: 1},
+		"v2.10.0":          {Major: 2, Minor: 10, Patch: 0},
+		"3.0.0-beta1":      {Major: 3, Minor: 0, Patch: 0, PreRelease: "beta1"},
+		"3.1.0-dev+abcdef": {Major: 3, Minor: 1, Patch: 0, PreRelease: "dev"},
+	}
+	for input, expected := range cases {
+		version, err := client.ParseVersion(input)
+		if err != nil || version != expected {
+			t.Errorf("ParseVersion(%s) = %+v, %v", input, version, err)
+		}
+	}
+	if _, err := client.ParseVersion("3.0"); !errors.Is(err, client.ErrInput) {
+		t.Errorf("Expected input error, got %v", err)
+	}
+
+	// The old check major>=2 && minor>=0 && patch>=2 rejected 3.0.0
+	if !(client.Version{Major: 3}).AtLeast(2, 0, 2) || (client.Version{Major: 2, Patch: 1}).AtLeast(2, 0, 2) {
+		t.Error("Unexpected version comparison")
+	}
+	if (client.Version{Major: 3, PreRelease: "beta"}).Compare(client.Version{Major: 3}) != -1 {
+		t.Error("Expected pre-release to be lower than release")
+	}
+}
+
+func TestSupportsIsCached(t *testing.T) {
+	ctrl := fake.New()
+	defer ctrl.Close()
+	ctrl.SetCapability(fake.ApplicationTemplatesCapability, false)
+	clt := client.New(client.Options{BaseURL: ctrl.URL()})
+
+	for i := 0; i < 3; i++ {
+		if supported, err := clt.Supports(client.FeatureEdgeResources); err != nil || !supported {
+			t.Fatalf("Expected edge resources to be supported, got %v, %v", supported, err)
+		}
+		if err := clt.IsApplicationTemplateCapable(); !errors.Is(err, client.ErrNotSupported) {
+			t.Fatalf("Expected application templates not to be supported, got %v", err)
+		}
+	}
+	if count := ctrl.RequestCount("HEAD", "/capabilities/edgeResources"); count != 1 {
+		t.Errorf("Expected 1 edge resources probe, got %d", count)
+	}
+	if count := ctrl.RequestCount("HEAD", "/capabilities/applicationTemplates"); count != 1 {
+		t.Errorf("Expected 1 application templates probe, got %d", count)
+	}
+	if supported, err := clt.Supports(client.FeatureListAllMicroservices); err != nil || !supported {
+		t.Errorf("Expected listing all microservices to be supported, got %v, %v", supported, err)
+	}
+}
+
+func TestLazyStatusProbe(t *testing.T) {
+	ctrl := fake.New()
+	defer ctrl.Close()
+
+	clt := client.New(client.Options{BaseURL: ctrl.URL(), SkipStatusProbe: true})
+	if count := ctrl.RequestCount("GET", "/status"); count != 0 {
+		t.Errorf("Expected New not to query the status, got %d requests", count)
+	}
+
+	// Controller is down on first use
+	ctrl.Intercept(func(w http.ResponseWriter, r *http.Request) bool {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return true
+	})
+	if _, err := clt.ControllerVersion(); err == nil || clt.StatusError() == nil {
+		t.Errorf("Expected status error, got %v and %v", err, clt.StatusError())
+	}
+
+	// Controller comes up
+	ctrl.Intercept(nil)
+	if version := clt.GetVersion(); version != "3.0.0" {
+		t.Errorf("Expected version 3.0.0, got %s", version)
+	}
+	if major, _, _, err := clt.GetVersionNumbers(); err != nil || major != 3 || clt.StatusError() != nil {
+		t.Errorf("Unexpected version %d: %v, %v", major, err, clt.StatusError())
+	}
+	if count := ctrl.RequestCount("GET", "/status"); count != 2 {
+		t.Errorf("Expected 2 status requests, got %d", count)
+	}
+}
@@ -14,13 +14,22 @@
 package client
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"strconv"
 )
 
 // GetCatalog retrieves all catalog items using Controller REST API
 func (clt *Client) GetCatalog() (response *CatalogListResponse, err error) {
-	body, err := clt.doRequest("GET", "/catalog/microservices", nil)
+	return clt.GetCatalogWithContext(context.Background())
+}
+
+// GetCatalogWithContext is GetCatalog with a context that can cancel the request or bound its deadline
+func (clt *Client) GetCatalogWithContext(ctx context.Context) (response *CatalogListResponse, err error) {
+	ctx = withOperation(ctx, "GetCatalog")
+	body, err := clt.doRequest(ctx, "GET", "/catalog/microservices", nil)
 	if err != nil {
 		return
 	}
@@ -32,7 +41,13 @@ func (clt *Client) GetCatalog() (response *CatalogListResponse, err error) {
 
 // GetCatalogItem retrieves one catalog item using Controller REST API
 func (clt *Client) GetCatalogItem(id int) (response *CatalogItemInfo, err error) {
-	body, err := clt.doRequest("GET", fmt.Sprintf("/catalog/microservices/%d", id), nil)
+	return clt.GetCatalogItemWithContext(context.Background(), id)
+}
+
+// GetCatalogItemWithContext is GetCatalogItem with a context that can cancel the request or bound its deadline
+func (clt *Client) GetCatalogItemWithContext(ctx context.Context, id int) (response *CatalogItemInfo, err error) {
+	ctx = withOperation(ctx, "GetCatalogItem")
+	body, err := clt.doRequest(ctx, "GET", fmt.Sprintf("/catalog/microservices/%d", id), nil)
 	if err != nil {
 		return
 	}
@@ -44,51 +59,103 @@ func (clt *Client) GetCatalogItem(id int) (response *CatalogItemInfo, err error)
 
 // CreateCatalogItem creates one catalog item using Controller REST API
 func (clt *Client) CreateCatalogItem(request *CatalogItemCreateRequest) (*CatalogItemInfo, error) {
+	return clt.CreateCatalogItemWithContext(context.Background(), request)
+}
+
+// CreateCatalogItemWithContext is CreateCatalogItem with a context that can cancel the request or bound its deadline
+func (clt *Client) CreateCatalogItemWithContext(ctx context.Context, request *CatalogItemCreateRequest) (*CatalogItemInfo, error) {
+	ctx = withOperation(ctx, "CreateCatalogItem")
 	// Set registry to public docker by default
 	if request.RegistryID == 0 {
 		request.RegistryID = 1
 	}
 
-	body, err := clt.doRequest("POST", "/catalog/microservices", request)
+	body, err := clt.doRequest(ctx, "POST", "/catalog/microservices", request)
 	if err != nil {
 		return nil, err
 	}
+	clt.names.invalidate(catalogNames)
 	response := &CatalogItemCreateResponse{}
 	if err := json.Unmarshal(body, response); err != nil {
 		return nil, err
 	}
-	return clt.GetCatalogItem(response.ID)
+	return clt.GetCatalogItemWithContext(ctx, response.ID)
 }
 
 // UpdateCatalogItem updates one catalog item using Controller REST API
 func (clt *Client) UpdateCatalogItem(request *CatalogItemUpdateRequest) (*CatalogItemInfo, error) {
-	_, err := clt.doRequest("PATCH", fmt.Sprintf("/catalog/microservices/%d", request.ID), request)
+	return clt.UpdateCatalogItemWithContext(context.Background(), request)
+}
+
+// UpdateCatalogItemWithContext is UpdateCatalogItem with a context that can cancel the request or bound its deadline
+func (clt *Client) UpdateCatalogItemWithContext(ctx context.Context, request *CatalogItemUpdateRequest) (*CatalogItemInfo, error) {
+	ctx = withOperation(ctx, "UpdateCatalogItem")
+	_, err := clt.doRequest(ctx, "PATCH", fmt.Sprintf("/catalog/microservices/%d", request.ID), request)
 	if err != nil {
 		return nil, err
 	}
-	return clt.GetCatalogItem(request.ID)
+	clt.names.invalidate(catalogNames)
+	return clt.GetCatalogItemWithContext(ctx, request.ID)
 }
 
 // DeleteCatalogItem deletes one catalog item using Controller REST API
 func (clt *Client) DeleteCatalogItem(id int) (err error) {
-	_, err = clt.doRequest("DELETE", fmt.Sprintf("/catalog/microservices/%d", id), nil)
+	return clt.DeleteCatalogItemWithContext(context.Background(), id)
+}
+
+// DeleteCatalogItemWithContext is DeleteCatalogItem with a context that can cancel the request or bound its deadline
+func (clt *Client) DeleteCatalogItemWithContext(ctx context.Context, id int) (err error) {
+	ctx = withOperation(ctx, "DeleteCatalogItem")
+	if _, err = clt.doRequest(ctx, "DELETE", fmt.Sprintf("/catalog/microservices/%d", id), nil); err != nil {
+		return
+	}
+	clt.names.invalidate(catalogNames)
 	return
 }
 
-// GetCatalogItemByName returns a catalog item by listing all catalog items and returning the first occurence of the specified name
+// GetCatalogItemByName returns a catalog item by listing all catalog items and finding the one with the specified name.
+// An AmbiguousError is returned when several catalog items share the name
 func (clt *Client) GetCatalogItemByName(name string) (*CatalogItemInfo, error) {
-	// Get all catalog items
-	catalog, err := clt.GetCatalog()
+	return clt.GetCatalogItemByNameWithContext(context.Background(), name)
+}
+
+// GetCatalogItemByNameWithContext is GetCatalogItemByName with a context that can cancel the request or bound its deadline
+func (clt *Client) GetCatalogItemByNameWithContext(ctx context.Context, name string) (*CatalogItemInfo, error) {
+	ctx = withOperation(ctx, "GetCatalogItemByName")
+	if id, cached := clt.names.get(catalogNames, name); cached {
+		if itemID, err := strconv.Atoi(id); err == nil {
+			item, err := clt.GetCatalogItemWithContext(ctx, itemID)
+			if err == nil && item.Name == name {
+				return item, nil
+			}
+			if err != nil && !errors.Is(err, ErrNotFound) {
+				return nil, err
+			}
+		}
+		clt.names.remove(catalogNames, name)
+	}
+
+	// The Controller cannot filter catalog items, get all of them
+	catalog, err := clt.GetCatalogWithContext(ctx)
 	if err != nil {
 		return nil, err
 	}
 
 	// Find catalog item
-	for _, item := range catalog.CatalogItems {
-		if item.Name == name {
-			return &item, nil
+	var found *CatalogItemInfo
+	ids := []string{}
+	for idx := range catalog.CatalogItems {
+		if catalog.CatalogItems[idx].Name == name {
+			found = &catalog.CatalogItems[idx]
+			ids = append(ids, strconv.Itoa(found.ID))
 		}
 	}
-
-	return nil, NewNotFoundError(fmt.Sprintf("Could not find catalog item %s\n", name))
+	switch len(ids) {
+	case 0:
+		return nil, NewNotFoundError(fmt.Sprintf("Could not find catalog item %s\n", name))
+	case 1:
+		clt.names.set(catalogNames, name, ids[0])
+		return found, nil
+	}
+	return nil, NewAmbiguousError(string(catalogNames), name, ids)
 }
@@ -14,68 +14,207 @@
 package client
 
 import (
+	"context"
+	"crypto/tls"
 	"fmt"
+	"io"
+	"net/http"
 	"net/url"
 	"path"
 	"strings"
+	"sync"
 	"time"
 )
 
 type controllerStatus struct {
-	version         string
-	versionNoSuffix string
-	versionNums     []string
+	fetched bool
+	version string
+	semver  Version
+	// err is set when the version is not a valid semantic version
+	err error
 }
 
+// Client calls the Controller REST API.
+// A Client is safe for concurrent use by multiple goroutines, including token rotation with SetAccessToken,
+// SetRetries and automatic relogin. The package level SetVerbosity and SetGlobalRetries are not and should be called before creating clients
 type Client struct {
 	baseURL     *url.URL
+	mu          sync.RWMutex
 	accessToken string
+	credentials CredentialProvider
+	tokenSource TokenSource
+	lastLogin   *LoginRequest
+	loggedOut   bool
+	loginMu     sync.Mutex
 	retries     Retries
 	status      controllerStatus
-	timeout     int
+	statusErr   error
+	statusMu    sync.Mutex
+	timeout     time.Duration
+	httpClient  *http.Client
+	logger      Logger
+	hooks       []Hooks
+
+	// Cached results of Supports
+	capabilities   map[Feature]bool
+	capabilitiesMu sync.Mutex
+
+	// Cached IDs of name lookups, nil when disabled
+	names *nameCache
+
+	// Request timeouts per operation name
+	operationTimeouts map[string]time.Duration
+
+	// Items per page of paginated lists, pagination is disabled when not positive
+	pageSize int
 }
 
 type Options struct {
 	BaseURL *url.URL
 	Retries *Retries
+	// Timeout is the request timeout in seconds, 5 by default. Deprecated: use RequestTimeout
 	Timeout int
+	// RequestTimeout bounds each request attempt and overrides Timeout
+	RequestTimeout time.Duration
+	// OperationTimeouts overrides the request timeout per operation, e.g. "CreateApplicationFromYAML" or "GetStatus".
+	// The innermost operation wins: GetStatus sent by Supports uses the GetStatus timeout, else the Supports one.
+	// See also WithRequestTimeout for a single call
+	OperationTimeouts map[string]time.Duration
+	// HTTPClient is used for every request when set. Transport, TLSConfig, Proxy and DisableKeepAlives are then ignored
+	HTTPClient *http.Client
+	// Transport is used to build the HTTP client when HTTPClient is not set. TLSConfig, Proxy and DisableKeepAlives are then ignored
+	Transport http.RoundTripper
+	// TLSConfig configures custom CA bundles and client certificates, see TLSConfigFromFiles
+	TLSConfig *tls.Config
+	// Proxy selects a proxy for each request, defaults to http.ProxyFromEnvironment
+	Proxy func(*http.Request) (*url.URL, error)
+	// DisableKeepAlives closes the connection after every request instead of reusing it
+	DisableKeepAlives bool
+	// Credentials are used to log in again when the access token is rejected.
+	// Defaults to the email and password of the last successful Login
+	Credentials CredentialProvider
+	// TokenSource supplies an access token sent with every request instead of logging in, see EnvTokenSource and FileTokenSource
+	TokenSource TokenSource
+	// SkipStatusProbe prevents New from querying the Controller status.
+	// The version is then queried on first use, e.g. by GetVersion or Supports
+	SkipStatusProbe bool
+	// Logger receives request, response and retry records with credentials redacted.
+	// Defaults to printing to stdout when SetVerbosity(true) was called
+	Logger Logger
+	// Hooks observe every request attempt, see the metrics and tracing packages for ready-made hooks
+	Hooks []Hooks
+	// NameCacheTTL enables caching the IDs of GetAgentByName, GetCatalogItemByName and GetFlowByName for that long.
+	// Entries are dropped when the client changes a resource of the same kind, see also InvalidateNameCache
+	NameCacheTTL time.Duration
+	// PageSize enables pagination: list calls and iterators request pages of that many items
+	// when the Controller supports pagination. Lists are requested in one response when unset
+	PageSize int
 }
 
 func New(opt Options) *Client {
-	if opt.Timeout == 0 {
-		opt.Timeout = 5
-	}
 	retries := GlobalRetriesPolicy
 	if opt.Retries != nil {
 		retries = *opt.Retries
 	}
+	// Copy the URL since it is completed below
+	baseURL := *opt.BaseURL
 	client := &Client{
-		retries: retries,
-		baseURL: opt.BaseURL,
-		timeout: opt.Timeout,
+		retries:     retries.clone(),
+		baseURL:     &baseURL,
+		timeout:     newRequestTimeout(opt),
+		credentials: opt.Credentials,
+		tokenSource: opt.TokenSource,
+		logger:      opt.Logger,
+		hooks:       opt.Hooks,
+		pageSize:    opt.PageSize,
+	}
+	if client.logger == nil {
+		client.logger = verboseLogger{}
 	}
+	client.httpClient = newHTTPClient(opt)
 	if client.baseURL.Scheme == "" {
 		client.baseURL.Path = "http"
 	}
 	if client.baseURL.Path == "" {
 		client.baseURL.Path = "api/v3"
 	}
-	// Get Controller version
-	if status, err := client.GetStatus(); err == nil {
-		versionNoSuffix := before(status.Versions.Controller, "-")
-		versionNums := strings.Split(versionNoSuffix, ".")
-		client.status = controllerStatus{
-			version:         status.Versions.Controller,
-			versionNoSuffix: versionNoSuffix,
-			versionNums:     versionNums,
-		}
+	client.capabilities = make(map[Feature]bool)
+	client.operationTimeouts = make(map[string]time.Duration, len(opt.OperationTimeouts))
+	for operation, timeout := range opt.OperationTimeouts {
+		client.operationTimeouts[operation] = timeout
+	}
+	if opt.NameCacheTTL > 0 {
+		client.names = newNameCache(opt.NameCacheTTL)
+	}
+	// Get Controller version, failures are retried on first use and available through StatusError
+	if !opt.SkipStatusProbe {
+		_, _ = client.loadStatus(context.Background())
 	}
 	return client
 }
 
+func newControllerStatus(version string) controllerStatus {
+	semver, err := ParseVersion(version)
+	if err != nil {
+		err = fmt.Errorf("Controller did not return a valid API version: %s", version)
+	}
+	return controllerStatus{
+		fetched: true,
+		version: version,
+		semver:  semver,
+		err:     err,
+	}
+}
+
+// newHTTPClient builds the HTTP client, leaving its Timeout unset since each attempt is bounded by requestTimeout
+func newHTTPClient(opt Options) *http.Client {
+	if opt.HTTPClient != nil {
+		// Copy so that the caller's client is never mutated
+		httpClient := *opt.HTTPClient
+		return &httpClient
+	}
+
+	transport := opt.Transport
+	if transport == nil {
+		defaultTransport := http.DefaultTransport.(*http.Transport).Clone()
+		if opt.TLSConfig != nil {
+			defaultTransport.TLSClientConfig = opt.TLSConfig
+		}
+		if opt.Proxy != nil {
+			defaultTransport.Proxy = opt.Proxy
+		}
+		defaultTransport.DisableKeepAlives = opt.DisableKeepAlives
+		transport = defaultTransport
+	}
+	return &http.Client{
+		Transport: transport,
+	}
+}
+
 func NewAndLogin(opt Options, email, password string) (clt *Client, err error) {
+	return NewAndLoginWithContext(context.Background(), opt, email, password)
+}
+
+// NewAndLoginWithContext is NewAndLogin with a context that can cancel the login request or bound its deadline
+func NewAndLoginWithContext(ctx context.Context, opt Options, email, password string) (clt *Client, err error) {
 	clt = New(opt)
-	if err = clt.Login(LoginRequest{Email: email, Password: password}); err != nil {
+	if err = clt.LoginWithContext(ctx, LoginRequest{Email: email, Password: password}); err != nil {
+		return
+	}
+	return
+}
+
+// NewWithCredentials instantiates a client and logs in with the credentials supplied by opt.Credentials
+func NewWithCredentials(ctx context.Context, opt Options) (clt *Client, err error) {
+	if opt.Credentials == nil {
+		return nil, NewInputError("Options.Credentials must be set to log in with a credential provider")
+	}
+	clt = New(opt)
+	creds, err := opt.Credentials.Credentials(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err = clt.LoginWithContext(ctx, creds); err != nil {
 		return
 	}
 	return
@@ -92,46 +231,72 @@ func (clt *Client) GetBaseURL() string {
 }
 
 func (clt *Client) GetRetries() Retries {
-	return clt.retries
+	return clt.getRetries().clone()
 }
 
 func (clt *Client) SetRetries(retries Retries) {
+	retries = retries.clone()
+	clt.mu.Lock()
+	defer clt.mu.Unlock()
 	clt.retries = retries
 }
 
+// getRetries returns the retries configuration, which is replaced but never modified by SetRetries
+func (clt *Client) getRetries() Retries {
+	clt.mu.RLock()
+	defer clt.mu.RUnlock()
+	return clt.retries
+}
+
 func (clt *Client) GetAccessToken() string {
+	clt.mu.RLock()
+	defer clt.mu.RUnlock()
 	return clt.accessToken
 }
 
 func (clt *Client) SetAccessToken(token string) {
+	clt.mu.Lock()
+	defer clt.mu.Unlock()
 	clt.accessToken = token
 }
 
-func (clt *Client) doRequestWithRetries(currentRetries Retries, method, requestURL string, headers map[string]string, request interface{}) ([]byte, error) {
+func (clt *Client) doRequestWithRetries(ctx context.Context, retries, currentRetries Retries, method, requestURL string, headers map[string]string, request interface{}) ([]byte, error) {
+	if retries.Policy != nil {
+		return clt.doRequestWithPolicy(ctx, *retries.Policy, method, requestURL, headers, request)
+	}
+
 	// Send request
-	httpDo := httpDo{timeout: clt.timeout}
-	bytes, err := httpDo.do(method, requestURL, headers, request)
+	attempt := currentRetries.attempt()
+	bytes, attemptCtx, err := clt.doAttempt(ctx, attempt, method, requestURL, headers, request)
 	if err != nil {
 		httpErr, ok := err.(*HTTPError)
 		// If HTTP Error
 		if ok {
 			if httpErr.Code == 408 { // HTTP Timeout
-				if currentRetries.Timeout < clt.retries.Timeout {
+				if currentRetries.Timeout < retries.Timeout {
 					currentRetries.Timeout++
-					time.Sleep(time.Duration(currentRetries.Timeout) * time.Second)
-					return clt.doRequestWithRetries(currentRetries, method, requestURL, headers, request)
+					delay := time.Duration(currentRetries.Timeout) * time.Second
+					clt.notifyRetry(attemptCtx, attempt, method, requestURL, delay, err)
+					if err := sleepWithContext(ctx, delay); err != nil {
+						return nil, err
+					}
+					return clt.doRequestWithRetries(ctx, retries, currentRetries, method, requestURL, headers, request)
 				}
 				return bytes, err
 			}
 		}
 		// If custom retries defined
-		if clt.retries.CustomMessage != nil {
-			for message, allowedRetries := range clt.retries.CustomMessage {
+		if retries.CustomMessage != nil {
+			for message, allowedRetries := range retries.CustomMessage {
 				if strings.Contains(err.Error(), message) {
 					if currentRetries.CustomMessage[message] < allowedRetries {
 						currentRetries.CustomMessage[message]++
-						time.Sleep(time.Duration(currentRetries.CustomMessage[message]) * time.Second)
-						return clt.doRequestWithRetries(currentRetries, method, requestURL, headers, request)
+						delay := time.Duration(currentRetries.CustomMessage[message]) * time.Second
+						clt.notifyRetry(attemptCtx, attempt, method, requestURL, delay, err)
+						if err := sleepWithContext(ctx, delay); err != nil {
+							return nil, err
+						}
+						return clt.doRequestWithRetries(ctx, retries, currentRetries, method, requestURL, headers, request)
 					}
 					return bytes, err
 				}
@@ -141,7 +306,7 @@ func (clt *Client) doRequestWithRetries(currentRetries Retries, method, requestU
 	return bytes, err
 }
 
-func (clt *Client) doRequestWithHeaders(method, requestPath string, request interface{}, headers map[string]string) ([]byte, error) {
+func (clt *Client) doRequestWithHeaders(ctx context.Context, method, requestPath string, request interface{}, headers map[string]string) ([]byte, error) {
 	// Copy the base URL
 	requestURL, err := url.Parse(clt.baseURL.String())
 	if err != nil {
@@ -159,26 +324,72 @@ func (clt *Client) doRequestWithHeaders(method, requestPath string, request inte
 		return nil, fmt.Errorf("failed to parse request URL %s", requestPath)
 	}
 
+	// Buffer streamed bodies so the request can be replayed
+	if reader, ok := request.(io.Reader); ok {
+		bodyBytes, err := io.ReadAll(reader)
+		if err != nil {
+			return nil, err
+		}
+		request = replayableBody(bodyBytes)
+	}
+
 	// Set auth header
-	headers["Authorization"] = clt.accessToken
+	token, err := clt.refreshToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+	headers["Authorization"] = token
+
+	retries := clt.getRetries()
+	bytes, err := clt.doRequestWithRetries(ctx, retries, newRetriesCounter(retries), method, requestURL.String(), headers, request)
+	if !isUnauthorized(err) || !canRelogin(ctx) {
+		return bytes, err
+	}
+
+	// Access token expired, log in again and replay the request once
+	clt.logger.Info("Access token rejected, logging in again", "method", method, "url", requestURL.String())
+	relogged, loginErr := clt.relogin(withoutStream(withoutRelogin(ctx)), token)
+	if !relogged {
+		return bytes, err
+	}
+	if loginErr != nil {
+		clt.logger.Warn("Failed to log in again", "error", loginErr)
+		return nil, loginErr
+	}
+	headers["Authorization"] = clt.GetAccessToken()
+	return clt.doRequestWithRetries(ctx, retries, newRetriesCounter(retries), method, requestURL.String(), headers, request)
+}
 
+// newRetriesCounter returns the per request counter of retries, so that concurrent requests do not share counts
+func newRetriesCounter(retries Retries) Retries {
 	currentRetries := Retries{CustomMessage: make(map[string]int)}
-	if clt.retries.CustomMessage != nil {
-		for message := range clt.retries.CustomMessage {
+	if retries.CustomMessage != nil {
+		for message := range retries.CustomMessage {
 			currentRetries.CustomMessage[message] = 0
 		}
 	}
-
-	return clt.doRequestWithRetries(currentRetries, method, requestURL.String(), headers, request)
+	return currentRetries
 }
 
-func (clt *Client) doRequest(method, requestPath string, request interface{}) ([]byte, error) {
+func (clt *Client) doRequest(ctx context.Context, method, requestPath string, request interface{}) ([]byte, error) {
 	headers := map[string]string{
 		"Content-Type": "application/json",
 	}
-	return clt.doRequestWithHeaders(method, requestPath, request, headers)
+	return clt.doRequestWithHeaders(ctx, method, requestPath, request, headers)
+}
+
+// sleepWithContext waits for the given duration unless ctx is done first
+func sleepWithContext(ctx context.Context, duration time.Duration) error {
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
 }
 
 func (clt *Client) isLoggedIn() bool {
-	return clt.accessToken != ""
+	return clt.tokenSource != nil || clt.GetAccessToken() != ""
 }
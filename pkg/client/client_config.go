@@ -34,7 +34,40 @@ func SetGlobalRetries(retries Retries) {
 	GlobalRetriesPolicy = retries
 }
 
+// Retries configures how failed requests are retried.
+// When Policy is set, Timeout and CustomMessage are ignored in favour of exponential backoff.
 type Retries struct {
 	Timeout       int
 	CustomMessage map[string]int
+	Policy        *RetryPolicy
+}
+
+// clone copies the map and policy so that the copy can be used while the caller modifies the original
+func (retries Retries) clone() Retries {
+	if retries.CustomMessage != nil {
+		customMessage := make(map[string]int, len(retries.CustomMessage))
+		for message, count := range retries.CustomMessage {
+			customMessage[message] = count
+		}
+		retries.CustomMessage = customMessage
+	}
+	if retries.Policy != nil {
+		policy := *retries.Policy
+		// An empty list disables the retries on status codes, unlike nil which selects the defaults
+		if policy.RetryableStatusCodes != nil {
+			policy.RetryableStatusCodes = make([]int, len(retries.Policy.RetryableStatusCodes))
+			copy(policy.RetryableStatusCodes, retries.Policy.RetryableStatusCodes)
+		}
+		retries.Policy = &policy
+	}
+	return retries
+}
+
+// attempt returns the number of the attempt counted by retries used as a counter
+func (retries Retries) attempt() int {
+	attempt := 1 + retries.Timeout
+	for _, count := range retries.CustomMessage {
+		attempt += count
+	}
+	return attempt
 }
@@ -14,8 +14,16 @@
 package client
 
 import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
 	"net/url"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 )
 
 func TestCreation(t *testing.T) {
@@ -50,4 +58,167 @@ func TestGenerateListAgentsURL(t *testing.T) {
 	if url != "/iofog-list?system=false" {
 		t.Errorf("Failed to generate List Agents URL: %s", url)
 	}
+	url = generateListAgentURL(NewAgentQuery().Name("edge 1&2").Request())
+	if url != "/iofog-list?system=false&filters[0][key]=name&filters[0][value]=edge+1%262&filters[0][condition]=equals" {
+		t.Errorf("Failed to escape List Agents URL: %s", url)
+	}
+}
+
+func TestRetriesHonourContext(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusRequestTimeout)
+	}))
+	defer server.Close()
+
+	baseURL, err := url.Parse(server.URL + "/api/v3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	clt := New(Options{BaseURL: baseURL})
+	clt.SetRetries(Retries{Timeout: 5})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err = clt.GetStatusWithContext(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected deadline exceeded error, got: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Retry backoff ignored context deadline, took %s", elapsed)
+	}
+}
+
+func TestCustomHTTPClientReusesConnections(t *testing.T) {
+	var connections int32
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"status":"online","versions":{"controller":"3.0.0"}}`))
+	}))
+	server.Config.ConnState = func(conn net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			atomic.AddInt32(&connections, 1)
+		}
+	}
+	server.StartTLS()
+	defer server.Close()
+
+	baseURL, err := url.Parse(server.URL + "/api/v3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	clt := New(Options{BaseURL: baseURL, HTTPClient: server.Client()})
+	if clt.GetVersion() != "3.0.0" {
+		t.Errorf("Failed to get Controller version over TLS: %s", clt.GetVersion())
+	}
+	for i := 0; i < 3; i++ {
+		if _, err := clt.GetStatus(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if count := atomic.LoadInt32(&connections); count != 1 {
+		t.Errorf("Expected a single reused connection, got %d", count)
+	}
+}
+
+func TestReloginOnUnauthorized(t *testing.T) {
+	var logins int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v3/user/login":
+			atomic.AddInt32(&logins, 1)
+			_, _ = w.Write([]byte(`{"accessToken":"fresh"}`))
+		case "/api/v3/iofog-list":
+			if r.Header.Get("Authorization") != "fresh" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			_, _ = w.Write([]byte(`{"fogs":[{"uuid":"abc","name":"agent-1"}]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	baseURL, err := url.Parse(server.URL + "/api/v3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	clt, err := NewWithToken(Options{
+		BaseURL:     baseURL,
+		Credentials: StaticCredentials{Email: "user@domain.com", Password: "secret"},
+	}, "expired")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			list, err := clt.ListAgents(ListAgentsRequest{})
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if len(list.Agents) != 1 {
+				t.Errorf("Expected 1 agent, got %d", len(list.Agents))
+			}
+		}()
+	}
+	wg.Wait()
+
+	if count := atomic.LoadInt32(&logins); count != 1 {
+		t.Errorf("Expected exactly one login, got %d", count)
+	}
+	if clt.GetAccessToken() != "fresh" {
+		t.Errorf("Access token was not refreshed: %s", clt.GetAccessToken())
+	}
+}
+
+func TestStructuredErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Request-Id", "req-1")
+		switch r.URL.Path {
+		case "/api/v3/registries":
+			w.WriteHeader(http.StatusConflict)
+			_, _ = w.Write([]byte(`{"name":"DuplicatePropertyError","message":"Duplicate registry url"}`))
+		case "/api/v3/router":
+			w.WriteHeader(http.StatusForbidden)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	baseURL, err := url.Parse(server.URL + "/api/v3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	clt := New(Options{BaseURL: baseURL})
+
+	_, err = clt.CreateRegistry(&RegistryCreateRequest{URL: "registry.hub.docker.com"})
+	if !errors.Is(err, ErrConflict) {
+		t.Fatalf("Expected conflict error, got: %v", err)
+	}
+	var httpErr *HTTPError
+	if !errors.As(err, &httpErr) {
+		t.Fatalf("Conflict error does not wrap an HTTPError: %v", err)
+	}
+	if httpErr.Code != http.StatusConflict || httpErr.Method != http.MethodPost || httpErr.RequestID != "req-1" {
+		t.Errorf("Unexpected HTTP error fields: %+v", httpErr)
+	}
+	if httpErr.Controller.Name != "DuplicatePropertyError" || httpErr.Controller.Message != "Duplicate registry url" {
+		t.Errorf("Failed to parse Controller error body: %+v", httpErr.Controller)
+	}
+
+	if _, err = clt.GetDefaultRouter(); !errors.Is(err, ErrForbidden) {
+		t.Errorf("Expected forbidden error, got: %v", err)
+	}
+	if _, err = clt.GetCatalogItem(1); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected not found error, got: %v", err)
+	}
+	if errors.Is(NewNotFoundError("local"), ErrConflict) {
+		t.Error("Not found error must not match conflict sentinel")
+	}
 }
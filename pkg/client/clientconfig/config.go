@@ -0,0 +1,293 @@
+/*
+ *  *******************************************************************************
+ *  * Copyright (c) 2019 Edgeworx, Inc.
+ *  *
+ *  * This program and the accompanying materials are made available under the
+ *  * terms of the Eclipse Public License v. 2.0 which is available at
+ *  * http://www.eclipse.org/legal/epl-2.0
+ *  *
+ *  * SPDX-License-Identifier: EPL-2.0
+ *  *******************************************************************************
+ *
+ */
+
+// Package clientconfig loads Controller endpoints and credentials from a YAML file holding named contexts,
+// so that tools share one file instead of each building client.Options by hand.
+//
+//	currentContext: staging
+//	contexts:
+//	- name: staging
+//	  endpoint: https://staging.example.com:51121
+//	  credentials:
+//	    email: ci@example.com
+//	    passwordEnv: IOFOG_STAGING_PASSWORD
+//	  tls:
+//	    caFile: /etc/iofog/staging-ca.pem
+//	  retries:
+//	    maxAttempts: 5
+//	  timeout: 30s
+package clientconfig
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/eclipse-iofog/iofog-go-sdk/v3/pkg/client"
+	"gopkg.in/yaml.v2"
+)
+
+// EnvConfigPath overrides the path returned by DefaultPath
+const EnvConfigPath = "IOFOG_CLIENT_CONFIG"
+
+// Config holds the named contexts of a configuration file
+type Config struct {
+	CurrentContext string    `yaml:"currentContext"`
+	Contexts       []Context `yaml:"contexts"`
+}
+
+// Context describes how to reach and authenticate with one Controller
+type Context struct {
+	Name string `yaml:"name"`
+	// Endpoint is the Controller URL, /api/v3 is appended when it has no path
+	Endpoint    string      `yaml:"endpoint"`
+	Credentials Credentials `yaml:"credentials"`
+	TLS         *TLS        `yaml:"tls,omitempty"`
+	Retries     *Retries    `yaml:"retries,omitempty"`
+	// Timeout bounds each request, e.g. 30s
+	Timeout time.Duration `yaml:"timeout,omitempty"`
+}
+
+// Credentials references an email and password or an access token.
+// Secrets should be referenced through environment variables or files, which are read again on every login so that they can be rotated
+type Credentials struct {
+	Email        string `yaml:"email,omitempty"`
+	Password     string `yaml:"password,omitempty"`
+	PasswordEnv  string `yaml:"passwordEnv,omitempty"`
+	PasswordFile string `yaml:"passwordFile,omitempty"`
+	Token        string `yaml:"token,omitempty"`
+	TokenEnv     string `yaml:"tokenEnv,omitempty"`
+	TokenFile    string `yaml:"tokenFile,omitempty"`
+}
+
+// TLS configures the CA bundle and client certificate used to reach the Controller
+type TLS struct {
+	CAFile             string `yaml:"caFile,omitempty"`
+	CertFile           string `yaml:"certFile,omitempty"`
+	KeyFile            string `yaml:"keyFile,omitempty"`
+	InsecureSkipVerify bool   `yaml:"insecureSkipVerify,omitempty"`
+}
+
+// Retries configures a client.RetryPolicy, zero fields use the defaults of client.DefaultRetryPolicy
+type Retries struct {
+	MaxAttempts    int           `yaml:"maxAttempts,omitempty"`
+	InitialBackoff time.Duration `yaml:"initialBackoff,omitempty"`
+	MaxBackoff     time.Duration `yaml:"maxBackoff,omitempty"`
+}
+
+// DefaultPath returns the value of IOFOG_CLIENT_CONFIG, or ~/.iofog/client.yaml
+func DefaultPath() (string, error) {
+	if path := os.Getenv(EnvConfigPath); path != "" {
+		return path, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".iofog", "client.yaml"), nil
+}
+
+// Load reads and validates the configuration file at path
+func Load(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(data)
+}
+
+// LoadDefault reads the configuration file at DefaultPath
+func LoadDefault() (*Config, error) {
+	path, err := DefaultPath()
+	if err != nil {
+		return nil, err
+	}
+	return Load(path)
+}
+
+// Parse decodes and validates a YAML configuration
+func Parse(data []byte) (*Config, error) {
+	config := new(Config)
+	if err := yaml.UnmarshalStrict(data, config); err != nil {
+		return nil, err
+	}
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// Validate checks that context names are unique, that each context has an endpoint and credentials,
+// and that the current context exists
+func (config *Config) Validate() error {
+	names := make(map[string]bool)
+	for idx := range config.Contexts {
+		c := &config.Contexts[idx]
+		if c.Name == "" {
+			return client.NewInputError(fmt.Sprintf("Context %d has no name", idx))
+		}
+		if names[c.Name] {
+			return client.NewInputError(fmt.Sprintf("Context %s is defined twice", c.Name))
+		}
+		names[c.Name] = true
+		if c.Endpoint == "" {
+			return client.NewInputError(fmt.Sprintf("Context %s has no endpoint", c.Name))
+		}
+		if err := c.Credentials.validate(); err != nil {
+			return client.NewInputError(fmt.Sprintf("Context %s: %s", c.Name, err.Error()))
+		}
+	}
+	if config.CurrentContext != "" && !names[config.CurrentContext] {
+		return client.NewInputError(fmt.Sprintf("Current context %s is not defined", config.CurrentContext))
+	}
+	return nil
+}
+
+func (creds *Credentials) validate() error {
+	tokens := countSet(creds.Token, creds.TokenEnv, creds.TokenFile)
+	passwords := countSet(creds.Password, creds.PasswordEnv, creds.PasswordFile)
+	switch {
+	case tokens > 1:
+		return fmt.Errorf("only one of token, tokenEnv and tokenFile may be set")
+	case passwords > 1:
+		return fmt.Errorf("only one of password, passwordEnv and passwordFile may be set")
+	case tokens == 1 && (passwords > 0 || creds.Email != ""):
+		return fmt.Errorf("credentials hold both a token and an email or password")
+	case tokens == 0 && (creds.Email == "" || passwords == 0):
+		return fmt.Errorf("credentials need either a token or an email and password")
+	}
+	return nil
+}
+
+func countSet(values ...string) (count int) {
+	for _, value := range values {
+		if value != "" {
+			count++
+		}
+	}
+	return
+}
+
+// Save writes the configuration to path, creating its directory, readable by the owner only
+func (config *Config) Save(path string) error {
+	if err := config.Validate(); err != nil {
+		return err
+	}
+	data, err := yaml.Marshal(config)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o600)
+}
+
+// Context returns the context named name, or the current context when name is empty
+func (config *Config) Context(name string) (*Context, error) {
+	if name == "" {
+		name = config.CurrentContext
+	}
+	if name == "" {
+		return nil, client.NewInputError("No context name given and no current context set")
+	}
+	for idx := range config.Contexts {
+		if config.Contexts[idx].Name == name {
+			return &config.Contexts[idx], nil
+		}
+	}
+	return nil, client.NewNotFoundError(fmt.Sprintf("Could not find context %s", name))
+}
+
+// NewClient returns a logged in client for the context named name, or the current context when name is empty
+func (config *Config) NewClient(ctx context.Context, name string) (*client.Client, error) {
+	named, err := config.Context(name)
+	if err != nil {
+		return nil, err
+	}
+	return named.NewClient(ctx)
+}
+
+// Options builds the client options of the context
+func (c *Context) Options() (opt client.Options, err error) {
+	if opt.BaseURL, err = url.Parse(c.Endpoint); err != nil {
+		return
+	}
+	if opt.BaseURL.Path == "" {
+		opt.BaseURL.Path = "/api/v3"
+	}
+	if c.TLS != nil {
+		if opt.TLSConfig, err = client.TLSConfigFromFiles(c.TLS.CAFile, c.TLS.CertFile, c.TLS.KeyFile); err != nil {
+			return
+		}
+		opt.TLSConfig.InsecureSkipVerify = c.TLS.InsecureSkipVerify //nolint:gosec
+	}
+	if c.Retries != nil {
+		opt.Retries = &client.Retries{Policy: &client.RetryPolicy{
+			MaxAttempts:    c.Retries.MaxAttempts,
+			InitialBackoff: c.Retries.InitialBackoff,
+			MaxBackoff:     c.Retries.MaxBackoff,
+		}}
+	}
+	opt.RequestTimeout = c.Timeout
+
+	creds := c.Credentials
+	switch {
+	case creds.Token != "":
+		opt.TokenSource = client.StaticToken(creds.Token)
+	case creds.TokenEnv != "":
+		opt.TokenSource = client.EnvTokenSource(creds.TokenEnv)
+	case creds.TokenFile != "":
+		opt.TokenSource = client.FileTokenSource(creds.TokenFile)
+	default:
+		opt.Credentials = passwordCredentials(creds)
+	}
+	return opt, nil
+}
+
+// NewClient returns a client for the context, logged in unless it uses an access token
+func (c *Context) NewClient(ctx context.Context) (*client.Client, error) {
+	opt, err := c.Options()
+	if err != nil {
+		return nil, err
+	}
+	if opt.TokenSource != nil {
+		return client.New(opt), nil
+	}
+	return client.NewWithCredentials(ctx, opt)
+}
+
+// passwordCredentials reads the password on every login
+type passwordCredentials Credentials
+
+func (creds passwordCredentials) Credentials(context.Context) (client.LoginRequest, error) {
+	request := client.LoginRequest{Email: creds.Email, Password: creds.Password}
+	switch {
+	case creds.PasswordEnv != "":
+		request.Password = os.Getenv(creds.PasswordEnv)
+	case creds.PasswordFile != "":
+		content, err := os.ReadFile(creds.PasswordFile)
+		if err != nil {
+			return request, err
+		}
+		request.Password = strings.TrimRight(string(content), "\r\n")
+	}
+	if request.Password == "" {
+		return request, client.NewInputError(fmt.Sprintf("No password found for %s", creds.Email))
+	}
+	return request, nil
+}
@@ -0,0 +1,105 @@
+/*
+ *  *******************************************************************************
+ *  * Copyright (c) 2019 Edgeworx, Inc.
+ *  *
+ *  * This program and the accompanying materials are made available under the
+ *  * terms of the Eclipse Public License v. 2.0 which is available at
+ *  * http://www.eclipse.org/legal/epl-2.0
+ *  *
+ *  * SPDX-License-Identifier: EPL-2.0
+ *  *******************************************************************************
+ *
+ */
+
+package clientconfig_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/eclipse-iofog/iofog-go-sdk/v3/pkg/client"
+	"github.com/eclipse-iofog/iofog-go-sdk/v3/pkg/client/clientconfig"
+	"github.com/eclipse-iofog/iofog-go-sdk/v3/pkg/client/fake"
+	"github.com/eclipse-iofog/iofog-go-sdk/v3/pkg/client/fake/faketest"
+)
+
+func TestConfigContexts(t *testing.T) {
+	ctrl, _ := faketest.NewLoggedIn(t, client.Options{})
+	t.Setenv("IOFOG_TEST_PASSWORD", fake.UserPassword)
+
+	token, err := ctrl.IssueToken(fake.UserEmail)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	tokenFile := filepath.Join(dir, "token")
+	if err := os.WriteFile(tokenFile, []byte(token), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	data := []byte(`currentContext: dev
+contexts:
+- name: dev
+  endpoint: ` + ctrl.URL().String() + `
+  credentials:
+    email: user@domain.com
+    passwordEnv: IOFOG_TEST_PASSWORD
+  timeout: 30s
+  retries:
+    maxAttempts: 2
+- name: ci
+  endpoint: ` + ctrl.URL().String() + `
+  credentials:
+    tokenFile: ` + tokenFile + `
+`)
+	config, err := clientconfig.Parse(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.Contexts[0].Timeout != 30*time.Second {
+		t.Errorf("Expected a 30s timeout, got %s", config.Contexts[0].Timeout)
+	}
+
+	// Save and load again
+	path := filepath.Join(dir, "nested", "client.yaml")
+	if err := config.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(clientconfig.EnvConfigPath, path)
+	config, err = clientconfig.LoadDefault()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range []string{"", "ci"} {
+		clt, err := config.NewClient(context.Background(), name)
+		if err != nil {
+			t.Fatalf("Context %q: %v", name, err)
+		}
+		if _, err := clt.ListAgents(client.ListAgentsRequest{}); err != nil {
+			t.Errorf("Context %q: %v", name, err)
+		}
+	}
+	if _, err := config.NewClient(context.Background(), "prod"); !errors.Is(err, client.ErrNotFound) {
+		t.Errorf("Expected an unknown context to be reported, got %v", err)
+	}
+}
+
+func TestConfigValidation(t *testing.T) {
+	invalid := map[string]string{
+		"duplicate name":  "contexts:\n- {name: a, endpoint: http://a, credentials: {token: t}}\n- {name: a, endpoint: http://b, credentials: {token: t}}\n",
+		"missing current": "currentContext: b\ncontexts:\n- {name: a, endpoint: http://a, credentials: {token: t}}\n",
+		"token and email": "contexts:\n- {name: a, endpoint: http://a, credentials: {token: t, email: e}}\n",
+		"no password":     "contexts:\n- {name: a, endpoint: http://a, credentials: {email: e}}\n",
+		"unknown field":   "contexts:\n- {name: a, endpoint: http://a, url: http://a, credentials: {token: t}}\n",
+	}
+	for desc, data := range invalid {
+		if _, err := clientconfig.Parse([]byte(data)); err == nil {
+			t.Errorf("Expected %s to be rejected", desc)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+/*
+ *  *******************************************************************************
+ *  * Copyright (c) 2019 Edgeworx, Inc.
+ *  *
+ *  * This program and the accompanying materials are made available under the
+ *  * terms of the Eclipse Public License v. 2.0 which is available at
+ *  * http://www.eclipse.org/legal/epl-2.0
+ *  *
+ *  * SPDX-License-Identifier: EPL-2.0
+ *  *******************************************************************************
+ *
+ */
+
+package client_test
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/eclipse-iofog/iofog-go-sdk/v3/pkg/client"
+	"github.com/eclipse-iofog/iofog-go-sdk/v3/pkg/client/fake/faketest"
+)
+
+// TestConcurrentCalls is meant to be run with -race
+func TestConcurrentCalls(t *testing.T) {
+	retries := client.Retries{CustomMessage: map[string]int{"timeout": 1}}
+	ctrl, clt := faketest.NewLoggedIn(t, client.Options{
+		Retries:      &retries,
+		NameCacheTTL: time.Minute,
+	})
+	uuids := []string{}
+	for idx := 0; idx < 4; idx++ {
+		agent, err := clt.CreateAgent(&client.CreateAgentRequest{AgentUpdateRequest: client.AgentUpdateRequest{Name: fmt.Sprintf("agent-%d", idx)}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		uuids = append(uuids, agent.UUID)
+	}
+
+	wg := sync.WaitGroup{}
+	errs := make(chan error, 1000)
+	for worker := 0; worker < 8; worker++ {
+		wg.Add(1)
+		go func(worker int) {
+			defer wg.Done()
+			for idx := 0; idx < 20; idx++ {
+				if _, err := clt.ListAgents(client.ListAgentsRequest{}); err != nil {
+					errs <- err
+				}
+				if _, err := clt.GetAgentByName(fmt.Sprintf("agent-%d", (worker+idx)%4), false); err != nil {
+					errs <- err
+				}
+				if _, err := clt.GetAgentByID(uuids[idx%4]); err != nil {
+					errs <- err
+				}
+				if _, err := clt.Supports(client.FeatureEdgeResources); err != nil {
+					errs <- err
+				}
+				if idx%5 == 0 {
+					clt.ResetCapabilities()
+					clt.InvalidateNameCache()
+				}
+			}
+		}(worker)
+	}
+
+	// Rotate tokens and retries while the workers run, expiring every token once
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for idx := 0; idx < 50; idx++ {
+			if idx == 25 {
+				ctrl.ExpireTokens()
+			}
+			token, err := ctrl.IssueToken("user@domain.com")
+			if err != nil {
+				errs <- err
+				return
+			}
+			clt.SetAccessToken(token)
+			current := clt.GetRetries()
+			current.CustomMessage["timeout"] = idx % 3
+			clt.SetRetries(current)
+			time.Sleep(time.Millisecond)
+		}
+	}()
+
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+	if retries.CustomMessage["timeout"] != 1 {
+		t.Errorf("Expected the retries passed in Options not to be modified, got %v", retries.CustomMessage)
+	}
+}
@@ -13,6 +13,8 @@
 
 package client
 
+import "context"
+
 type Protocol = string
 
 const (
@@ -21,11 +23,23 @@ const (
 )
 
 func (clt *Client) PutPublicPortHost(protocol Protocol, host string) (err error) {
-	_, err = clt.doRequest("PUT", "/config", newPublicPortHostRequest(protocol, host))
+	return clt.PutPublicPortHostWithContext(context.Background(), protocol, host)
+}
+
+// PutPublicPortHostWithContext is PutPublicPortHost with a context that can cancel the request or bound its deadline
+func (clt *Client) PutPublicPortHostWithContext(ctx context.Context, protocol Protocol, host string) (err error) {
+	ctx = withOperation(ctx, "PutPublicPortHost")
+	_, err = clt.doRequest(ctx, "PUT", "/config", newPublicPortHostRequest(protocol, host))
 	return
 }
 
 func (clt *Client) PutDefaultProxy(address string) (err error) {
-	_, err = clt.doRequest("PUT", "/config", newDefaultProxyRequest(address))
+	return clt.PutDefaultProxyWithContext(context.Background(), address)
+}
+
+// PutDefaultProxyWithContext is PutDefaultProxy with a context that can cancel the request or bound its deadline
+func (clt *Client) PutDefaultProxyWithContext(ctx context.Context, address string) (err error) {
+	ctx = withOperation(ctx, "PutDefaultProxy")
+	_, err = clt.doRequest(ctx, "PUT", "/config", newDefaultProxyRequest(address))
 	return
 }
@@ -13,18 +13,41 @@
 
 package client
 
-import "encoding/json"
+import (
+	"context"
+	"encoding/json"
+)
 
 func (clt *Client) GetStatus() (status ControllerStatus, err error) {
+	return clt.GetStatusWithContext(context.Background())
+}
+
+// GetStatusWithContext is GetStatus with a context that can cancel the request or bound its deadline
+func (clt *Client) GetStatusWithContext(ctx context.Context) (status ControllerStatus, err error) {
+	ctx = withOperation(ctx, "GetStatus")
 	// Prepare request
-	body, err := clt.doRequest("GET", "/status", nil)
+	body, err := clt.doRequest(ctx, "GET", "/status", nil)
 	if err != nil {
+		clt.setStatusError(err)
 		return
 	}
 
 	// Return body
 	if err = json.Unmarshal(body, &status); err != nil {
+		clt.setStatusError(err)
 		return
 	}
+
+	// Cache the version
+	clt.mu.Lock()
+	clt.status = newControllerStatus(status.Versions.Controller)
+	clt.statusErr = nil
+	clt.mu.Unlock()
 	return
 }
+
+func (clt *Client) setStatusError(err error) {
+	clt.mu.Lock()
+	defer clt.mu.Unlock()
+	clt.statusErr = err
+}
@@ -0,0 +1,95 @@
+/*
+ *  *******************************************************************************
+ *  * Copyright (c) 2019 Edgeworx, Inc.
+ *  *
+ *  * This program and the accompanying materials are made available under the
+ *  * terms of the Eclipse Public License v. 2.0 which is available at
+ *  * http://www.eclipse.org/legal/epl-2.0
+ *  *
+ *  * SPDX-License-Identifier: EPL-2.0
+ *  *******************************************************************************
+ *
+ */
+
+package client
+
+import (
+	"context"
+	"errors"
+)
+
+// CredentialProvider supplies the credentials used to log in again when the Controller rejects the access token
+type CredentialProvider interface {
+	Credentials(ctx context.Context) (LoginRequest, error)
+}
+
+// StaticCredentials is a CredentialProvider always returning the same email and password
+type StaticCredentials LoginRequest
+
+// Credentials export
+func (creds StaticCredentials) Credentials(context.Context) (LoginRequest, error) {
+	return LoginRequest(creds), nil
+}
+
+type skipReloginKey struct{}
+
+// withoutRelogin marks ctx so that a 401 response is returned as is instead of triggering a new login
+func withoutRelogin(ctx context.Context) context.Context {
+	return context.WithValue(ctx, skipReloginKey{}, true)
+}
+
+func canRelogin(ctx context.Context) bool {
+	skip, _ := ctx.Value(skipReloginKey{}).(bool)
+	return !skip
+}
+
+func isUnauthorized(err error) bool {
+	return errors.Is(err, ErrUnauthorized)
+}
+
+func (clt *Client) credentialProvider() CredentialProvider {
+	clt.mu.RLock()
+	defer clt.mu.RUnlock()
+	if clt.loggedOut {
+		return nil
+	}
+	if clt.credentials != nil {
+		return clt.credentials
+	}
+	if clt.lastLogin != nil {
+		return StaticCredentials(*clt.lastLogin)
+	}
+	return nil
+}
+
+// relogin logs in again unless another caller already replaced staleToken in the meantime.
+// With a TokenSource, the token is read again instead, e.g. in case it was rotated during the request.
+// It returns false when no credentials are available to log in with.
+func (clt *Client) relogin(ctx context.Context, staleToken string) (bool, error) {
+	if clt.tokenSource != nil {
+		token, err := clt.refreshToken(ctx)
+		if err != nil {
+			return true, err
+		}
+		return token != staleToken, nil
+	}
+
+	provider := clt.credentialProvider()
+	if provider == nil {
+		return false, nil
+	}
+
+	clt.loginMu.Lock()
+	defer clt.loginMu.Unlock()
+
+	// Concurrent callers hitting the same expired token only log in once
+	if clt.GetAccessToken() != staleToken {
+		return true, nil
+	}
+
+	creds, err := provider.Credentials(ctx)
+	if err != nil {
+		return true, err
+	}
+	return true, clt.LoginWithContext(WithOperation(ctx, "Login"), creds)
+}
@@ -14,6 +14,7 @@
 package client
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 )
@@ -23,19 +24,18 @@ const (
 )
 
 func (clt *Client) IsEdgeResourceCapable() error {
-	if _, err := clt.doRequest("HEAD", "/capabilities/edgeResources", nil); err != nil {
-		// If 404, not capable
-		if _, ok := err.(*NotFoundError); ok {
-			return NewNotSupportedError("Edge Resources")
-		}
-		return err
-	}
-	return nil
+	return clt.IsEdgeResourceCapableWithContext(context.Background())
 }
 
-func (clt *Client) edgeResourcePreflight() error {
+// IsEdgeResourceCapableWithContext is IsEdgeResourceCapable with a context that can cancel the request or bound its deadline
+func (clt *Client) IsEdgeResourceCapableWithContext(ctx context.Context) error {
+	ctx = withOperation(ctx, "IsEdgeResourceCapable")
+	return clt.requireFeature(ctx, FeatureEdgeResources, "Edge Resources")
+}
+
+func (clt *Client) edgeResourcePreflight(ctx context.Context) error {
 	// Check capability
-	if err := clt.IsEdgeResourceCapable(); err != nil {
+	if err := clt.IsEdgeResourceCapableWithContext(ctx); err != nil {
 		return err
 	}
 
@@ -48,12 +48,18 @@ func (clt *Client) edgeResourcePreflight() error {
 
 // CreateHttpEdgeResource creates an Edge Resource using Controller REST API
 func (clt *Client) CreateHTTPEdgeResource(request *EdgeResourceMetadata) error {
-	if err := clt.edgeResourcePreflight(); err != nil {
+	return clt.CreateHTTPEdgeResourceWithContext(context.Background(), request)
+}
+
+// CreateHTTPEdgeResourceWithContext is CreateHTTPEdgeResource with a context that can cancel the request or bound its deadline
+func (clt *Client) CreateHTTPEdgeResourceWithContext(ctx context.Context, request *EdgeResourceMetadata) error {
+	ctx = withOperation(ctx, "CreateHTTPEdgeResource")
+	if err := clt.edgeResourcePreflight(ctx); err != nil {
 		return err
 	}
 
 	// Send request
-	if _, err := clt.doRequest("POST", "/edgeResource", request); err != nil {
+	if _, err := clt.doRequest(ctx, "POST", "/edgeResource", request); err != nil {
 		return err
 	}
 
@@ -62,12 +68,18 @@ func (clt *Client) CreateHTTPEdgeResource(request *EdgeResourceMetadata) error {
 
 // GetHttpEdgeResourceByName gets an Edge Resource using Controller REST API
 func (clt *Client) GetHTTPEdgeResourceByName(name, version string) (response EdgeResourceMetadata, err error) {
-	if err := clt.edgeResourcePreflight(); err != nil {
+	return clt.GetHTTPEdgeResourceByNameWithContext(context.Background(), name, version)
+}
+
+// GetHTTPEdgeResourceByNameWithContext is GetHTTPEdgeResourceByName with a context that can cancel the request or bound its deadline
+func (clt *Client) GetHTTPEdgeResourceByNameWithContext(ctx context.Context, name, version string) (response EdgeResourceMetadata, err error) {
+	ctx = withOperation(ctx, "GetHTTPEdgeResourceByName")
+	if err := clt.edgeResourcePreflight(ctx); err != nil {
 		return response, err
 	}
 
 	// Send request
-	body, err := clt.doRequest("GET", fmt.Sprintf("/edgeResource/%s/%s", name, version), nil)
+	body, err := clt.doRequest(ctx, "GET", fmt.Sprintf("/edgeResource/%s/%s", name, version), nil)
 	if err != nil {
 		return
 	}
@@ -80,30 +92,36 @@ func (clt *Client) GetHTTPEdgeResourceByName(name, version string) (response Edg
 
 // ListEdgeResources list all Edge Resources using Controller REST API
 func (clt *Client) ListEdgeResources() (response ListEdgeResourceResponse, err error) {
-	if err := clt.edgeResourcePreflight(); err != nil {
-		return response, err
-	}
-
-	// Send request
-	body, err := clt.doRequest("GET", "/edgeResources", nil)
-	if err != nil {
-		return
-	}
+	return clt.ListEdgeResourcesWithContext(context.Background())
+}
 
-	if err = json.Unmarshal(body, &response); err != nil {
-		return
+// ListEdgeResourcesWithContext is ListEdgeResources with a context that can cancel the request or bound its deadline
+func (clt *Client) ListEdgeResourcesWithContext(ctx context.Context) (response ListEdgeResourceResponse, err error) {
+	ctx = withOperation(ctx, "ListEdgeResources")
+	edgeResources := clt.IterateEdgeResources(ctx)
+	defer edgeResources.Close()
+	response.EdgeResources = []EdgeResourceMetadata{}
+	for edgeResources.Next() {
+		response.EdgeResources = append(response.EdgeResources, edgeResources.EdgeResource())
 	}
+	err = edgeResources.Err()
 	return
 }
 
 // UpdateHttpEdgeResource updates an HTTP Based Edge Resources using Controller REST API
 func (clt *Client) UpdateHTTPEdgeResource(name string, request *EdgeResourceMetadata) error {
-	if err := clt.edgeResourcePreflight(); err != nil {
+	return clt.UpdateHTTPEdgeResourceWithContext(context.Background(), name, request)
+}
+
+// UpdateHTTPEdgeResourceWithContext is UpdateHTTPEdgeResource with a context that can cancel the request or bound its deadline
+func (clt *Client) UpdateHTTPEdgeResourceWithContext(ctx context.Context, name string, request *EdgeResourceMetadata) error {
+	ctx = withOperation(ctx, "UpdateHTTPEdgeResource")
+	if err := clt.edgeResourcePreflight(ctx); err != nil {
 		return err
 	}
 
 	// Send request
-	if _, err := clt.doRequest("PUT", fmt.Sprintf("/edgeResource/%s/%s", name, request.Version), request); err != nil {
+	if _, err := clt.doRequest(ctx, "PUT", fmt.Sprintf("/edgeResource/%s/%s", name, request.Version), request); err != nil {
 		return err
 	}
 
@@ -112,12 +130,18 @@ func (clt *Client) UpdateHTTPEdgeResource(name string, request *EdgeResourceMeta
 
 // ListEdgeResources list all Edge Resources using Controller REST API
 func (clt *Client) DeleteEdgeResource(name, version string) error {
-	if err := clt.edgeResourcePreflight(); err != nil {
+	return clt.DeleteEdgeResourceWithContext(context.Background(), name, version)
+}
+
+// DeleteEdgeResourceWithContext is DeleteEdgeResource with a context that can cancel the request or bound its deadline
+func (clt *Client) DeleteEdgeResourceWithContext(ctx context.Context, name, version string) error {
+	ctx = withOperation(ctx, "DeleteEdgeResource")
+	if err := clt.edgeResourcePreflight(ctx); err != nil {
 		return err
 	}
 
 	// Send request
-	if _, err := clt.doRequest("DELETE", fmt.Sprintf("/edgeResource/%s/%s", name, version), nil); err != nil {
+	if _, err := clt.doRequest(ctx, "DELETE", fmt.Sprintf("/edgeResource/%s/%s", name, version), nil); err != nil {
 		return err
 	}
 
@@ -126,13 +150,19 @@ func (clt *Client) DeleteEdgeResource(name, version string) error {
 
 // LinkEdgeResource links an Edge Resource to an Agent using Controller REST API
 func (clt *Client) LinkEdgeResource(request LinkEdgeResourceRequest) error {
-	if err := clt.edgeResourcePreflight(); err != nil {
+	return clt.LinkEdgeResourceWithContext(context.Background(), request)
+}
+
+// LinkEdgeResourceWithContext is LinkEdgeResource with a context that can cancel the request or bound its deadline
+func (clt *Client) LinkEdgeResourceWithContext(ctx context.Context, request LinkEdgeResourceRequest) error {
+	ctx = withOperation(ctx, "LinkEdgeResource")
+	if err := clt.edgeResourcePreflight(ctx); err != nil {
 		return err
 	}
 
 	// Send request
 	url := fmt.Sprintf("/edgeResource/%s/%s/link", request.EdgeResourceName, request.EdgeResourceVersion)
-	if _, err := clt.doRequest("POST", url, request); err != nil {
+	if _, err := clt.doRequest(ctx, "POST", url, request); err != nil {
 		return err
 	}
 
@@ -141,12 +171,18 @@ func (clt *Client) LinkEdgeResource(request LinkEdgeResourceRequest) error {
 
 // UnlinkEdgeResource unlinks an Edge Resource from an Agent using Controller REST API
 func (clt *Client) UnlinkEdgeResource(request LinkEdgeResourceRequest) error {
-	if err := clt.edgeResourcePreflight(); err != nil {
+	return clt.UnlinkEdgeResourceWithContext(context.Background(), request)
+}
+
+// UnlinkEdgeResourceWithContext is UnlinkEdgeResource with a context that can cancel the request or bound its deadline
+func (clt *Client) UnlinkEdgeResourceWithContext(ctx context.Context, request LinkEdgeResourceRequest) error {
+	ctx = withOperation(ctx, "UnlinkEdgeResource")
+	if err := clt.edgeResourcePreflight(ctx); err != nil {
 		return err
 	}
 	// Send request
 	url := fmt.Sprintf("/edgeResource/%s/%s/link", request.EdgeResourceName, request.EdgeResourceVersion)
-	if _, err := clt.doRequest("DELETE", url, request); err != nil {
+	if _, err := clt.doRequest(ctx, "DELETE", url, request); err != nil {
 		return err
 	}
 
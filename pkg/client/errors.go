@@ -14,7 +14,23 @@
 package client
 
 import (
+	"errors"
 	"fmt"
+	"strings"
+	"time"
+)
+
+// Sentinel errors to match with errors.Is
+var (
+	ErrNotFound     = errors.New("resource not found")
+	ErrConflict     = errors.New("resource conflict")
+	ErrUnauthorized = errors.New("unauthorized")
+	ErrForbidden    = errors.New("forbidden")
+	ErrNotSupported = errors.New("not supported by Controller")
+	ErrInput        = errors.New("invalid user input")
+	ErrAmbiguous    = errors.New("ambiguous resource name")
+	// ErrMicroserviceFailed is wrapped by the *WaitError returned when an awaited Microservice fails
+	ErrMicroserviceFailed = errors.New("microservice failed")
 )
 
 type Error struct {
@@ -33,7 +49,8 @@ func (err *Error) Error() string {
 
 // NotFoundError export
 type NotFoundError struct {
-	msg string
+	msg     string
+	httpErr *HTTPError
 }
 
 // NewNotFoundError export
@@ -48,9 +65,20 @@ func (err *NotFoundError) Error() string {
 	return fmt.Sprintf("Unknown resource error\n%s", err.msg)
 }
 
+// Is export
+func (err *NotFoundError) Is(target error) bool {
+	return target == ErrNotFound
+}
+
+// Unwrap returns the Controller response the error was built from, if any
+func (err *NotFoundError) Unwrap() error {
+	return unwrapHTTPError(err.httpErr)
+}
+
 // ConflictError export
 type ConflictError struct {
-	msg string
+	msg     string
+	httpErr *HTTPError
 }
 
 // NewConflictError export
@@ -65,6 +93,56 @@ func (err *ConflictError) Error() string {
 	return fmt.Sprintf("Resource conflict error\n%s", err.msg)
 }
 
+// Is export
+func (err *ConflictError) Is(target error) bool {
+	return target == ErrConflict
+}
+
+// Unwrap returns the Controller response the error was built from, if any
+func (err *ConflictError) Unwrap() error {
+	return unwrapHTTPError(err.httpErr)
+}
+
+// UnauthorizedError is returned when the Controller rejects the access token or credentials (401)
+type UnauthorizedError struct {
+	httpErr *HTTPError
+}
+
+// Error export
+func (err *UnauthorizedError) Error() string {
+	return "Unauthorized error\n" + err.httpErr.message
+}
+
+// Is export
+func (err *UnauthorizedError) Is(target error) bool {
+	return target == ErrUnauthorized
+}
+
+// Unwrap export
+func (err *UnauthorizedError) Unwrap() error {
+	return err.httpErr
+}
+
+// ForbiddenError is returned when the logged in user may not perform the request (403)
+type ForbiddenError struct {
+	httpErr *HTTPError
+}
+
+// Error export
+func (err *ForbiddenError) Error() string {
+	return "Forbidden error\n" + err.httpErr.message
+}
+
+// Is export
+func (err *ForbiddenError) Is(target error) bool {
+	return target == ErrForbidden
+}
+
+// Unwrap export
+func (err *ForbiddenError) Unwrap() error {
+	return err.httpErr
+}
+
 // InputError export
 type InputError struct {
 	message string
@@ -82,6 +160,37 @@ func (err *InputError) Error() string {
 	return "User input error\n" + err.message
 }
 
+// Is export
+func (err *InputError) Is(target error) bool {
+	return target == ErrInput
+}
+
+// AmbiguousError is returned by name lookups when several resources share the name
+type AmbiguousError struct {
+	Kind string
+	Name string
+	IDs  []string
+}
+
+// NewAmbiguousError export
+func NewAmbiguousError(kind, name string, ids []string) (err *AmbiguousError) {
+	err = new(AmbiguousError)
+	err.Kind = kind
+	err.Name = name
+	err.IDs = ids
+	return err
+}
+
+// Error export
+func (err *AmbiguousError) Error() string {
+	return fmt.Sprintf("Ambiguous %s name\n%d resources are named %s: %s", err.Kind, len(err.IDs), err.Name, strings.Join(err.IDs, ", "))
+}
+
+// Is export
+func (err *AmbiguousError) Is(target error) bool {
+	return target == ErrAmbiguous
+}
+
 // InternalError export
 type InternalError struct {
 	message string
@@ -99,10 +208,23 @@ func (err *InternalError) Error() string {
 	return "Unexpected internal behaviour\n" + err.message
 }
 
+// ControllerError is the error body returned by the Controller REST API
+type ControllerError struct {
+	Name    string `json:"name"`
+	Message string `json:"message"`
+}
+
 // HTTPError export
 type HTTPError struct {
 	message string
 	Code    int
+	Method  string
+	URL     string
+	// Controller holds the parsed error body, empty when the body was not a Controller error
+	Controller ControllerError
+	// RequestID is read from the X-Request-Id response header
+	RequestID  string
+	retryAfter time.Duration
 }
 
 // NewHTTPError export
@@ -118,6 +240,14 @@ func (err *HTTPError) Error() string {
 	return "Unexpected HTTP response\n" + err.message
 }
 
+// unwrapHTTPError avoids returning a typed nil when no response is attached
+func unwrapHTTPError(err *HTTPError) error {
+	if err == nil {
+		return nil
+	}
+	return err
+}
+
 // NotSupported export
 type NotSupportedError struct {
 	capability string
@@ -134,3 +264,8 @@ func NewNotSupportedError(capability string) (err *NotSupportedError) {
 func (err *NotSupportedError) Error() string {
 	return "Controller API does not support " + err.capability
 }
+
+// Is export
+func (err *NotSupportedError) Is(target error) bool {
+	return target == ErrNotSupported
+}
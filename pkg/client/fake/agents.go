@@ -0,0 +1,324 @@
+/*
+ *  *******************************************************************************
+ *  * Copyright (c) 2019 Edgeworx, Inc.
+ *  *
+ *  * This program and the accompanying materials are made available under the
+ *  * terms of the Eclipse Public License v. 2.0 which is available at
+ *  * http://www.eclipse.org/legal/epl-2.0
+ *  *
+ *  * SPDX-License-Identifier: EPL-2.0
+ *  *******************************************************************************
+ *
+ */
+
+package fake
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/eclipse-iofog/iofog-go-sdk/v3/pkg/client"
+)
+
+// Agent commands recorded by AgentCommands
+const (
+	RebootCommand   = "reboot"
+	PruneCommand    = "prune"
+	UpgradeCommand  = "upgrade"
+	RollbackCommand = "rollback"
+)
+
+// UpdateAgent applies update to the stored Agent, e.g. to simulate a status report
+func (ctrl *Controller) UpdateAgent(uuid string, update func(agent *client.AgentInfo)) error {
+	ctrl.mu.Lock()
+	defer ctrl.mu.Unlock()
+	agent, exists := ctrl.agents[uuid]
+	if !exists {
+		return fmt.Errorf("unknown agent %s", uuid)
+	}
+	update(agent)
+	return nil
+}
+
+// AgentCommands returns the reboot, prune, upgrade and rollback commands received for an Agent, in order
+func (ctrl *Controller) AgentCommands(uuid string) []string {
+	ctrl.mu.Lock()
+	defer ctrl.mu.Unlock()
+	return append([]string(nil), ctrl.agentCommands[uuid]...)
+}
+
+func (ctrl *Controller) registerAgentRoutes() {
+	ctrl.handle(http.MethodPost, "/iofog", ctrl.createAgent)
+	ctrl.handle(http.MethodGet, "/iofog-list", ctrl.listAgents)
+	ctrl.handle(http.MethodGet, "/iofog/:uuid", ctrl.getAgent)
+	ctrl.handle(http.MethodPatch, "/iofog/:uuid", ctrl.patchAgent)
+	ctrl.handle(http.MethodDelete, "/iofog/:uuid", ctrl.deleteAgent)
+	ctrl.handle(http.MethodGet, "/iofog/:uuid/provisioning-key", ctrl.getProvisionKey)
+	ctrl.handle(http.MethodPost, "/iofog/:uuid/reboot", ctrl.agentCommand(RebootCommand))
+	ctrl.handle(http.MethodPost, "/iofog/:uuid/prune", ctrl.agentCommand(PruneCommand))
+	ctrl.handle(http.MethodPost, "/iofog/:uuid/version/upgrade", ctrl.agentCommand(UpgradeCommand))
+	ctrl.handle(http.MethodPost, "/iofog/:uuid/version/rollback", ctrl.agentCommand(RollbackCommand))
+}
+
+func (ctrl *Controller) createAgent(w http.ResponseWriter, r *http.Request, _ []string) {
+	request := client.CreateAgentRequest{}
+	if !readJSON(w, r, &request) {
+		return
+	}
+	if request.Name == "" {
+		writeError(w, http.StatusBadRequest, "ValidationError", "Agent name is required")
+		return
+	}
+
+	ctrl.mu.Lock()
+	defer ctrl.mu.Unlock()
+	for _, agent := range ctrl.agents {
+		if agent.Name == request.Name {
+			writeError(w, http.StatusConflict, "DuplicatePropertyError", fmt.Sprintf("Duplicate name '%s'", request.Name))
+			return
+		}
+	}
+	now := time.Now().UTC().Format(time.RFC3339)
+	agent := &client.AgentInfo{
+		UUID:               randomHex(32),
+		Name:               request.Name,
+		Location:           request.Location,
+		Latitude:           request.Latitude,
+		Longitude:          request.Longitude,
+		Description:        request.Description,
+		Tags:               request.Tags,
+		DaemonStatus:       "UNKNOWN",
+		CreatedTimeRFC3339: now,
+		UpdatedTimeRFC3339: now,
+		FogType:            0,
+	}
+	if request.FogType != nil {
+		agent.FogType = int(*request.FogType)
+	}
+	applyAgentConfiguration(agent, &request.AgentConfiguration)
+	ctrl.agents[agent.UUID] = agent
+	writeJSON(w, http.StatusCreated, map[string]string{"uuid": agent.UUID})
+}
+
+func (ctrl *Controller) listAgents(w http.ResponseWriter, r *http.Request, _ []string) {
+	query := r.URL.Query()
+	system := query.Get("system") == "true"
+	filters := parseAgentFilters(query)
+
+	ctrl.mu.Lock()
+	defer ctrl.mu.Unlock()
+	response := client.ListAgentsResponse{Agents: []client.AgentInfo{}}
+	for _, uuid := range ctrl.sortedAgentUUIDs() {
+		agent := ctrl.agents[uuid]
+		if isSystemAgent(agent) != system || !matchesAgentFilters(agent, filters) {
+			continue
+		}
+		response.Agents = append(response.Agents, *agent)
+	}
+	start, end := ctrl.page(query, len(response.Agents))
+	response.Agents = response.Agents[start:end]
+	writeJSON(w, http.StatusOK, response)
+}
+
+func (ctrl *Controller) getAgent(w http.ResponseWriter, r *http.Request, params []string) {
+	ctrl.mu.Lock()
+	defer ctrl.mu.Unlock()
+	agent, exists := ctrl.agents[params[0]]
+	if !exists {
+		writeError(w, http.StatusNotFound, "NotFoundError", fmt.Sprintf("Invalid ioFog UUID '%s'", params[0]))
+		return
+	}
+	writeJSON(w, http.StatusOK, agent)
+}
+
+func (ctrl *Controller) patchAgent(w http.ResponseWriter, r *http.Request, params []string) {
+	request := client.AgentUpdateRequest{}
+	if !readJSON(w, r, &request) {
+		return
+	}
+	ctrl.mu.Lock()
+	defer ctrl.mu.Unlock()
+	agent, exists := ctrl.agents[params[0]]
+	if !exists {
+		writeError(w, http.StatusNotFound, "NotFoundError", fmt.Sprintf("Invalid ioFog UUID '%s'", params[0]))
+		return
+	}
+	if request.Name != "" {
+		agent.Name = request.Name
+	}
+	if request.Location != "" {
+		agent.Location = request.Location
+	}
+	if request.Description != "" {
+		agent.Description = request.Description
+	}
+	if request.Latitude != 0 || request.Longitude != 0 {
+		agent.Latitude = request.Latitude
+		agent.Longitude = request.Longitude
+	}
+	if request.FogType != nil {
+		agent.FogType = int(*request.FogType)
+	}
+	if request.Tags != nil {
+		agent.Tags = request.Tags
+	}
+	applyAgentConfiguration(agent, &request.AgentConfiguration)
+	agent.UpdatedTimeRFC3339 = time.Now().UTC().Format(time.RFC3339)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (ctrl *Controller) deleteAgent(w http.ResponseWriter, r *http.Request, params []string) {
+	ctrl.mu.Lock()
+	defer ctrl.mu.Unlock()
+	if _, exists := ctrl.agents[params[0]]; !exists {
+		writeError(w, http.StatusNotFound, "NotFoundError", fmt.Sprintf("Invalid ioFog UUID '%s'", params[0]))
+		return
+	}
+	delete(ctrl.agents, params[0])
+	delete(ctrl.provisionKeys, params[0])
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (ctrl *Controller) getProvisionKey(w http.ResponseWriter, r *http.Request, params []string) {
+	ctrl.mu.Lock()
+	defer ctrl.mu.Unlock()
+	if _, exists := ctrl.agents[params[0]]; !exists {
+		writeError(w, http.StatusNotFound, "NotFoundError", fmt.Sprintf("Invalid ioFog UUID '%s'", params[0]))
+		return
+	}
+	key := client.GetAgentProvisionKeyResponse{
+		Key:             randomHex(8),
+		ExpireTimeMsUTC: time.Now().Add(20*time.Minute).UnixNano() / int64(time.Millisecond),
+	}
+	ctrl.provisionKeys[params[0]] = key
+	writeJSON(w, http.StatusCreated, key)
+}
+
+func (ctrl *Controller) agentCommand(command string) handlerFunc {
+	return func(w http.ResponseWriter, r *http.Request, params []string) {
+		ctrl.mu.Lock()
+		defer ctrl.mu.Unlock()
+		if _, exists := ctrl.agents[params[0]]; !exists {
+			writeError(w, http.StatusNotFound, "NotFoundError", fmt.Sprintf("Invalid ioFog UUID '%s'", params[0]))
+			return
+		}
+		ctrl.agentCommands[params[0]] = append(ctrl.agentCommands[params[0]], command)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func (ctrl *Controller) sortedAgentUUIDs() []string {
+	uuids := make([]string, 0, len(ctrl.agents))
+	for uuid := range ctrl.agents {
+		uuids = append(uuids, uuid)
+	}
+	sort.Slice(uuids, func(i, j int) bool {
+		return ctrl.agents[uuids[i]].Name < ctrl.agents[uuids[j]].Name
+	})
+	return uuids
+}
+
+func (ctrl *Controller) findAgentByName(name string) *client.AgentInfo {
+	for _, agent := range ctrl.agents {
+		if agent.Name == name {
+			return agent
+		}
+	}
+	return nil
+}
+
+func isSystemAgent(agent *client.AgentInfo) bool {
+	return agent.RouterMode == "interior"
+}
+
+func applyAgentConfiguration(agent *client.AgentInfo, config *client.AgentConfiguration) {
+	if config.Host != nil {
+		agent.Host = *config.Host
+	}
+	if config.DockerURL != nil {
+		agent.DockerURL = *config.DockerURL
+	}
+	if config.DiskLimit != nil {
+		agent.DiskLimit = *config.DiskLimit
+	}
+	if config.MemoryLimit != nil {
+		agent.MemoryLimit = *config.MemoryLimit
+	}
+	if config.CPULimit != nil {
+		agent.CPULimit = *config.CPULimit
+	}
+	if config.LogLevel != nil {
+		agent.LogLevel = config.LogLevel
+	}
+	if config.RouterMode != nil {
+		agent.RouterMode = *config.RouterMode
+	}
+	if config.IsSystem != nil && *config.IsSystem {
+		agent.RouterMode = "interior"
+	}
+	if config.UpstreamRouters != nil {
+		agent.UpstreamRouters = config.UpstreamRouters
+	}
+	if config.NetworkRouter != nil {
+		agent.NetworkRouter = config.NetworkRouter
+	}
+	if config.TimeZone != "" {
+		agent.TimeZone = config.TimeZone
+	}
+}
+
+type agentFilter struct {
+	key       string
+	value     string
+	condition string
+}
+
+func parseAgentFilters(query map[string][]string) (filters []agentFilter) {
+	for idx := 0; ; idx++ {
+		prefix := fmt.Sprintf("filters[%d]", idx)
+		key, exists := query[prefix+"[key]"]
+		if !exists || len(key) == 0 {
+			return filters
+		}
+		filter := agentFilter{key: key[0], condition: "equals"}
+		if value := query[prefix+"[value]"]; len(value) > 0 {
+			filter.value = value[0]
+		}
+		if condition := query[prefix+"[condition]"]; len(condition) > 0 && condition[0] != "" {
+			filter.condition = condition[0]
+		}
+		filters = append(filters, filter)
+	}
+}
+
+// matchesAgentFilters compares filters with the JSON representation of the Agent
+func matchesAgentFilters(agent *client.AgentInfo, filters []agentFilter) bool {
+	if len(filters) == 0 {
+		return true
+	}
+	agentBytes, _ := json.Marshal(agent)
+	fields := make(map[string]interface{})
+	_ = json.Unmarshal(agentBytes, &fields)
+	for _, filter := range filters {
+		value, exists := fields[filter.key]
+		if !exists {
+			return false
+		}
+		actual := fmt.Sprint(value)
+		switch filter.condition {
+		case "has":
+			if !strings.Contains(actual, filter.value) {
+				return false
+			}
+		default:
+			if actual != filter.value {
+				return false
+			}
+		}
+	}
+	return true
+}
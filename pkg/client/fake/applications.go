@@ -0,0 +1,831 @@
+/*
+ *  *******************************************************************************
+ *  * Copyright (c) 2019 Edgeworx, Inc.
+ *  *
+ *  * This program and the accompanying materials are made available under the
+ *  * terms of the Eclipse Public License v. 2.0 which is available at
+ *  * http://www.eclipse.org/legal/epl-2.0
+ *  *
+ *  * SPDX-License-Identifier: EPL-2.0
+ *  *******************************************************************************
+ *
+ */
+
+package fake
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/eclipse-iofog/iofog-go-sdk/v3/pkg/apps"
+	"github.com/eclipse-iofog/iofog-go-sdk/v3/pkg/client"
+	"gopkg.in/yaml.v2"
+)
+
+// Initial status of microservices created through the fake Controller
+const QueuedStatus = client.MicroserviceStatusQueued
+
+type applicationFile struct {
+	Metadata apps.HeaderMetadata `yaml:"metadata"`
+	Spec     apps.Application    `yaml:"spec"`
+}
+
+type microserviceFile struct {
+	Metadata apps.HeaderMetadata `yaml:"metadata"`
+	Spec     apps.Microservice   `yaml:"spec"`
+}
+
+type templateFile struct {
+	Metadata apps.HeaderMetadata      `yaml:"metadata"`
+	Spec     apps.ApplicationTemplate `yaml:"spec"`
+}
+
+// UpdateMicroservice applies update to the stored microservice, e.g. to simulate an image pull or a status report
+func (ctrl *Controller) UpdateMicroservice(uuid string, update func(msvc *client.MicroserviceInfo)) error {
+	ctrl.mu.Lock()
+	defer ctrl.mu.Unlock()
+	msvc, exists := ctrl.microservices[uuid]
+	if !exists {
+		return fmt.Errorf("unknown microservice %s", uuid)
+	}
+	update(msvc)
+	return nil
+}
+
+func (ctrl *Controller) registerApplicationRoutes() {
+	ctrl.handle(http.MethodGet, "/application", ctrl.listApplications)
+	ctrl.handle(http.MethodPost, "/application/yaml", ctrl.createApplicationFromYAML)
+	ctrl.handle(http.MethodPut, "/application/yaml/:name", ctrl.updateApplicationFromYAML)
+	ctrl.handle(http.MethodGet, "/application/:name", ctrl.getApplication)
+	ctrl.handle(http.MethodPatch, "/application/:name", ctrl.patchApplication)
+	ctrl.handle(http.MethodDelete, "/application/:name", ctrl.deleteApplication)
+
+	ctrl.handle(http.MethodGet, "/microservices", ctrl.listMicroservices)
+	ctrl.handle(http.MethodGet, "/microservices/public-ports", ctrl.listPublicPorts)
+	ctrl.handle(http.MethodPost, "/microservices/yaml", ctrl.createMicroserviceFromYAML)
+	ctrl.handle(http.MethodPatch, "/microservices/yaml/:uuid", ctrl.updateMicroserviceFromYAML)
+	ctrl.handle(http.MethodGet, "/microservices/:uuid", ctrl.getMicroservice)
+	ctrl.handle(http.MethodDelete, "/microservices/:uuid", ctrl.deleteMicroservice)
+	ctrl.handle(http.MethodGet, "/microservices/:uuid/port-mapping", ctrl.listPortMappings)
+	ctrl.handle(http.MethodPost, "/microservices/:uuid/port-mapping", ctrl.createPortMapping)
+	ctrl.handle(http.MethodDelete, "/microservices/:uuid/port-mapping/:internal", ctrl.deletePortMapping)
+	ctrl.handle(http.MethodPost, "/microservices/:uuid/routes/:dest", ctrl.createMicroserviceRoute)
+	ctrl.handle(http.MethodDelete, "/microservices/:uuid/routes/:dest", ctrl.deleteMicroserviceRoute)
+
+	ctrl.handle(http.MethodGet, "/routes", ctrl.listRoutes)
+	ctrl.handle(http.MethodPost, "/routes", ctrl.createRoute)
+	ctrl.handle(http.MethodGet, "/routes/:app/:name", ctrl.getRoute)
+	ctrl.handle(http.MethodPatch, "/routes/:app/:name", ctrl.patchRoute)
+	ctrl.handle(http.MethodDelete, "/routes/:app/:name", ctrl.deleteRoute)
+
+	ctrl.handle(http.MethodGet, "/applicationTemplates", ctrl.listTemplates)
+	ctrl.handle(http.MethodPost, "/applicationTemplate/yaml", ctrl.createTemplateFromYAML)
+	ctrl.handle(http.MethodPut, "/applicationTemplate/yaml/:name", ctrl.updateTemplateFromYAML)
+	ctrl.handle(http.MethodGet, "/applicationTemplate/:name", ctrl.getTemplate)
+	ctrl.handle(http.MethodPatch, "/applicationTemplate/:name", ctrl.patchTemplate)
+	ctrl.handle(http.MethodDelete, "/applicationTemplate/:name", ctrl.deleteTemplate)
+}
+
+// readYAMLFile decodes the YAML file sent as multipart form field into target
+func readYAMLFile(w http.ResponseWriter, r *http.Request, field string, target interface{}) bool {
+	file, _, err := r.FormFile(field)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "ValidationError", err.Error())
+		return false
+	}
+	defer file.Close()
+	content, err := io.ReadAll(file)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "ValidationError", err.Error())
+		return false
+	}
+	if err := yaml.Unmarshal(content, target); err != nil {
+		writeError(w, http.StatusBadRequest, "ValidationError", err.Error())
+		return false
+	}
+	return true
+}
+
+func (ctrl *Controller) applicationView(app *client.ApplicationInfo) client.ApplicationInfo {
+	view := *app
+	view.Microservices = []client.MicroserviceInfo{}
+	for _, uuid := range ctrl.sortedMicroserviceUUIDs(app.Name) {
+		view.Microservices = append(view.Microservices, *ctrl.microservices[uuid])
+	}
+	view.Routes = []client.Route{}
+	for _, key := range ctrl.sortedRouteKeys(app.Name) {
+		view.Routes = append(view.Routes, *ctrl.routesByName[key])
+	}
+	return view
+}
+
+func (ctrl *Controller) listApplications(w http.ResponseWriter, r *http.Request, _ []string) {
+	ctrl.mu.Lock()
+	defer ctrl.mu.Unlock()
+	names := make([]string, 0, len(ctrl.applications))
+	for name := range ctrl.applications {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	start, end := ctrl.page(r.URL.Query(), len(names))
+	response := client.ApplicationListResponse{Applications: []client.ApplicationInfo{}}
+	for _, name := range names[start:end] {
+		response.Applications = append(response.Applications, ctrl.applicationView(ctrl.applications[name]))
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+func (ctrl *Controller) getApplication(w http.ResponseWriter, r *http.Request, params []string) {
+	ctrl.mu.Lock()
+	defer ctrl.mu.Unlock()
+	app, exists := ctrl.applications[params[0]]
+	if !exists {
+		writeError(w, http.StatusNotFound, "NotFoundError", fmt.Sprintf("Invalid application name '%s'", params[0]))
+		return
+	}
+	writeJSON(w, http.StatusOK, ctrl.applicationView(app))
+}
+
+func (ctrl *Controller) createApplicationFromYAML(w http.ResponseWriter, r *http.Request, _ []string) {
+	file := applicationFile{}
+	if !readYAMLFile(w, r, "application", &file) {
+		return
+	}
+	ctrl.mu.Lock()
+	defer ctrl.mu.Unlock()
+	name := file.Metadata.Name
+	if name == "" {
+		writeError(w, http.StatusBadRequest, "ValidationError", "Application name is required")
+		return
+	}
+	if _, exists := ctrl.applications[name]; exists {
+		writeError(w, http.StatusBadRequest, "DuplicatePropertyError", fmt.Sprintf("Duplicate name '%s'", name))
+		return
+	}
+	app := &client.ApplicationInfo{Name: name, ID: ctrl.newID(), IsActivated: true}
+	if err := ctrl.deployApplication(app, &file.Spec); err != nil {
+		writeError(w, http.StatusBadRequest, "ValidationError", err.Error())
+		return
+	}
+	writeJSON(w, http.StatusCreated, client.FlowCreateResponse{ID: app.ID, Name: app.Name})
+}
+
+func (ctrl *Controller) updateApplicationFromYAML(w http.ResponseWriter, r *http.Request, params []string) {
+	file := applicationFile{}
+	if !readYAMLFile(w, r, "application", &file) {
+		return
+	}
+	ctrl.mu.Lock()
+	defer ctrl.mu.Unlock()
+	app, exists := ctrl.applications[params[0]]
+	if !exists {
+		writeError(w, http.StatusNotFound, "NotFoundError", fmt.Sprintf("Invalid application name '%s'", params[0]))
+		return
+	}
+	ctrl.removeApplicationContent(app.Name)
+	if err := ctrl.deployApplication(app, &file.Spec); err != nil {
+		writeError(w, http.StatusBadRequest, "ValidationError", err.Error())
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// deployApplication stores the application with its microservices and routes
+func (ctrl *Controller) deployApplication(app *client.ApplicationInfo, spec *apps.Application) error {
+	msvcs := make([]*client.MicroserviceInfo, 0, len(spec.Microservices))
+	for idx := range spec.Microservices {
+		msvc, err := ctrl.newMicroservice(app.Name, &spec.Microservices[idx])
+		if err != nil {
+			return err
+		}
+		msvcs = append(msvcs, msvc)
+	}
+	ctrl.applications[app.Name] = app
+	for _, msvc := range msvcs {
+		ctrl.microservices[msvc.UUID] = msvc
+	}
+	for _, route := range spec.Routes {
+		ctrl.routesByName[routeKey(app.Name, route.Name)] = &client.Route{
+			Name:        route.Name,
+			Application: app.Name,
+			From:        route.From,
+			To:          route.To,
+		}
+	}
+	return nil
+}
+
+func (ctrl *Controller) removeApplicationContent(appName string) {
+	for uuid, msvc := range ctrl.microservices {
+		if msvc.Application == appName {
+			delete(ctrl.microservices, uuid)
+		}
+	}
+	for key, route := range ctrl.routesByName {
+		if route.Application == appName {
+			delete(ctrl.routesByName, key)
+		}
+	}
+}
+
+func (ctrl *Controller) patchApplication(w http.ResponseWriter, r *http.Request, params []string) {
+	request := client.ApplicationPatchRequest{}
+	if !readJSON(w, r, &request) {
+		return
+	}
+	ctrl.mu.Lock()
+	defer ctrl.mu.Unlock()
+	app, exists := ctrl.applications[params[0]]
+	if !exists {
+		writeError(w, http.StatusNotFound, "NotFoundError", fmt.Sprintf("Invalid application name '%s'", params[0]))
+		return
+	}
+	if request.Description != nil {
+		app.Description = *request.Description
+	}
+	if request.IsActivated != nil {
+		app.IsActivated = *request.IsActivated
+	}
+	if request.IsSystem != nil {
+		app.IsSystem = *request.IsSystem
+	}
+	if request.Name != nil && *request.Name != app.Name {
+		oldName := app.Name
+		app.Name = *request.Name
+		delete(ctrl.applications, oldName)
+		ctrl.applications[app.Name] = app
+		for _, msvc := range ctrl.microservices {
+			if msvc.Application == oldName {
+				msvc.Application = app.Name
+			}
+		}
+		for key, route := range ctrl.routesByName {
+			if route.Application == oldName {
+				delete(ctrl.routesByName, key)
+				route.Application = app.Name
+				ctrl.routesByName[routeKey(app.Name, route.Name)] = route
+			}
+		}
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (ctrl *Controller) deleteApplication(w http.ResponseWriter, r *http.Request, params []string) {
+	ctrl.mu.Lock()
+	defer ctrl.mu.Unlock()
+	if _, exists := ctrl.applications[params[0]]; !exists {
+		writeError(w, http.StatusNotFound, "NotFoundError", fmt.Sprintf("Invalid application name '%s'", params[0]))
+		return
+	}
+	ctrl.removeApplicationContent(params[0])
+	delete(ctrl.applications, params[0])
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// newMicroservice validates a microservice spec against stored Agents
+func (ctrl *Controller) newMicroservice(appName string, spec *apps.Microservice) (*client.MicroserviceInfo, error) {
+	if spec.Name == "" {
+		return nil, fmt.Errorf("microservice name is required")
+	}
+	agent := ctrl.findAgentByName(spec.Agent.Name)
+	if agent == nil {
+		return nil, fmt.Errorf("invalid agent name '%s' for microservice %s", spec.Agent.Name, spec.Name)
+	}
+	msvc := microserviceFromSpec(spec)
+	msvc.UUID = randomHex(32)
+	msvc.Application = appName
+	msvc.AgentUUID = agent.UUID
+	msvc.Status = client.MicroserviceStatusInfo{Status: QueuedStatus}
+	if app, exists := ctrl.applications[appName]; exists {
+		msvc.ApplicationID = app.ID
+	}
+	return msvc, nil
+}
+
+// microserviceFromSpec converts the parts of a microservice spec which do not depend on the Controller state
+func microserviceFromSpec(spec *apps.Microservice) *client.MicroserviceInfo {
+	msvc := &client.MicroserviceInfo{
+		Name:     spec.Name,
+		Config:   "{}",
+		Commands: spec.Container.Commands,
+	}
+	if spec.Images != nil {
+		msvc.CatalogItemID = spec.Images.CatalogID
+		msvc.RegistryID = registryID(spec.Images.Registry)
+		if spec.Images.X86 != "" {
+			msvc.Images = append(msvc.Images, client.CatalogImage{ContainerImage: spec.Images.X86, AgentTypeID: 1})
+		}
+		if spec.Images.ARM != "" {
+			msvc.Images = append(msvc.Images, client.CatalogImage{ContainerImage: spec.Images.ARM, AgentTypeID: 2})
+		}
+	}
+	if config, err := json.Marshal(stringKeys(spec.Config)); err == nil && spec.Config != nil {
+		msvc.Config = string(config)
+	}
+	for _, port := range spec.Container.Ports {
+		msvc.Ports = append(msvc.Ports, client.MicroservicePortMappingInfo{Internal: port.Internal, External: port.External, Protocol: port.Protocol})
+	}
+	if spec.Container.Env != nil {
+		for _, env := range *spec.Container.Env {
+			msvc.Env = append(msvc.Env, client.MicroserviceEnvironmentInfo{Key: env.Key, Value: env.Value})
+		}
+	}
+	if spec.Container.Volumes != nil {
+		for _, volume := range *spec.Container.Volumes {
+			msvc.Volumes = append(msvc.Volumes, client.MicroserviceVolumeMappingInfo{
+				HostDestination:      volume.HostDestination,
+				ContainerDestination: volume.ContainerDestination,
+				AccessMode:           volume.AccessMode,
+				Type:                 volume.Type,
+			})
+		}
+	}
+	return msvc
+}
+
+// registryID resolves the registry of a microservice spec, given by ID or as remote or local
+func registryID(registry string) int {
+	switch registry {
+	case "remote":
+		return 1
+	case "local":
+		return 2
+	}
+	id, _ := strconv.Atoi(registry)
+	return id
+}
+
+// stringKeys converts YAML maps to maps which can be encoded as JSON
+func stringKeys(value interface{}) interface{} {
+	switch typed := value.(type) {
+	case map[interface{}]interface{}:
+		converted := make(map[string]interface{}, len(typed))
+		for key, val := range typed {
+			converted[fmt.Sprint(key)] = stringKeys(val)
+		}
+		return converted
+	case apps.NestedMap:
+		converted := make(map[string]interface{}, len(typed))
+		for key, val := range typed {
+			converted[key] = stringKeys(val)
+		}
+		return converted
+	case map[string]interface{}:
+		converted := make(map[string]interface{}, len(typed))
+		for key, val := range typed {
+			converted[key] = stringKeys(val)
+		}
+		return converted
+	case []interface{}:
+		converted := make([]interface{}, len(typed))
+		for idx, val := range typed {
+			converted[idx] = stringKeys(val)
+		}
+		return converted
+	default:
+		return value
+	}
+}
+
+func (ctrl *Controller) sortedMicroserviceUUIDs(appName string) []string {
+	uuids := []string{}
+	for uuid, msvc := range ctrl.microservices {
+		if appName == "" || msvc.Application == appName {
+			uuids = append(uuids, uuid)
+		}
+	}
+	sort.Slice(uuids, func(i, j int) bool {
+		left, right := ctrl.microservices[uuids[i]], ctrl.microservices[uuids[j]]
+		if left.Application != right.Application {
+			return left.Application < right.Application
+		}
+		return left.Name < right.Name
+	})
+	return uuids
+}
+
+func (ctrl *Controller) listMicroservices(w http.ResponseWriter, r *http.Request, _ []string) {
+	appName := r.URL.Query().Get("application")
+	ctrl.mu.Lock()
+	defer ctrl.mu.Unlock()
+	if appName != "" {
+		if _, exists := ctrl.applications[appName]; !exists {
+			writeError(w, http.StatusNotFound, "NotFoundError", fmt.Sprintf("Invalid application name '%s'", appName))
+			return
+		}
+	}
+	uuids := ctrl.sortedMicroserviceUUIDs(appName)
+	start, end := ctrl.page(r.URL.Query(), len(uuids))
+	response := client.MicroserviceListResponse{Microservices: []client.MicroserviceInfo{}}
+	for _, uuid := range uuids[start:end] {
+		response.Microservices = append(response.Microservices, *ctrl.microservices[uuid])
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"microservices": response.Microservices})
+}
+
+func (ctrl *Controller) getMicroservice(w http.ResponseWriter, r *http.Request, params []string) {
+	ctrl.mu.Lock()
+	defer ctrl.mu.Unlock()
+	msvc, exists := ctrl.microservices[params[0]]
+	if !exists {
+		writeError(w, http.StatusNotFound, "NotFoundError", fmt.Sprintf("Invalid microservice UUID '%s'", params[0]))
+		return
+	}
+	writeJSON(w, http.StatusOK, msvc)
+}
+
+func (ctrl *Controller) createMicroserviceFromYAML(w http.ResponseWriter, r *http.Request, _ []string) {
+	file := microserviceFile{}
+	if !readYAMLFile(w, r, "microservice", &file) {
+		return
+	}
+	appName, name := splitFQName(file.Metadata.Name)
+	if file.Spec.Application != nil && *file.Spec.Application != "" {
+		appName = *file.Spec.Application
+	}
+	file.Spec.Name = name
+	ctrl.mu.Lock()
+	defer ctrl.mu.Unlock()
+	if _, exists := ctrl.applications[appName]; !exists {
+		writeError(w, http.StatusNotFound, "NotFoundError", fmt.Sprintf("Invalid application name '%s'", appName))
+		return
+	}
+	for _, msvc := range ctrl.microservices {
+		if msvc.Application == appName && msvc.Name == name {
+			writeError(w, http.StatusBadRequest, "DuplicatePropertyError", fmt.Sprintf("Duplicate name '%s'", name))
+			return
+		}
+	}
+	msvc, err := ctrl.newMicroservice(appName, &file.Spec)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "ValidationError", err.Error())
+		return
+	}
+	ctrl.microservices[msvc.UUID] = msvc
+	writeJSON(w, http.StatusCreated, client.MicroserviceCreateResponse{UUID: msvc.UUID})
+}
+
+func (ctrl *Controller) updateMicroserviceFromYAML(w http.ResponseWriter, r *http.Request, params []string) {
+	file := microserviceFile{}
+	if !readYAMLFile(w, r, "microservice", &file) {
+		return
+	}
+	ctrl.mu.Lock()
+	defer ctrl.mu.Unlock()
+	existing, exists := ctrl.microservices[params[0]]
+	if !exists {
+		writeError(w, http.StatusNotFound, "NotFoundError", fmt.Sprintf("Invalid microservice UUID '%s'", params[0]))
+		return
+	}
+	if file.Spec.Name == "" {
+		file.Spec.Name = existing.Name
+	}
+	msvc, err := ctrl.newMicroservice(existing.Application, &file.Spec)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "ValidationError", err.Error())
+		return
+	}
+	msvc.UUID = existing.UUID
+	msvc.Status = existing.Status
+	ctrl.microservices[msvc.UUID] = msvc
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (ctrl *Controller) deleteMicroservice(w http.ResponseWriter, r *http.Request, params []string) {
+	ctrl.mu.Lock()
+	defer ctrl.mu.Unlock()
+	if _, exists := ctrl.microservices[params[0]]; !exists {
+		writeError(w, http.StatusNotFound, "NotFoundError", fmt.Sprintf("Invalid microservice UUID '%s'", params[0]))
+		return
+	}
+	delete(ctrl.microservices, params[0])
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (ctrl *Controller) listPortMappings(w http.ResponseWriter, r *http.Request, params []string) {
+	ctrl.mu.Lock()
+	defer ctrl.mu.Unlock()
+	msvc, exists := ctrl.microservices[params[0]]
+	if !exists {
+		writeError(w, http.StatusNotFound, "NotFoundError", fmt.Sprintf("Invalid microservice UUID '%s'", params[0]))
+		return
+	}
+	ports := append([]client.MicroservicePortMappingInfo{}, msvc.Ports...)
+	writeJSON(w, http.StatusOK, client.MicroservicePortMappingListResponse{PortMappings: ports})
+}
+
+func (ctrl *Controller) createPortMapping(w http.ResponseWriter, r *http.Request, params []string) {
+	port := client.MicroservicePortMappingInfo{}
+	if !readJSON(w, r, &port) {
+		return
+	}
+	ctrl.mu.Lock()
+	defer ctrl.mu.Unlock()
+	msvc, exists := ctrl.microservices[params[0]]
+	if !exists {
+		writeError(w, http.StatusNotFound, "NotFoundError", fmt.Sprintf("Invalid microservice UUID '%s'", params[0]))
+		return
+	}
+	msvc.Ports = append(msvc.Ports, port)
+	w.WriteHeader(http.StatusCreated)
+}
+
+func (ctrl *Controller) deletePortMapping(w http.ResponseWriter, r *http.Request, params []string) {
+	internal, err := strconv.ParseInt(params[1], 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "ValidationError", err.Error())
+		return
+	}
+	ctrl.mu.Lock()
+	defer ctrl.mu.Unlock()
+	msvc, exists := ctrl.microservices[params[0]]
+	if !exists {
+		writeError(w, http.StatusNotFound, "NotFoundError", fmt.Sprintf("Invalid microservice UUID '%s'", params[0]))
+		return
+	}
+	for idx := range msvc.Ports {
+		if msvc.Ports[idx].Internal == internal {
+			msvc.Ports = append(msvc.Ports[:idx], msvc.Ports[idx+1:]...)
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+	}
+	writeError(w, http.StatusNotFound, "NotFoundError", fmt.Sprintf("Invalid port mapping %d", internal))
+}
+
+func (ctrl *Controller) listPublicPorts(w http.ResponseWriter, r *http.Request, _ []string) {
+	ctrl.mu.Lock()
+	defer ctrl.mu.Unlock()
+	response := []client.MicroservicePublicPort{}
+	for _, uuid := range ctrl.sortedMicroserviceUUIDs("") {
+		for _, port := range ctrl.microservices[uuid].Ports {
+			if port.Public == nil {
+				continue
+			}
+			response = append(response, client.MicroservicePublicPort{
+				MicroserviceUUID: uuid,
+				PublicPort:       client.PublicPort{Protocol: port.Public.Protocol, Port: int(port.External)},
+			})
+		}
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+func (ctrl *Controller) createMicroserviceRoute(w http.ResponseWriter, r *http.Request, params []string) {
+	ctrl.mu.Lock()
+	defer ctrl.mu.Unlock()
+	from, fromExists := ctrl.microservices[params[0]]
+	to, toExists := ctrl.microservices[params[1]]
+	if !fromExists || !toExists {
+		writeError(w, http.StatusNotFound, "NotFoundError", "Invalid microservice UUID")
+		return
+	}
+	name := fmt.Sprintf("%s-%s", from.Name, to.Name)
+	ctrl.routesByName[routeKey(from.Application, name)] = &client.Route{
+		Name:                   name,
+		Application:            from.Application,
+		SourceMicroserviceUUID: from.UUID,
+		DestMicroserviceUUID:   to.UUID,
+		From:                   from.Name,
+		To:                     to.Name,
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (ctrl *Controller) deleteMicroserviceRoute(w http.ResponseWriter, r *http.Request, params []string) {
+	ctrl.mu.Lock()
+	defer ctrl.mu.Unlock()
+	for key, route := range ctrl.routesByName {
+		if route.SourceMicroserviceUUID == params[0] && route.DestMicroserviceUUID == params[1] {
+			delete(ctrl.routesByName, key)
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+	}
+	writeError(w, http.StatusNotFound, "NotFoundError", "Invalid route")
+}
+
+func routeKey(appName, name string) string {
+	return appName + "/" + name
+}
+
+func (ctrl *Controller) sortedRouteKeys(appName string) []string {
+	keys := []string{}
+	for key, route := range ctrl.routesByName {
+		if appName == "" || route.Application == appName {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (ctrl *Controller) listRoutes(w http.ResponseWriter, r *http.Request, _ []string) {
+	ctrl.mu.Lock()
+	defer ctrl.mu.Unlock()
+	response := client.RouteListResponse{Routes: []client.Route{}}
+	for _, key := range ctrl.sortedRouteKeys("") {
+		response.Routes = append(response.Routes, *ctrl.routesByName[key])
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+func (ctrl *Controller) getRoute(w http.ResponseWriter, r *http.Request, params []string) {
+	ctrl.mu.Lock()
+	defer ctrl.mu.Unlock()
+	route, exists := ctrl.routesByName[routeKey(params[0], params[1])]
+	if !exists {
+		writeError(w, http.StatusNotFound, "NotFoundError", fmt.Sprintf("Invalid route '%s'", routeKey(params[0], params[1])))
+		return
+	}
+	writeJSON(w, http.StatusOK, route)
+}
+
+func (ctrl *Controller) createRoute(w http.ResponseWriter, r *http.Request, _ []string) {
+	route := client.Route{}
+	if !readJSON(w, r, &route) {
+		return
+	}
+	ctrl.mu.Lock()
+	defer ctrl.mu.Unlock()
+	if _, exists := ctrl.applications[route.Application]; !exists {
+		writeError(w, http.StatusNotFound, "NotFoundError", fmt.Sprintf("Invalid application name '%s'", route.Application))
+		return
+	}
+	key := routeKey(route.Application, route.Name)
+	if _, exists := ctrl.routesByName[key]; exists {
+		writeError(w, http.StatusBadRequest, "DuplicatePropertyError", fmt.Sprintf("Duplicate name '%s'", route.Name))
+		return
+	}
+	ctrl.routesByName[key] = &route
+	w.WriteHeader(http.StatusCreated)
+}
+
+func (ctrl *Controller) patchRoute(w http.ResponseWriter, r *http.Request, params []string) {
+	patch := client.Route{}
+	if !readJSON(w, r, &patch) {
+		return
+	}
+	ctrl.mu.Lock()
+	defer ctrl.mu.Unlock()
+	key := routeKey(params[0], params[1])
+	route, exists := ctrl.routesByName[key]
+	if !exists {
+		writeError(w, http.StatusNotFound, "NotFoundError", fmt.Sprintf("Invalid route '%s'", key))
+		return
+	}
+	if patch.From != "" {
+		route.From = patch.From
+	}
+	if patch.To != "" {
+		route.To = patch.To
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (ctrl *Controller) deleteRoute(w http.ResponseWriter, r *http.Request, params []string) {
+	ctrl.mu.Lock()
+	defer ctrl.mu.Unlock()
+	key := routeKey(params[0], params[1])
+	if _, exists := ctrl.routesByName[key]; !exists {
+		writeError(w, http.StatusNotFound, "NotFoundError", fmt.Sprintf("Invalid route '%s'", key))
+		return
+	}
+	delete(ctrl.routesByName, key)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (ctrl *Controller) listTemplates(w http.ResponseWriter, r *http.Request, _ []string) {
+	ctrl.mu.Lock()
+	defer ctrl.mu.Unlock()
+	names := make([]string, 0, len(ctrl.templates))
+	for name := range ctrl.templates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	response := client.ApplicationTemplateListResponse{ApplicationTemplates: []client.ApplicationTemplate{}}
+	for _, name := range names {
+		response.ApplicationTemplates = append(response.ApplicationTemplates, *ctrl.templates[name])
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+func (ctrl *Controller) getTemplate(w http.ResponseWriter, r *http.Request, params []string) {
+	ctrl.mu.Lock()
+	defer ctrl.mu.Unlock()
+	template, exists := ctrl.templates[params[0]]
+	if !exists {
+		writeError(w, http.StatusNotFound, "NotFoundError", fmt.Sprintf("Invalid application template name '%s'", params[0]))
+		return
+	}
+	writeJSON(w, http.StatusOK, template)
+}
+
+func (ctrl *Controller) createTemplateFromYAML(w http.ResponseWriter, r *http.Request, _ []string) {
+	file := templateFile{}
+	if !readYAMLFile(w, r, "template", &file) {
+		return
+	}
+	ctrl.mu.Lock()
+	defer ctrl.mu.Unlock()
+	name := file.Metadata.Name
+	if _, exists := ctrl.templates[name]; exists {
+		writeError(w, http.StatusBadRequest, "DuplicatePropertyError", fmt.Sprintf("Duplicate name '%s'", name))
+		return
+	}
+	ctrl.templates[name] = newTemplate(name, &file.Spec)
+	writeJSON(w, http.StatusCreated, client.ApplicationTemplateCreateResponse{Name: name, ID: ctrl.newID()})
+}
+
+func (ctrl *Controller) updateTemplateFromYAML(w http.ResponseWriter, r *http.Request, params []string) {
+	file := templateFile{}
+	if !readYAMLFile(w, r, "template", &file) {
+		return
+	}
+	ctrl.mu.Lock()
+	defer ctrl.mu.Unlock()
+	ctrl.templates[params[0]] = newTemplate(params[0], &file.Spec)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (ctrl *Controller) patchTemplate(w http.ResponseWriter, r *http.Request, params []string) {
+	request := client.ApplicationTemplateMetadataUpdateRequest{}
+	if !readJSON(w, r, &request) {
+		return
+	}
+	ctrl.mu.Lock()
+	defer ctrl.mu.Unlock()
+	template, exists := ctrl.templates[params[0]]
+	if !exists {
+		writeError(w, http.StatusNotFound, "NotFoundError", fmt.Sprintf("Invalid application template name '%s'", params[0]))
+		return
+	}
+	if request.Description != nil {
+		template.Description = *request.Description
+	}
+	if request.Name != nil {
+		delete(ctrl.templates, template.Name)
+		template.Name = *request.Name
+		ctrl.templates[template.Name] = template
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (ctrl *Controller) deleteTemplate(w http.ResponseWriter, r *http.Request, params []string) {
+	ctrl.mu.Lock()
+	defer ctrl.mu.Unlock()
+	if _, exists := ctrl.templates[params[0]]; !exists {
+		writeError(w, http.StatusNotFound, "NotFoundError", fmt.Sprintf("Invalid application template name '%s'", params[0]))
+		return
+	}
+	delete(ctrl.templates, params[0])
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// templateMicroservice is a microservice of an application template as returned by the Controller
+type templateMicroservice struct {
+	client.MicroserviceInfo
+	AgentName string `json:"agentName"`
+}
+
+func newTemplate(name string, spec *apps.ApplicationTemplate) *client.ApplicationTemplate {
+	template := &client.ApplicationTemplate{
+		Name:        name,
+		Description: spec.Description,
+		Application: &client.ApplicationTemplateInfo{Microservices: []interface{}{}, Routes: []interface{}{}},
+	}
+	for _, variable := range spec.Variables {
+		template.Variables = append(template.Variables, client.TemplateVariable{
+			Key:          variable.Key,
+			Description:  variable.Description,
+			DefaultValue: stringKeys(variable.DefaultValue),
+			Value:        stringKeys(variable.Value),
+		})
+	}
+	if spec.Application != nil {
+		// Like the Controller, keep the agent name, which may be a template variable
+		for idx := range spec.Application.Microservices {
+			msvc := &spec.Application.Microservices[idx]
+			template.Application.Microservices = append(template.Application.Microservices, templateMicroservice{
+				MicroserviceInfo: *microserviceFromSpec(msvc),
+				AgentName:        msvc.Agent.Name,
+			})
+		}
+		for _, route := range spec.Application.Routes {
+			template.Application.Routes = append(template.Application.Routes, map[string]interface{}{"name": route.Name, "from": route.From, "to": route.To})
+		}
+	}
+	return template
+}
+
+func splitFQName(fqName string) (appName, name string) {
+	if idx := strings.Index(fqName, "/"); idx >= 0 {
+		return fqName[:idx], fqName[idx+1:]
+	}
+	return "", fqName
+}
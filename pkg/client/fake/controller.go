@@ -0,0 +1,405 @@
+/*
+ *  *******************************************************************************
+ *  * Copyright (c) 2019 Edgeworx, Inc.
+ *  *
+ *  * This program and the accompanying materials are made available under the
+ *  * terms of the Eclipse Public License v. 2.0 which is available at
+ *  * http://www.eclipse.org/legal/epl-2.0
+ *  *
+ *  * SPDX-License-Identifier: EPL-2.0
+ *  *******************************************************************************
+ *
+ */
+
+// Package fake provides an in-memory ioFog Controller serving the /api/v3 REST routes used by the client package.
+// It is meant for unit tests of code built on client.Client which must run without a real Controller.
+package fake
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/eclipse-iofog/iofog-go-sdk/v3/pkg/client"
+)
+
+// Capabilities probed by the client through HEAD /capabilities/<name>
+const (
+	EdgeResourcesCapability        = "edgeResources"
+	ApplicationTemplatesCapability = "applicationTemplates"
+	// ListPaginationCapability pages list responses with the limit and offset query parameters, disabled by default
+	ListPaginationCapability = "listPagination"
+)
+
+type handlerFunc func(w http.ResponseWriter, r *http.Request, params []string)
+
+type route struct {
+	method   string
+	segments []string
+	handler  handlerFunc
+	public   bool
+}
+
+// Controller is an in-memory ioFog Controller listening on a local httptest server
+type Controller struct {
+	server *httptest.Server
+	routes []route
+
+	mu                sync.Mutex
+	version           string
+	capabilities      map[string]bool
+	users             map[string]*client.User
+	activationCodes   map[string]string
+	tokens            map[string]string
+	agents            map[string]*client.AgentInfo
+	provisionKeys     map[string]client.GetAgentProvisionKeyResponse
+	agentCommands     map[string][]string
+	applications      map[string]*client.ApplicationInfo
+	microservices     map[string]*client.MicroserviceInfo
+	routesByName      map[string]*client.Route
+	templates         map[string]*client.ApplicationTemplate
+	edgeResources     map[string]*client.EdgeResourceMetadata
+	edgeResourceLinks map[string]map[string]bool
+	registries        map[int]*client.RegistryInfo
+	catalog           map[int]*client.CatalogItemInfo
+	flows             map[int]*client.FlowInfo
+	router            client.Router
+	config            map[string]string
+	requests          map[string]int
+	nextID            int
+	interceptor       func(w http.ResponseWriter, r *http.Request) bool
+}
+
+// New starts a fake Controller. Call Close once done with it.
+func New() *Controller {
+	ctrl := &Controller{
+		version: "3.0.0",
+		capabilities: map[string]bool{
+			EdgeResourcesCapability:        true,
+			ApplicationTemplatesCapability: true,
+			ListPaginationCapability:       false,
+		},
+		users:             make(map[string]*client.User),
+		activationCodes:   make(map[string]string),
+		tokens:            make(map[string]string),
+		agents:            make(map[string]*client.AgentInfo),
+		provisionKeys:     make(map[string]client.GetAgentProvisionKeyResponse),
+		agentCommands:     make(map[string][]string),
+		applications:      make(map[string]*client.ApplicationInfo),
+		microservices:     make(map[string]*client.MicroserviceInfo),
+		routesByName:      make(map[string]*client.Route),
+		templates:         make(map[string]*client.ApplicationTemplate),
+		edgeResources:     make(map[string]*client.EdgeResourceMetadata),
+		edgeResourceLinks: make(map[string]map[string]bool),
+		registries: map[int]*client.RegistryInfo{
+			1: {ID: 1, URL: "registry.hub.docker.com", IsPublic: true},
+			2: {ID: 2, URL: "from_cache", IsPublic: true},
+		},
+		// Like the registries, a few catalog items are built into every Controller
+		catalog: map[int]*client.CatalogItemInfo{
+			1: {ID: 1, Name: "Router", Category: "SYSTEM", RegistryID: 1, Images: []client.CatalogImage{
+				{ContainerImage: "ghcr.io/eclipse-iofog/router:3", AgentTypeID: 1},
+				{ContainerImage: "ghcr.io/eclipse-iofog/router:3", AgentTypeID: 2},
+			}},
+			2: {ID: 2, Name: "Diagnostics", Category: "UTILITIES", RegistryID: 1, Images: []client.CatalogImage{
+				{ContainerImage: "iofog/diagnostics", AgentTypeID: 1},
+				{ContainerImage: "iofog/diagnostics-arm", AgentTypeID: 2},
+			}},
+		},
+		flows:    make(map[int]*client.FlowInfo),
+		config:   make(map[string]string),
+		requests: make(map[string]int),
+		nextID:   100,
+	}
+	ctrl.registerRoutes()
+	ctrl.server = httptest.NewServer(http.HandlerFunc(ctrl.serveHTTP))
+	return ctrl
+}
+
+// Close shuts the server down
+func (ctrl *Controller) Close() {
+	ctrl.server.Close()
+}
+
+// URL returns the base URL of the REST API, ready for client.Options
+func (ctrl *Controller) URL() *url.URL {
+	baseURL, _ := url.Parse(ctrl.server.URL + "/api/v3")
+	return baseURL
+}
+
+// AddUser registers a user able to log in
+func (ctrl *Controller) AddUser(email, password string) {
+	ctrl.mu.Lock()
+	defer ctrl.mu.Unlock()
+	ctrl.users[email] = &client.User{Email: email, Password: password}
+}
+
+// Credentials of the user created by faketest.NewLoggedIn
+const (
+	UserEmail    = "user@domain.com"
+	UserPassword = "secret"
+)
+
+// IssueToken returns a valid access token for an existing user, as if the user had logged in
+func (ctrl *Controller) IssueToken(email string) (string, error) {
+	ctrl.mu.Lock()
+	defer ctrl.mu.Unlock()
+	if _, exists := ctrl.users[email]; !exists {
+		return "", fmt.Errorf("unknown user %s", email)
+	}
+	return ctrl.issueToken(email), nil
+}
+
+// RevokeToken invalidates one access token, e.g. one returned by IssueToken
+func (ctrl *Controller) RevokeToken(token string) {
+	ctrl.mu.Lock()
+	defer ctrl.mu.Unlock()
+	delete(ctrl.tokens, token)
+}
+
+// ExpireTokens invalidates every access token issued so far
+func (ctrl *Controller) ExpireTokens() {
+	ctrl.mu.Lock()
+	defer ctrl.mu.Unlock()
+	for token := range ctrl.tokens {
+		delete(ctrl.tokens, token)
+	}
+}
+
+// SetVersion sets the Controller version reported by GET /status
+func (ctrl *Controller) SetVersion(version string) {
+	ctrl.mu.Lock()
+	defer ctrl.mu.Unlock()
+	ctrl.version = version
+}
+
+// SetCapability enables or disables a capability probed by the client
+func (ctrl *Controller) SetCapability(capability string, enabled bool) {
+	ctrl.mu.Lock()
+	defer ctrl.mu.Unlock()
+	ctrl.capabilities[capability] = enabled
+}
+
+// Intercept installs a function called before every request.
+// Returning true means the interceptor wrote the response and the request is not served any further.
+func (ctrl *Controller) Intercept(interceptor func(w http.ResponseWriter, r *http.Request) bool) {
+	ctrl.mu.Lock()
+	defer ctrl.mu.Unlock()
+	ctrl.interceptor = interceptor
+}
+
+// RequestCount returns how many requests were received for the method and API path, e.g. ("GET", "/iofog-list")
+func (ctrl *Controller) RequestCount(method, path string) int {
+	ctrl.mu.Lock()
+	defer ctrl.mu.Unlock()
+	return ctrl.requests[method+" "+path]
+}
+
+func (ctrl *Controller) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	apiPath := strings.TrimPrefix(r.URL.Path, "/api/v3")
+	segments := strings.Split(strings.Trim(apiPath, "/"), "/")
+
+	ctrl.mu.Lock()
+	ctrl.requests[r.Method+" "+apiPath]++
+	interceptor := ctrl.interceptor
+	ctrl.mu.Unlock()
+	if interceptor != nil && interceptor(w, r) {
+		return
+	}
+
+	for idx := range ctrl.routes {
+		rte := &ctrl.routes[idx]
+		params, ok := rte.match(r.Method, segments)
+		if !ok {
+			continue
+		}
+		if !rte.public && !ctrl.isAuthorized(r) {
+			writeError(w, http.StatusUnauthorized, "AuthenticationError", "Invalid credentials")
+			return
+		}
+		rte.handler(w, r, params)
+		return
+	}
+	writeError(w, http.StatusNotFound, "NotFoundError", fmt.Sprintf("Cannot %s %s", r.Method, apiPath))
+}
+
+func (rte *route) match(method string, segments []string) (params []string, ok bool) {
+	if rte.method != method || len(rte.segments) != len(segments) {
+		return nil, false
+	}
+	for idx, segment := range rte.segments {
+		if strings.HasPrefix(segment, ":") {
+			params = append(params, segments[idx])
+			continue
+		}
+		if segment != segments[idx] {
+			return nil, false
+		}
+	}
+	return params, true
+}
+
+func (ctrl *Controller) handle(method, pattern string, handler handlerFunc) {
+	ctrl.routes = append(ctrl.routes, route{
+		method:   method,
+		segments: strings.Split(strings.Trim(pattern, "/"), "/"),
+		handler:  handler,
+	})
+}
+
+func (ctrl *Controller) handlePublic(method, pattern string, handler handlerFunc) {
+	ctrl.handle(method, pattern, handler)
+	ctrl.routes[len(ctrl.routes)-1].public = true
+}
+
+func (ctrl *Controller) registerRoutes() {
+	ctrl.handlePublic(http.MethodGet, "/status", ctrl.getStatus)
+	ctrl.handlePublic(http.MethodHead, "/capabilities/:name", ctrl.headCapability)
+	ctrl.handlePublic(http.MethodPost, "/user/signup", ctrl.signup)
+	ctrl.handlePublic(http.MethodPost, "/user/login", ctrl.login)
+	ctrl.handle(http.MethodPatch, "/user/password", ctrl.updatePassword)
+	ctrl.registerUserRoutes()
+	ctrl.registerAgentRoutes()
+	ctrl.registerApplicationRoutes()
+	ctrl.registerResourceRoutes()
+}
+
+func (ctrl *Controller) isAuthorized(r *http.Request) bool {
+	ctrl.mu.Lock()
+	defer ctrl.mu.Unlock()
+	_, exists := ctrl.tokens[r.Header.Get("Authorization")]
+	return exists
+}
+
+func (ctrl *Controller) issueToken(email string) string {
+	token := randomHex(32)
+	ctrl.tokens[token] = email
+	return token
+}
+
+func (ctrl *Controller) newID() int {
+	ctrl.nextID++
+	return ctrl.nextID
+}
+
+func (ctrl *Controller) getStatus(w http.ResponseWriter, r *http.Request, _ []string) {
+	ctrl.mu.Lock()
+	defer ctrl.mu.Unlock()
+	writeJSON(w, http.StatusOK, client.ControllerStatus{
+		Status:        "online",
+		UptimeSeconds: 1,
+		Versions:      client.ControllerVersions{Controller: ctrl.version, EcnViewer: ctrl.version},
+	})
+}
+
+func (ctrl *Controller) headCapability(w http.ResponseWriter, r *http.Request, params []string) {
+	ctrl.mu.Lock()
+	defer ctrl.mu.Unlock()
+	if !ctrl.capabilities[params[0]] {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (ctrl *Controller) signup(w http.ResponseWriter, r *http.Request, _ []string) {
+	user := client.User{}
+	if !readJSON(w, r, &user) {
+		return
+	}
+	if user.Email == "" || user.Password == "" {
+		writeError(w, http.StatusBadRequest, "ValidationError", "Email and password are required")
+		return
+	}
+	ctrl.mu.Lock()
+	defer ctrl.mu.Unlock()
+	if _, exists := ctrl.users[user.Email]; exists {
+		writeError(w, http.StatusBadRequest, "ValidationError", "Registration failed: There is already an account associated with your email address")
+		return
+	}
+	ctrl.users[user.Email] = &user
+	ctrl.activationCodes[user.Email] = randomHex(4)
+	writeJSON(w, http.StatusCreated, map[string]interface{}{"userId": ctrl.newID()})
+}
+
+func (ctrl *Controller) login(w http.ResponseWriter, r *http.Request, _ []string) {
+	request := client.LoginRequest{}
+	if !readJSON(w, r, &request) {
+		return
+	}
+	ctrl.mu.Lock()
+	defer ctrl.mu.Unlock()
+	user, exists := ctrl.users[request.Email]
+	if !exists || user.Password != request.Password {
+		writeError(w, http.StatusUnauthorized, "InvalidCredentialsError", "Invalid credentials")
+		return
+	}
+	writeJSON(w, http.StatusOK, client.LoginResponse{AccessToken: ctrl.issueToken(request.Email)})
+}
+
+func (ctrl *Controller) updatePassword(w http.ResponseWriter, r *http.Request, _ []string) {
+	request := client.UpdateUserPasswordRequest{}
+	if !readJSON(w, r, &request) {
+		return
+	}
+	ctrl.mu.Lock()
+	defer ctrl.mu.Unlock()
+	user := ctrl.users[ctrl.tokens[r.Header.Get("Authorization")]]
+	if user == nil || user.Password != request.OldPassword {
+		writeError(w, http.StatusBadRequest, "ValidationError", "Old password is incorrect")
+		return
+	}
+	user.Password = request.NewPassword
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func readJSON(w http.ResponseWriter, r *http.Request, target interface{}) bool {
+	if err := json.NewDecoder(r.Body).Decode(target); err != nil {
+		writeError(w, http.StatusBadRequest, "ValidationError", err.Error())
+		return false
+	}
+	return true
+}
+
+// page returns the bounds of the page of count items requested with limit and offset, all items unless pagination is enabled
+func (ctrl *Controller) page(query url.Values, count int) (start, end int) {
+	limit, err := strconv.Atoi(query.Get("limit"))
+	if !ctrl.capabilities[ListPaginationCapability] || err != nil || limit <= 0 {
+		return 0, count
+	}
+	offset, _ := strconv.Atoi(query.Get("offset"))
+	start, end = offset, offset+limit
+	if start < 0 {
+		start = 0
+	}
+	if start > count {
+		start = count
+	}
+	if end > count {
+		end = count
+	}
+	return start, end
+}
+
+func writeJSON(w http.ResponseWriter, code int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func writeError(w http.ResponseWriter, code int, name, message string) {
+	writeJSON(w, code, client.ControllerError{Name: name, Message: message})
+}
+
+func randomHex(size int) string {
+	buf := make([]byte, size/2)
+	_, _ = rand.Read(buf)
+	return hex.EncodeToString(buf)
+}
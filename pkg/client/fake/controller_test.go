@@ -0,0 +1,103 @@
+/*
+ *  *******************************************************************************
+ *  * Copyright (c) 2019 Edgeworx, Inc.
+ *  *
+ *  * This program and the accompanying materials are made available under the
+ *  * terms of the Eclipse Public License v. 2.0 which is available at
+ *  * http://www.eclipse.org/legal/epl-2.0
+ *  *
+ *  * SPDX-License-Identifier: EPL-2.0
+ *  *******************************************************************************
+ *
+ */
+
+package fake_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/eclipse-iofog/iofog-go-sdk/v3/pkg/client"
+	"github.com/eclipse-iofog/iofog-go-sdk/v3/pkg/client/fake"
+	"github.com/eclipse-iofog/iofog-go-sdk/v3/pkg/client/fake/faketest"
+)
+
+const applicationYAML = `apiVersion: iofog.org/v3
+kind: Application
+metadata:
+  name: func-app
+spec:
+  microservices:
+  - name: heart-rate
+    agent:
+      name: agent-1
+    images:
+      x86: edgeworx/healthcare-heart-rate:x86-v1
+      arm: edgeworx/healthcare-heart-rate:arm-v1
+    container:
+      ports:
+      - internal: 80
+        external: 5000
+    config:
+      test_mode: true
+      data_label: Anonymous Person
+  - name: heart-rate-ui
+    agent:
+      name: agent-1
+    images:
+      x86: edgeworx/healthcare-heart-rate-ui:x86
+  routes:
+  - name: monitor-to-ui
+    from: heart-rate
+    to: heart-rate-ui
+`
+
+func TestClientAgainstFakeController(t *testing.T) {
+	ctrl, clt := faketest.NewLoggedIn(t, client.Options{})
+
+	agent, err := clt.CreateAgent(&client.CreateAgentRequest{AgentUpdateRequest: client.AgentUpdateRequest{Name: "agent-1"}})
+	if err != nil {
+		t.Fatalf("CreateAgent failed: %v", err)
+	}
+	if _, err = clt.CreateAgent(&client.CreateAgentRequest{AgentUpdateRequest: client.AgentUpdateRequest{Name: "agent-1"}}); !errors.Is(err, client.ErrConflict) {
+		t.Errorf("Expected conflict on duplicate Agent, got %v", err)
+	}
+	if err = clt.RebootAgent(agent.UUID); err != nil {
+		t.Fatalf("RebootAgent failed: %v", err)
+	}
+	if commands := ctrl.AgentCommands(agent.UUID); len(commands) != 1 || commands[0] != fake.RebootCommand {
+		t.Errorf("Expected reboot command, got %v", commands)
+	}
+
+	app, err := clt.CreateApplicationFromYAML(strings.NewReader(applicationYAML))
+	if err != nil {
+		t.Fatalf("CreateApplicationFromYAML failed: %v", err)
+	}
+	if len(app.Microservices) != 2 || len(app.Routes) != 1 {
+		t.Fatalf("Expected 2 microservices and 1 route, got %d and %d", len(app.Microservices), len(app.Routes))
+	}
+	msvc, err := clt.GetMicroserviceByName("func-app", "heart-rate")
+	if err != nil {
+		t.Fatalf("GetMicroserviceByName failed: %v", err)
+	}
+	if msvc.AgentUUID != agent.UUID || msvc.Status.Status != fake.QueuedStatus {
+		t.Errorf("Unexpected microservice %+v", msvc)
+	}
+	if !strings.Contains(msvc.Config, `"test_mode":true`) {
+		t.Errorf("Unexpected microservice config %s", msvc.Config)
+	}
+
+	if _, err = clt.GetApplicationByName("missing"); !errors.Is(err, client.ErrNotFound) {
+		t.Errorf("Expected not found error, got %v", err)
+	}
+
+	// Expired tokens are renewed by logging in again
+	ctrl.ExpireTokens()
+	if _, err = clt.ListAgents(client.ListAgentsRequest{}); err != nil {
+		t.Errorf("ListAgents after token expiry failed: %v", err)
+	}
+	if count := ctrl.RequestCount("POST", "/user/login"); count != 2 {
+		t.Errorf("Expected 2 logins, got %d", count)
+	}
+}
@@ -0,0 +1,37 @@
+/*
+ *  *******************************************************************************
+ *  * Copyright (c) 2019 Edgeworx, Inc.
+ *  *
+ *  * This program and the accompanying materials are made available under the
+ *  * terms of the Eclipse Public License v. 2.0 which is available at
+ *  * http://www.eclipse.org/legal/epl-2.0
+ *  *
+ *  * SPDX-License-Identifier: EPL-2.0
+ *  *******************************************************************************
+ *
+ */
+
+// Package faketest sets up fake Controllers in tests, keeping the testing package out of the fake package
+package faketest
+
+import (
+	"testing"
+
+	"github.com/eclipse-iofog/iofog-go-sdk/v3/pkg/client"
+	"github.com/eclipse-iofog/iofog-go-sdk/v3/pkg/client/fake"
+)
+
+// NewLoggedIn starts a fake Controller with the fake.UserEmail user and returns a client logged in as that user.
+// opts.BaseURL is set to the fake Controller, which is closed at the end of the test
+func NewLoggedIn(t testing.TB, opts client.Options) (*fake.Controller, *client.Client) {
+	t.Helper()
+	ctrl := fake.New()
+	t.Cleanup(ctrl.Close)
+	ctrl.AddUser(fake.UserEmail, fake.UserPassword)
+	opts.BaseURL = ctrl.URL()
+	clt, err := client.NewAndLogin(opts, fake.UserEmail, fake.UserPassword)
+	if err != nil {
+		t.Fatalf("Login to the fake Controller failed: %v", err)
+	}
+	return ctrl, clt
+}
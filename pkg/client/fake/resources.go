@@ -0,0 +1,526 @@
+/*
+ *  *******************************************************************************
+ *  * Copyright (c) 2019 Edgeworx, Inc.
+ *  *
+ *  * This program and the accompanying materials are made available under the
+ *  * terms of the Eclipse Public License v. 2.0 which is available at
+ *  * http://www.eclipse.org/legal/epl-2.0
+ *  *
+ *  * SPDX-License-Identifier: EPL-2.0
+ *  *******************************************************************************
+ *
+ */
+
+package fake
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/eclipse-iofog/iofog-go-sdk/v3/pkg/client"
+)
+
+// Config returns the value stored for key through PUT /config
+func (ctrl *Controller) Config(key string) string {
+	ctrl.mu.Lock()
+	defer ctrl.mu.Unlock()
+	return ctrl.config[key]
+}
+
+// EdgeResourceLinks returns the UUIDs of the Agents linked to an Edge Resource
+func (ctrl *Controller) EdgeResourceLinks(name, version string) []string {
+	ctrl.mu.Lock()
+	defer ctrl.mu.Unlock()
+	uuids := []string{}
+	for uuid := range ctrl.edgeResourceLinks[edgeResourceKey(name, version)] {
+		uuids = append(uuids, uuid)
+	}
+	sort.Strings(uuids)
+	return uuids
+}
+
+func (ctrl *Controller) registerResourceRoutes() {
+	ctrl.handle(http.MethodGet, "/registries", ctrl.listRegistries)
+	ctrl.handle(http.MethodPost, "/registries", ctrl.createRegistry)
+	ctrl.handle(http.MethodPatch, "/registries/:id", ctrl.patchRegistry)
+	ctrl.handle(http.MethodDelete, "/registries/:id", ctrl.deleteRegistry)
+
+	ctrl.handle(http.MethodGet, "/catalog/microservices", ctrl.listCatalog)
+	ctrl.handle(http.MethodPost, "/catalog/microservices", ctrl.createCatalogItem)
+	ctrl.handle(http.MethodGet, "/catalog/microservices/:id", ctrl.getCatalogItem)
+	ctrl.handle(http.MethodPatch, "/catalog/microservices/:id", ctrl.patchCatalogItem)
+	ctrl.handle(http.MethodDelete, "/catalog/microservices/:id", ctrl.deleteCatalogItem)
+
+	ctrl.handle(http.MethodGet, "/flow", ctrl.listFlows)
+	ctrl.handle(http.MethodPost, "/flow", ctrl.createFlow)
+	ctrl.handle(http.MethodGet, "/flow/:id", ctrl.getFlow)
+	ctrl.handle(http.MethodPatch, "/flow/:id", ctrl.patchFlow)
+	ctrl.handle(http.MethodDelete, "/flow/:id", ctrl.deleteFlow)
+
+	ctrl.handle(http.MethodGet, "/edgeResources", ctrl.listEdgeResources)
+	ctrl.handle(http.MethodPost, "/edgeResource", ctrl.createEdgeResource)
+	ctrl.handle(http.MethodGet, "/edgeResource/:name/:version", ctrl.getEdgeResource)
+	ctrl.handle(http.MethodPut, "/edgeResource/:name/:version", ctrl.updateEdgeResource)
+	ctrl.handle(http.MethodDelete, "/edgeResource/:name/:version", ctrl.deleteEdgeResource)
+	ctrl.handle(http.MethodPost, "/edgeResource/:name/:version/link", ctrl.linkEdgeResource)
+	ctrl.handle(http.MethodDelete, "/edgeResource/:name/:version/link", ctrl.unlinkEdgeResource)
+
+	ctrl.handle(http.MethodGet, "/router", ctrl.getRouter)
+	ctrl.handle(http.MethodPut, "/router", ctrl.putRouter)
+	ctrl.handle(http.MethodPut, "/config", ctrl.putConfig)
+}
+
+// parseID reads a numeric path parameter
+func parseID(w http.ResponseWriter, param string) (int, bool) {
+	id, err := strconv.Atoi(param)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "ValidationError", fmt.Sprintf("Invalid id '%s'", param))
+		return 0, false
+	}
+	return id, true
+}
+
+func sortedIDs(length int, each func(add func(int))) []int {
+	ids := make([]int, 0, length)
+	each(func(id int) { ids = append(ids, id) })
+	sort.Ints(ids)
+	return ids
+}
+
+func (ctrl *Controller) listRegistries(w http.ResponseWriter, r *http.Request, _ []string) {
+	ctrl.mu.Lock()
+	defer ctrl.mu.Unlock()
+	ids := sortedIDs(len(ctrl.registries), func(add func(int)) {
+		for id := range ctrl.registries {
+			add(id)
+		}
+	})
+	response := client.RegistryListResponse{Registries: []client.RegistryInfo{}}
+	for _, id := range ids {
+		response.Registries = append(response.Registries, *ctrl.registries[id])
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+func (ctrl *Controller) createRegistry(w http.ResponseWriter, r *http.Request, _ []string) {
+	request := client.RegistryCreateRequest{}
+	if !readJSON(w, r, &request) {
+		return
+	}
+	ctrl.mu.Lock()
+	defer ctrl.mu.Unlock()
+	id := ctrl.newID()
+	ctrl.registries[id] = &client.RegistryInfo{
+		ID:           id,
+		URL:          request.URL,
+		IsPublic:     request.IsPublic,
+		Certificate:  request.Certificate,
+		RequiresCert: request.RequiresCert,
+		Username:     request.Username,
+		Email:        request.Email,
+	}
+	writeJSON(w, http.StatusCreated, client.RegistryCreateResponse{ID: id})
+}
+
+func (ctrl *Controller) patchRegistry(w http.ResponseWriter, r *http.Request, params []string) {
+	id, ok := parseID(w, params[0])
+	if !ok {
+		return
+	}
+	request := client.RegistryUpdateRequest{}
+	if !readJSON(w, r, &request) {
+		return
+	}
+	ctrl.mu.Lock()
+	defer ctrl.mu.Unlock()
+	registry, exists := ctrl.registries[id]
+	if !exists {
+		writeError(w, http.StatusNotFound, "NotFoundError", fmt.Sprintf("Invalid registry id '%d'", id))
+		return
+	}
+	if request.URL != nil {
+		registry.URL = *request.URL
+	}
+	if request.IsPublic != nil {
+		registry.IsPublic = *request.IsPublic
+	}
+	if request.Certificate != nil {
+		registry.Certificate = *request.Certificate
+	}
+	if request.RequiresCert != nil {
+		registry.RequiresCert = *request.RequiresCert
+	}
+	if request.Username != nil {
+		registry.Username = *request.Username
+	}
+	if request.Email != nil {
+		registry.Email = *request.Email
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (ctrl *Controller) deleteRegistry(w http.ResponseWriter, r *http.Request, params []string) {
+	id, ok := parseID(w, params[0])
+	if !ok {
+		return
+	}
+	ctrl.mu.Lock()
+	defer ctrl.mu.Unlock()
+	if _, exists := ctrl.registries[id]; !exists {
+		writeError(w, http.StatusNotFound, "NotFoundError", fmt.Sprintf("Invalid registry id '%d'", id))
+		return
+	}
+	delete(ctrl.registries, id)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (ctrl *Controller) listCatalog(w http.ResponseWriter, r *http.Request, _ []string) {
+	ctrl.mu.Lock()
+	defer ctrl.mu.Unlock()
+	ids := sortedIDs(len(ctrl.catalog), func(add func(int)) {
+		for id := range ctrl.catalog {
+			add(id)
+		}
+	})
+	response := client.CatalogListResponse{CatalogItems: []client.CatalogItemInfo{}}
+	for _, id := range ids {
+		response.CatalogItems = append(response.CatalogItems, *ctrl.catalog[id])
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+func (ctrl *Controller) getCatalogItem(w http.ResponseWriter, r *http.Request, params []string) {
+	id, ok := parseID(w, params[0])
+	if !ok {
+		return
+	}
+	ctrl.mu.Lock()
+	defer ctrl.mu.Unlock()
+	item, exists := ctrl.catalog[id]
+	if !exists {
+		writeError(w, http.StatusNotFound, "NotFoundError", fmt.Sprintf("Invalid catalog item id '%d'", id))
+		return
+	}
+	writeJSON(w, http.StatusOK, item)
+}
+
+func (ctrl *Controller) createCatalogItem(w http.ResponseWriter, r *http.Request, _ []string) {
+	request := client.CatalogItemCreateRequest{}
+	if !readJSON(w, r, &request) {
+		return
+	}
+	ctrl.mu.Lock()
+	defer ctrl.mu.Unlock()
+	for _, item := range ctrl.catalog {
+		if item.Name == request.Name {
+			writeError(w, http.StatusBadRequest, "DuplicatePropertyError", fmt.Sprintf("Duplicate name '%s'", request.Name))
+			return
+		}
+	}
+	if _, exists := ctrl.registries[request.RegistryID]; !exists {
+		writeError(w, http.StatusNotFound, "NotFoundError", fmt.Sprintf("Invalid registry id '%d'", request.RegistryID))
+		return
+	}
+	id := ctrl.newID()
+	ctrl.catalog[id] = &client.CatalogItemInfo{
+		ID:          id,
+		Name:        request.Name,
+		Description: request.Description,
+		Images:      request.Images,
+		RegistryID:  request.RegistryID,
+	}
+	writeJSON(w, http.StatusCreated, client.CatalogItemCreateResponse{ID: id})
+}
+
+func (ctrl *Controller) patchCatalogItem(w http.ResponseWriter, r *http.Request, params []string) {
+	id, ok := parseID(w, params[0])
+	if !ok {
+		return
+	}
+	request := client.CatalogItemUpdateRequest{}
+	if !readJSON(w, r, &request) {
+		return
+	}
+	ctrl.mu.Lock()
+	defer ctrl.mu.Unlock()
+	item, exists := ctrl.catalog[id]
+	if !exists {
+		writeError(w, http.StatusNotFound, "NotFoundError", fmt.Sprintf("Invalid catalog item id '%d'", id))
+		return
+	}
+	if request.Name != "" {
+		item.Name = request.Name
+	}
+	if request.Description != "" {
+		item.Description = request.Description
+	}
+	if len(request.Images) != 0 {
+		item.Images = request.Images
+	}
+	if request.RegistryID != 0 {
+		item.RegistryID = request.RegistryID
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (ctrl *Controller) deleteCatalogItem(w http.ResponseWriter, r *http.Request, params []string) {
+	id, ok := parseID(w, params[0])
+	if !ok {
+		return
+	}
+	ctrl.mu.Lock()
+	defer ctrl.mu.Unlock()
+	if _, exists := ctrl.catalog[id]; !exists {
+		writeError(w, http.StatusNotFound, "NotFoundError", fmt.Sprintf("Invalid catalog item id '%d'", id))
+		return
+	}
+	delete(ctrl.catalog, id)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (ctrl *Controller) listFlows(w http.ResponseWriter, r *http.Request, _ []string) {
+	ctrl.mu.Lock()
+	defer ctrl.mu.Unlock()
+	ids := sortedIDs(len(ctrl.flows), func(add func(int)) {
+		for id := range ctrl.flows {
+			add(id)
+		}
+	})
+	response := client.FlowListResponse{Flows: []client.FlowInfo{}}
+	for _, id := range ids {
+		response.Flows = append(response.Flows, *ctrl.flows[id])
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+func (ctrl *Controller) getFlow(w http.ResponseWriter, r *http.Request, params []string) {
+	id, ok := parseID(w, params[0])
+	if !ok {
+		return
+	}
+	ctrl.mu.Lock()
+	defer ctrl.mu.Unlock()
+	flow, exists := ctrl.flows[id]
+	if !exists {
+		writeError(w, http.StatusNotFound, "NotFoundError", fmt.Sprintf("Invalid flow id '%d'", id))
+		return
+	}
+	writeJSON(w, http.StatusOK, flow)
+}
+
+func (ctrl *Controller) createFlow(w http.ResponseWriter, r *http.Request, _ []string) {
+	request := client.FlowCreateRequest{}
+	if !readJSON(w, r, &request) {
+		return
+	}
+	ctrl.mu.Lock()
+	defer ctrl.mu.Unlock()
+	for _, flow := range ctrl.flows {
+		if flow.Name == request.Name {
+			writeError(w, http.StatusBadRequest, "DuplicatePropertyError", fmt.Sprintf("Duplicate name '%s'", request.Name))
+			return
+		}
+	}
+	id := ctrl.newID()
+	ctrl.flows[id] = &client.FlowInfo{ID: id, Name: request.Name, Description: request.Description}
+	writeJSON(w, http.StatusCreated, client.FlowCreateResponse{ID: id, Name: request.Name})
+}
+
+func (ctrl *Controller) patchFlow(w http.ResponseWriter, r *http.Request, params []string) {
+	id, ok := parseID(w, params[0])
+	if !ok {
+		return
+	}
+	request := client.FlowUpdateRequest{}
+	if !readJSON(w, r, &request) {
+		return
+	}
+	ctrl.mu.Lock()
+	defer ctrl.mu.Unlock()
+	flow, exists := ctrl.flows[id]
+	if !exists {
+		writeError(w, http.StatusNotFound, "NotFoundError", fmt.Sprintf("Invalid flow id '%d'", id))
+		return
+	}
+	if request.Name != nil {
+		flow.Name = *request.Name
+	}
+	if request.Description != nil {
+		flow.Description = *request.Description
+	}
+	if request.IsActivated != nil {
+		flow.IsActivated = *request.IsActivated
+	}
+	if request.IsSystem != nil {
+		flow.IsSystem = *request.IsSystem
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (ctrl *Controller) deleteFlow(w http.ResponseWriter, r *http.Request, params []string) {
+	id, ok := parseID(w, params[0])
+	if !ok {
+		return
+	}
+	ctrl.mu.Lock()
+	defer ctrl.mu.Unlock()
+	if _, exists := ctrl.flows[id]; !exists {
+		writeError(w, http.StatusNotFound, "NotFoundError", fmt.Sprintf("Invalid flow id '%d'", id))
+		return
+	}
+	delete(ctrl.flows, id)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func edgeResourceKey(name, version string) string {
+	return name + "/" + version
+}
+
+func (ctrl *Controller) listEdgeResources(w http.ResponseWriter, r *http.Request, _ []string) {
+	ctrl.mu.Lock()
+	defer ctrl.mu.Unlock()
+	keys := make([]string, 0, len(ctrl.edgeResources))
+	for key := range ctrl.edgeResources {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	start, end := ctrl.page(r.URL.Query(), len(keys))
+	response := client.ListEdgeResourceResponse{EdgeResources: []client.EdgeResourceMetadata{}}
+	for _, key := range keys[start:end] {
+		response.EdgeResources = append(response.EdgeResources, *ctrl.edgeResources[key])
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+func (ctrl *Controller) getEdgeResource(w http.ResponseWriter, r *http.Request, params []string) {
+	ctrl.mu.Lock()
+	defer ctrl.mu.Unlock()
+	key := edgeResourceKey(params[0], params[1])
+	resource, exists := ctrl.edgeResources[key]
+	if !exists {
+		writeError(w, http.StatusNotFound, "NotFoundError", fmt.Sprintf("Invalid edge resource '%s'", key))
+		return
+	}
+	writeJSON(w, http.StatusOK, resource)
+}
+
+func (ctrl *Controller) createEdgeResource(w http.ResponseWriter, r *http.Request, _ []string) {
+	resource := client.EdgeResourceMetadata{}
+	if !readJSON(w, r, &resource) {
+		return
+	}
+	ctrl.mu.Lock()
+	defer ctrl.mu.Unlock()
+	key := edgeResourceKey(resource.Name, resource.Version)
+	if _, exists := ctrl.edgeResources[key]; exists {
+		writeError(w, http.StatusBadRequest, "DuplicatePropertyError", fmt.Sprintf("Duplicate edge resource '%s'", key))
+		return
+	}
+	ctrl.edgeResources[key] = &resource
+	w.WriteHeader(http.StatusCreated)
+}
+
+func (ctrl *Controller) updateEdgeResource(w http.ResponseWriter, r *http.Request, params []string) {
+	resource := client.EdgeResourceMetadata{}
+	if !readJSON(w, r, &resource) {
+		return
+	}
+	ctrl.mu.Lock()
+	defer ctrl.mu.Unlock()
+	key := edgeResourceKey(params[0], params[1])
+	if _, exists := ctrl.edgeResources[key]; !exists {
+		writeError(w, http.StatusNotFound, "NotFoundError", fmt.Sprintf("Invalid edge resource '%s'", key))
+		return
+	}
+	if resource.Name == "" {
+		resource.Name = params[0]
+	}
+	if resource.Version == "" {
+		resource.Version = params[1]
+	}
+	delete(ctrl.edgeResources, key)
+	ctrl.edgeResources[edgeResourceKey(resource.Name, resource.Version)] = &resource
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (ctrl *Controller) deleteEdgeResource(w http.ResponseWriter, r *http.Request, params []string) {
+	ctrl.mu.Lock()
+	defer ctrl.mu.Unlock()
+	key := edgeResourceKey(params[0], params[1])
+	if _, exists := ctrl.edgeResources[key]; !exists {
+		writeError(w, http.StatusNotFound, "NotFoundError", fmt.Sprintf("Invalid edge resource '%s'", key))
+		return
+	}
+	delete(ctrl.edgeResources, key)
+	delete(ctrl.edgeResourceLinks, key)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (ctrl *Controller) linkEdgeResource(w http.ResponseWriter, r *http.Request, params []string) {
+	request := client.LinkEdgeResourceRequest{}
+	if !readJSON(w, r, &request) {
+		return
+	}
+	ctrl.mu.Lock()
+	defer ctrl.mu.Unlock()
+	key := edgeResourceKey(params[0], params[1])
+	if _, exists := ctrl.edgeResources[key]; !exists {
+		writeError(w, http.StatusNotFound, "NotFoundError", fmt.Sprintf("Invalid edge resource '%s'", key))
+		return
+	}
+	if _, exists := ctrl.agents[request.AgentUUID]; !exists {
+		writeError(w, http.StatusNotFound, "NotFoundError", fmt.Sprintf("Invalid agent UUID '%s'", request.AgentUUID))
+		return
+	}
+	if ctrl.edgeResourceLinks[key] == nil {
+		ctrl.edgeResourceLinks[key] = make(map[string]bool)
+	}
+	ctrl.edgeResourceLinks[key][request.AgentUUID] = true
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (ctrl *Controller) unlinkEdgeResource(w http.ResponseWriter, r *http.Request, params []string) {
+	request := client.LinkEdgeResourceRequest{}
+	if !readJSON(w, r, &request) {
+		return
+	}
+	ctrl.mu.Lock()
+	defer ctrl.mu.Unlock()
+	key := edgeResourceKey(params[0], params[1])
+	if !ctrl.edgeResourceLinks[key][request.AgentUUID] {
+		writeError(w, http.StatusNotFound, "NotFoundError", fmt.Sprintf("Agent '%s' is not linked to edge resource '%s'", request.AgentUUID, key))
+		return
+	}
+	delete(ctrl.edgeResourceLinks[key], request.AgentUUID)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (ctrl *Controller) getRouter(w http.ResponseWriter, r *http.Request, _ []string) {
+	ctrl.mu.Lock()
+	defer ctrl.mu.Unlock()
+	writeJSON(w, http.StatusOK, ctrl.router)
+}
+
+func (ctrl *Controller) putRouter(w http.ResponseWriter, r *http.Request, _ []string) {
+	router := client.Router{}
+	if !readJSON(w, r, &router) {
+		return
+	}
+	ctrl.mu.Lock()
+	defer ctrl.mu.Unlock()
+	ctrl.router = router
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (ctrl *Controller) putConfig(w http.ResponseWriter, r *http.Request, _ []string) {
+	request := client.UpdateConfigRequest{}
+	if !readJSON(w, r, &request) {
+		return
+	}
+	ctrl.mu.Lock()
+	defer ctrl.mu.Unlock()
+	ctrl.config[request.Key] = request.Value
+	w.WriteHeader(http.StatusNoContent)
+}
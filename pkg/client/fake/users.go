@@ -0,0 +1,149 @@
+/*
+ *  *******************************************************************************
+ *  * Copyright (c) 2019 Edgeworx, Inc.
+ *  *
+ *  * This program and the accompanying materials are made available under the
+ *  * terms of the Eclipse Public License v. 2.0 which is available at
+ *  * http://www.eclipse.org/legal/epl-2.0
+ *  *
+ *  * SPDX-License-Identifier: EPL-2.0
+ *  *******************************************************************************
+ *
+ */
+
+package fake
+
+import (
+	"net/http"
+
+	"github.com/eclipse-iofog/iofog-go-sdk/v3/pkg/client"
+)
+
+// Password returns the current password of a user, e.g. the temporary password set by a password reset
+func (ctrl *Controller) Password(email string) string {
+	ctrl.mu.Lock()
+	defer ctrl.mu.Unlock()
+	if user, exists := ctrl.users[email]; exists {
+		return user.Password
+	}
+	return ""
+}
+
+// ActivationCode returns the code emailed to activate a signed up account, empty once the account is activated
+func (ctrl *Controller) ActivationCode(email string) string {
+	ctrl.mu.Lock()
+	defer ctrl.mu.Unlock()
+	return ctrl.activationCodes[email]
+}
+
+func (ctrl *Controller) registerUserRoutes() {
+	ctrl.handle(http.MethodPost, "/user/logout", ctrl.logout)
+	ctrl.handle(http.MethodGet, "/user/profile", ctrl.getProfile)
+	ctrl.handle(http.MethodPatch, "/user/profile", ctrl.patchProfile)
+	ctrl.handle(http.MethodDelete, "/user/profile", ctrl.deleteAccount)
+	ctrl.handlePublic(http.MethodPost, "/user/password", ctrl.resetPassword)
+	ctrl.handlePublic(http.MethodGet, "/user/signup/resend-activation", ctrl.resendActivation)
+	ctrl.handlePublic(http.MethodPost, "/user/account/activate", ctrl.activateAccount)
+}
+
+// currentUser returns the user owning the access token of the request, ctrl.mu must be held
+func (ctrl *Controller) currentUser(r *http.Request) *client.User {
+	return ctrl.users[ctrl.tokens[r.Header.Get("Authorization")]]
+}
+
+func (ctrl *Controller) logout(w http.ResponseWriter, r *http.Request, _ []string) {
+	ctrl.mu.Lock()
+	defer ctrl.mu.Unlock()
+	delete(ctrl.tokens, r.Header.Get("Authorization"))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (ctrl *Controller) getProfile(w http.ResponseWriter, r *http.Request, _ []string) {
+	ctrl.mu.Lock()
+	defer ctrl.mu.Unlock()
+	writeJSON(w, http.StatusOK, userProfile(ctrl.currentUser(r)))
+}
+
+func (ctrl *Controller) patchProfile(w http.ResponseWriter, r *http.Request, _ []string) {
+	request := client.UpdateUserProfileRequest{}
+	if !readJSON(w, r, &request) {
+		return
+	}
+	ctrl.mu.Lock()
+	defer ctrl.mu.Unlock()
+	user := ctrl.currentUser(r)
+	if request.FirstName != "" {
+		user.Name = request.FirstName
+	}
+	if request.LastName != "" {
+		user.Surname = request.LastName
+	}
+	writeJSON(w, http.StatusOK, userProfile(user))
+}
+
+func (ctrl *Controller) deleteAccount(w http.ResponseWriter, r *http.Request, _ []string) {
+	request := client.DeleteUserAccountRequest{}
+	if !readJSON(w, r, &request) {
+		return
+	}
+	ctrl.mu.Lock()
+	defer ctrl.mu.Unlock()
+	email := ctrl.tokens[r.Header.Get("Authorization")]
+	delete(ctrl.users, email)
+	for token, owner := range ctrl.tokens {
+		if owner == email {
+			delete(ctrl.tokens, token)
+		}
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (ctrl *Controller) resetPassword(w http.ResponseWriter, r *http.Request, _ []string) {
+	request := client.ResetUserPasswordRequest{}
+	if !readJSON(w, r, &request) {
+		return
+	}
+	ctrl.mu.Lock()
+	defer ctrl.mu.Unlock()
+	// Like the Controller, do not reveal whether the account exists
+	if user, exists := ctrl.users[request.Email]; exists {
+		user.Password = randomHex(8)
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (ctrl *Controller) resendActivation(w http.ResponseWriter, r *http.Request, _ []string) {
+	ctrl.mu.Lock()
+	defer ctrl.mu.Unlock()
+	// A new code replaces the previous one of accounts pending activation
+	email := r.URL.Query().Get("email")
+	if _, pending := ctrl.activationCodes[email]; pending {
+		ctrl.activationCodes[email] = randomHex(4)
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (ctrl *Controller) activateAccount(w http.ResponseWriter, r *http.Request, _ []string) {
+	request := client.ActivateUserAccountRequest{}
+	if !readJSON(w, r, &request) {
+		return
+	}
+	ctrl.mu.Lock()
+	defer ctrl.mu.Unlock()
+	for email, code := range ctrl.activationCodes {
+		if code == request.ActivationCode {
+			delete(ctrl.activationCodes, email)
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+	}
+	writeError(w, http.StatusNotFound, "NotFoundError", "Activation code not found")
+}
+
+func userProfile(user *client.User) client.UserProfile {
+	return client.UserProfile{
+		FirstName: user.Name,
+		LastName:  user.Surname,
+		Email:     user.Email,
+	}
+}
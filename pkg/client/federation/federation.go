@@ -0,0 +1,250 @@
+/*
+ *  *******************************************************************************
+ *  * Copyright (c) 2019 Edgeworx, Inc.
+ *  *
+ *  * This program and the accompanying materials are made available under the
+ *  * terms of the Eclipse Public License v. 2.0 which is available at
+ *  * http://www.eclipse.org/legal/epl-2.0
+ *  *
+ *  * SPDX-License-Identifier: EPL-2.0
+ *  *******************************************************************************
+ *
+ */
+
+// Package federation fans calls out to several independent Controllers and merges their results,
+// annotated with the Controller each item comes from
+package federation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/eclipse-iofog/iofog-go-sdk/v3/pkg/client"
+	"github.com/eclipse-iofog/iofog-go-sdk/v3/pkg/client/clientconfig"
+)
+
+// Member is a Controller of the federation
+type Member struct {
+	// Name identifies the Controller in results and errors, e.g. its region
+	Name   string
+	Client *client.Client
+}
+
+// Federation issues calls to all of its members concurrently.
+// Like client.Client, it is safe for concurrent use
+type Federation struct {
+	members []Member
+}
+
+// New returns a federation of members, whose names must be unique
+func New(members ...Member) (*Federation, error) {
+	names := make(map[string]bool)
+	for _, member := range members {
+		if member.Name == "" || member.Client == nil {
+			return nil, client.NewInputError("Federation members need a name and a client")
+		}
+		if names[member.Name] {
+			return nil, client.NewInputError(fmt.Sprintf("Federation member %s is defined twice", member.Name))
+		}
+		names[member.Name] = true
+	}
+	return &Federation{members: append([]Member(nil), members...)}, nil
+}
+
+// FromConfig returns a federation of the contexts named names, or of every context when none is given.
+// Controllers which cannot be logged into are left out and reported in an *Error along with the federation of the others
+func FromConfig(ctx context.Context, config *clientconfig.Config, names ...string) (*Federation, error) {
+	if len(names) == 0 {
+		for idx := range config.Contexts {
+			names = append(names, config.Contexts[idx].Name)
+		}
+	}
+	clients := make([]*client.Client, len(names))
+	errs := make([]error, len(names))
+	wg := sync.WaitGroup{}
+	for idx, name := range names {
+		wg.Add(1)
+		go func(idx int, name string) {
+			defer wg.Done()
+			clients[idx], errs[idx] = config.NewClient(ctx, name)
+		}(idx, name)
+	}
+	wg.Wait()
+
+	members := []Member{}
+	fedErr := &Error{Errors: make(map[string]error)}
+	for idx, name := range names {
+		if errs[idx] != nil {
+			fedErr.Errors[name] = errs[idx]
+			continue
+		}
+		members = append(members, Member{Name: name, Client: clients[idx]})
+	}
+	fed, err := New(members...)
+	if err != nil {
+		return nil, err
+	}
+	if len(fedErr.Errors) > 0 {
+		return fed, fedErr
+	}
+	return fed, nil
+}
+
+// Members returns the names of the Controllers, in the order given to New
+func (fed *Federation) Members() []string {
+	names := make([]string, 0, len(fed.members))
+	for _, member := range fed.members {
+		names = append(names, member.Name)
+	}
+	return names
+}
+
+// Client returns the client of the Controller named name, nil when unknown
+func (fed *Federation) Client(name string) *client.Client {
+	for _, member := range fed.members {
+		if member.Name == name {
+			return member.Client
+		}
+	}
+	return nil
+}
+
+// Agent is an Agent of the Controller named Controller
+type Agent struct {
+	Controller string
+	client.AgentInfo
+}
+
+// Application is an Application of the Controller named Controller
+type Application struct {
+	Controller string
+	client.ApplicationInfo
+}
+
+// Microservice is a Microservice of the Controller named Controller
+type Microservice struct {
+	Controller string
+	client.MicroserviceInfo
+}
+
+// Error holds the failures of a federated call per Controller name.
+// The results of the other Controllers are returned along with it
+type Error struct {
+	Errors map[string]error
+}
+
+// Error export
+func (err *Error) Error() string {
+	names := make([]string, 0, len(err.Errors))
+	for name := range err.Errors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	msg := fmt.Sprintf("%d Controllers failed", len(names))
+	for _, name := range names {
+		msg += fmt.Sprintf("\n%s: %s", name, err.Errors[name].Error())
+	}
+	return msg
+}
+
+// Is reports whether any Controller failed with target
+func (err *Error) Is(target error) bool {
+	for _, memberErr := range err.Errors {
+		if errors.Is(memberErr, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// each calls fn for every member concurrently and collects the failures
+func (fed *Federation) each(ctx context.Context, fn func(ctx context.Context, idx int, member Member) error) error {
+	errs := make([]error, len(fed.members))
+	wg := sync.WaitGroup{}
+	for idx, member := range fed.members {
+		wg.Add(1)
+		go func(idx int, member Member) {
+			defer wg.Done()
+			errs[idx] = fn(ctx, idx, member)
+		}(idx, member)
+	}
+	wg.Wait()
+
+	fedErr := &Error{Errors: make(map[string]error)}
+	for idx, err := range errs {
+		if err != nil {
+			fedErr.Errors[fed.members[idx].Name] = err
+		}
+	}
+	if len(fedErr.Errors) == 0 {
+		return nil
+	}
+	return fedErr
+}
+
+// ListAgents lists the Agents of every Controller.
+// When some Controllers fail, the Agents of the others are returned with an *Error
+func (fed *Federation) ListAgents(ctx context.Context, request client.ListAgentsRequest) ([]Agent, error) {
+	results := make([][]Agent, len(fed.members))
+	err := fed.each(ctx, func(ctx context.Context, idx int, member Member) error {
+		response, err := member.Client.ListAgentsWithContext(ctx, request)
+		if err != nil {
+			return err
+		}
+		for _, agent := range response.Agents {
+			results[idx] = append(results[idx], Agent{Controller: member.Name, AgentInfo: agent})
+		}
+		return nil
+	})
+	agents := []Agent{}
+	for _, result := range results {
+		agents = append(agents, result...)
+	}
+	return agents, err
+}
+
+// GetAllApplications lists the Applications of every Controller.
+// When some Controllers fail, the Applications of the others are returned with an *Error
+func (fed *Federation) GetAllApplications(ctx context.Context) ([]Application, error) {
+	results := make([][]Application, len(fed.members))
+	err := fed.each(ctx, func(ctx context.Context, idx int, member Member) error {
+		response, err := member.Client.GetAllApplicationsWithContext(ctx)
+		if err != nil {
+			return err
+		}
+		for _, app := range response.Applications {
+			results[idx] = append(results[idx], Application{Controller: member.Name, ApplicationInfo: app})
+		}
+		return nil
+	})
+	apps := []Application{}
+	for _, result := range results {
+		apps = append(apps, result...)
+	}
+	return apps, err
+}
+
+// GetAllMicroservices lists the Microservices of every Controller.
+// When some Controllers fail, the Microservices of the others are returned with an *Error.
+// A Controller which failed to list some of its flows keeps its other Microservices and reports a *client.ListError
+func (fed *Federation) GetAllMicroservices(ctx context.Context) ([]Microservice, error) {
+	results := make([][]Microservice, len(fed.members))
+	err := fed.each(ctx, func(ctx context.Context, idx int, member Member) error {
+		response, err := member.Client.GetAllMicroservicesWithContext(ctx)
+		if response == nil {
+			return err
+		}
+		for _, msvc := range response.Microservices {
+			results[idx] = append(results[idx], Microservice{Controller: member.Name, MicroserviceInfo: msvc})
+		}
+		return err
+	})
+	msvcs := []Microservice{}
+	for _, result := range results {
+		msvcs = append(msvcs, result...)
+	}
+	return msvcs, err
+}
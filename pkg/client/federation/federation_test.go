@@ -0,0 +1,114 @@
+/*
+ *  *******************************************************************************
+ *  * Copyright (c) 2019 Edgeworx, Inc.
+ *  *
+ *  * This program and the accompanying materials are made available under the
+ *  * terms of the Eclipse Public License v. 2.0 which is available at
+ *  * http://www.eclipse.org/legal/epl-2.0
+ *  *
+ *  * SPDX-License-Identifier: EPL-2.0
+ *  *******************************************************************************
+ *
+ */
+
+package federation_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/eclipse-iofog/iofog-go-sdk/v3/pkg/client"
+	"github.com/eclipse-iofog/iofog-go-sdk/v3/pkg/client/fake"
+	"github.com/eclipse-iofog/iofog-go-sdk/v3/pkg/client/fake/faketest"
+	"github.com/eclipse-iofog/iofog-go-sdk/v3/pkg/client/federation"
+)
+
+func newMember(t *testing.T, name string, agents ...string) (federation.Member, *fake.Controller) {
+	ctrl, clt := faketest.NewLoggedIn(t, client.Options{})
+	for _, agent := range agents {
+		if _, err := clt.CreateAgent(&client.CreateAgentRequest{AgentUpdateRequest: client.AgentUpdateRequest{Name: agent}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return federation.Member{Name: name, Client: clt}, ctrl
+}
+
+func TestFederationPartialOutage(t *testing.T) {
+	eu, _ := newMember(t, "eu", "eu-1", "eu-2")
+	us, _ := newMember(t, "us", "us-1")
+	down, downCtrl := newMember(t, "ap")
+	downCtrl.Close()
+
+	fed, err := federation.New(eu, us, down)
+	if err != nil {
+		t.Fatal(err)
+	}
+	agents, err := fed.ListAgents(context.Background(), client.ListAgentsRequest{})
+
+	var fedErr *federation.Error
+	if !errors.As(err, &fedErr) || len(fedErr.Errors) != 1 || fedErr.Errors["ap"] == nil {
+		t.Fatalf("Expected only ap to fail, got %v", err)
+	}
+	expected := map[string]string{"eu-1": "eu", "eu-2": "eu", "us-1": "us"}
+	if len(agents) != len(expected) {
+		t.Fatalf("Expected %d Agents, got %d", len(expected), len(agents))
+	}
+	for _, agent := range agents {
+		if expected[agent.Name] != agent.Controller {
+			t.Errorf("Expected %s to come from %s, got %s", agent.Name, expected[agent.Name], agent.Controller)
+		}
+	}
+
+	if _, err := federation.New(eu, eu); !errors.Is(err, client.ErrInput) {
+		t.Errorf("Expected duplicate members to be rejected, got %v", err)
+	}
+}
+
+func TestFederationPartialMicroservices(t *testing.T) {
+	eu, euCtrl := newMember(t, "eu")
+	us, _ := newMember(t, "us")
+	// Controllers before 2.0.2 list microservices flow by flow, the second flow of eu fails
+	euCtrl.SetVersion("2.0.0")
+	eu.Client.ResetCapabilities()
+	flowIDs := []int{}
+	for _, name := range []string{"flow-1", "flow-2"} {
+		flow, err := eu.Client.CreateFlow(name, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		flowIDs = append(flowIDs, flow.ID)
+	}
+	euCtrl.Intercept(func(w http.ResponseWriter, r *http.Request) bool {
+		flowID := r.URL.Query().Get("flowId")
+		if !strings.HasSuffix(r.URL.Path, "/microservices") || flowID == "" {
+			return false
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if flowID == fmt.Sprint(flowIDs[1]) {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"name":"NotFoundError","message":"Invalid flow id"}`))
+			return true
+		}
+		_, _ = fmt.Fprintf(w, `{"microservices":[{"uuid":"msvc-%s","name":"msvc-%s","flowId":%s}]}`, flowID, flowID, flowID)
+		return true
+	})
+
+	fed, err := federation.New(eu, us)
+	if err != nil {
+		t.Fatal(err)
+	}
+	msvcs, err := fed.GetAllMicroservices(context.Background())
+
+	var fedErr *federation.Error
+	var listErr *client.ListError
+	if !errors.As(err, &fedErr) || len(fedErr.Errors) != 1 || !errors.As(fedErr.Errors["eu"], &listErr) {
+		t.Fatalf("Expected eu to report a list error, got %v", err)
+	}
+	if len(msvcs) != 1 || msvcs[0].Controller != "eu" || msvcs[0].Name != fmt.Sprintf("msvc-%d", flowIDs[0]) {
+		t.Errorf("Expected the microservice of the first flow of eu, got %+v", msvcs)
+	}
+}
@@ -14,13 +14,22 @@
 package client
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"strconv"
 )
 
 // GetFlowByID retrieve flow information using the Controller REST API
 func (clt *Client) GetFlowByID(id int) (flow *FlowInfo, err error) {
-	body, err := clt.doRequest("GET", fmt.Sprintf("/flow/%d", id), nil)
+	return clt.GetFlowByIDWithContext(context.Background(), id)
+}
+
+// GetFlowByIDWithContext is GetFlowByID with a context that can cancel the request or bound its deadline
+func (clt *Client) GetFlowByIDWithContext(ctx context.Context, id int) (flow *FlowInfo, err error) {
+	ctx = withOperation(ctx, "GetFlowByID")
+	body, err := clt.doRequest(ctx, "GET", fmt.Sprintf("/flow/%d", id), nil)
 	if err != nil {
 		return
 	}
@@ -33,41 +42,73 @@ func (clt *Client) GetFlowByID(id int) (flow *FlowInfo, err error) {
 
 // CreateFlow creates a new flow using the Controller REST API
 func (clt *Client) CreateFlow(name, description string) (*FlowInfo, error) {
+	return clt.CreateFlowWithContext(context.Background(), name, description)
+}
+
+// CreateFlowWithContext is CreateFlow with a context that can cancel the request or bound its deadline
+func (clt *Client) CreateFlowWithContext(ctx context.Context, name, description string) (*FlowInfo, error) {
+	ctx = withOperation(ctx, "CreateFlow")
 	response := FlowCreateResponse{}
-	body, err := clt.doRequest("POST", "/flow", FlowCreateRequest{Name: name, Description: description})
+	body, err := clt.doRequest(ctx, "POST", "/flow", FlowCreateRequest{Name: name, Description: description})
 	if err != nil {
 		return nil, err
 	}
+	clt.names.invalidate(flowNames)
 	if err := json.Unmarshal(body, &response); err != nil {
 		return nil, err
 	}
-	return clt.GetFlowByID(response.ID)
+	return clt.GetFlowByIDWithContext(ctx, response.ID)
 }
 
 // UpdateFlow patches a flow using the Controller REST API
 func (clt *Client) UpdateFlow(request *FlowUpdateRequest) (*FlowInfo, error) {
-	_, err := clt.doRequest("PATCH", fmt.Sprintf("/flow/%d", request.ID), *request)
+	return clt.UpdateFlowWithContext(context.Background(), request)
+}
+
+// UpdateFlowWithContext is UpdateFlow with a context that can cancel the request or bound its deadline
+func (clt *Client) UpdateFlowWithContext(ctx context.Context, request *FlowUpdateRequest) (*FlowInfo, error) {
+	ctx = withOperation(ctx, "UpdateFlow")
+	_, err := clt.doRequest(ctx, "PATCH", fmt.Sprintf("/flow/%d", request.ID), *request)
 	if err != nil {
 		return nil, err
 	}
-	return clt.GetFlowByID(request.ID)
+	clt.names.invalidate(flowNames)
+	return clt.GetFlowByIDWithContext(ctx, request.ID)
 }
 
 // StartFlow set the flow as active using the Controller REST API
 func (clt *Client) StartFlow(id int) (*FlowInfo, error) {
+	return clt.StartFlowWithContext(context.Background(), id)
+}
+
+// StartFlowWithContext is StartFlow with a context that can cancel the request or bound its deadline
+func (clt *Client) StartFlowWithContext(ctx context.Context, id int) (*FlowInfo, error) {
+	ctx = withOperation(ctx, "StartFlow")
 	active := true
-	return clt.UpdateFlow(&FlowUpdateRequest{ID: id, IsActivated: &active})
+	return clt.UpdateFlowWithContext(ctx, &FlowUpdateRequest{ID: id, IsActivated: &active})
 }
 
 // StopFlow set the flow as inactive using the Controller REST API
 func (clt *Client) StopFlow(id int) (*FlowInfo, error) {
+	return clt.StopFlowWithContext(context.Background(), id)
+}
+
+// StopFlowWithContext is StopFlow with a context that can cancel the request or bound its deadline
+func (clt *Client) StopFlowWithContext(ctx context.Context, id int) (*FlowInfo, error) {
+	ctx = withOperation(ctx, "StopFlow")
 	active := false
-	return clt.UpdateFlow(&FlowUpdateRequest{ID: id, IsActivated: &active})
+	return clt.UpdateFlowWithContext(ctx, &FlowUpdateRequest{ID: id, IsActivated: &active})
 }
 
 // GetAllFlows retrieve all flows information from the Controller REST API
 func (clt *Client) GetAllFlows() (response *FlowListResponse, err error) {
-	body, err := clt.doRequest("GET", "/flow", nil)
+	return clt.GetAllFlowsWithContext(context.Background())
+}
+
+// GetAllFlowsWithContext is GetAllFlows with a context that can cancel the request or bound its deadline
+func (clt *Client) GetAllFlowsWithContext(ctx context.Context) (response *FlowListResponse, err error) {
+	ctx = withOperation(ctx, "GetAllFlows")
+	body, err := clt.doRequest(ctx, "GET", "/flow", nil)
 	if err != nil {
 		return
 	}
@@ -78,22 +119,62 @@ func (clt *Client) GetAllFlows() (response *FlowListResponse, err error) {
 	return response, nil
 }
 
-// GetFlowByName retrieve the flow information by getting all flows then searching for the first occurance in the list
+// GetFlowByName retrieves the flow information by getting all flows then finding the one with the specified name.
+// An AmbiguousError is returned when several flows share the name
 func (clt *Client) GetFlowByName(name string) (_ *FlowInfo, err error) {
-	list, err := clt.GetAllFlows()
+	return clt.GetFlowByNameWithContext(context.Background(), name)
+}
+
+// GetFlowByNameWithContext is GetFlowByName with a context that can cancel the request or bound its deadline
+func (clt *Client) GetFlowByNameWithContext(ctx context.Context, name string) (_ *FlowInfo, err error) {
+	ctx = withOperation(ctx, "GetFlowByName")
+	if id, cached := clt.names.get(flowNames, name); cached {
+		if flowID, convErr := strconv.Atoi(id); convErr == nil {
+			flow, err := clt.GetFlowByIDWithContext(ctx, flowID)
+			if err == nil && flow.Name == name {
+				return flow, nil
+			}
+			if err != nil && !errors.Is(err, ErrNotFound) {
+				return nil, err
+			}
+		}
+		clt.names.remove(flowNames, name)
+	}
+
+	// The Controller cannot filter flows, get all of them
+	list, err := clt.GetAllFlowsWithContext(ctx)
 	if err != nil {
 		return
 	}
-	for _, flow := range list.Flows {
-		if flow.Name == name {
-			return &flow, nil
+	var found *FlowInfo
+	ids := []string{}
+	for idx := range list.Flows {
+		if list.Flows[idx].Name == name {
+			found = &list.Flows[idx]
+			ids = append(ids, strconv.Itoa(found.ID))
 		}
 	}
-	return nil, NewNotFoundError(fmt.Sprintf("Could not find flow: %s", name))
+	switch len(ids) {
+	case 0:
+		return nil, NewNotFoundError(fmt.Sprintf("Could not find flow: %s", name))
+	case 1:
+		clt.names.set(flowNames, name, ids[0])
+		return found, nil
+	}
+	return nil, NewAmbiguousError(string(flowNames), name, ids)
 }
 
 // DeleteFlow deletes a flow using the Controller REST API
 func (clt *Client) DeleteFlow(id int) (err error) {
-	_, err = clt.doRequest("DELETE", fmt.Sprintf("/flow/%d", id), nil)
+	return clt.DeleteFlowWithContext(context.Background(), id)
+}
+
+// DeleteFlowWithContext is DeleteFlow with a context that can cancel the request or bound its deadline
+func (clt *Client) DeleteFlowWithContext(ctx context.Context, id int) (err error) {
+	ctx = withOperation(ctx, "DeleteFlow")
+	if _, err = clt.doRequest(ctx, "DELETE", fmt.Sprintf("/flow/%d", id), nil); err != nil {
+		return
+	}
+	clt.names.invalidate(flowNames)
 	return
 }
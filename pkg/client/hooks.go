@@ -0,0 +1,141 @@
+/*
+ *  *******************************************************************************
+ *  * Copyright (c) 2019 Edgeworx, Inc.
+ *  *
+ *  * This program and the accompanying materials are made available under the
+ *  * terms of the Eclipse Public License v. 2.0 which is available at
+ *  * http://www.eclipse.org/legal/epl-2.0
+ *  *
+ *  * SPDX-License-Identifier: EPL-2.0
+ *  *******************************************************************************
+ *
+ */
+
+package client
+
+import (
+	"context"
+	"time"
+)
+
+// RequestInfo describes one attempt of a request sent to the Controller
+type RequestInfo struct {
+	// Operation is the client call which sent the request, e.g. ListAgents
+	Operation string
+	Method    string
+	URL       string
+	// Attempt starts at 1 and is incremented by each retry
+	Attempt int
+}
+
+// ResponseInfo describes the outcome of one attempt
+type ResponseInfo struct {
+	RequestInfo
+	// StatusCode is 0 when no response was received
+	StatusCode int
+	Duration   time.Duration
+	Err        error
+}
+
+// Hooks observe the requests sent to the Controller, e.g. to export metrics or traces. Every field is optional
+type Hooks struct {
+	// OnRequest is called before each attempt. A non nil returned context is used to send the attempt and passed to OnResponse
+	OnRequest func(ctx context.Context, info RequestInfo) context.Context
+	// OnResponse is called after each attempt
+	OnResponse func(ctx context.Context, info ResponseInfo)
+	// OnRetry is called when a failed attempt is retried after delay, with the context of the failed attempt
+	OnRetry func(ctx context.Context, info RequestInfo, delay time.Duration, err error)
+}
+
+type operationKey struct{}
+
+// operationChainKey holds the names of the nested client calls, outermost first, used to resolve Options.OperationTimeouts
+type operationChainKey struct{}
+
+// WithOperation names the operation reported to Hooks for the requests sent with ctx.
+// Client calls name their own operation unless ctx already carries one.
+func WithOperation(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, operationKey{}, name)
+}
+
+// OperationFromContext returns the operation name carried by ctx, also available to custom transports through the request context
+func OperationFromContext(ctx context.Context) string {
+	name, _ := ctx.Value(operationKey{}).(string)
+	return name
+}
+
+// withOperation names the operation unless an outer call already did, and appends name to the operation chain
+func withOperation(ctx context.Context, name string) context.Context {
+	outer := operationChain(ctx)
+	chain := make([]string, len(outer), len(outer)+1)
+	copy(chain, outer)
+	ctx = context.WithValue(ctx, operationChainKey{}, append(chain, name))
+	if OperationFromContext(ctx) != "" {
+		return ctx
+	}
+	return WithOperation(ctx, name)
+}
+
+// operationChain returns the names of the nested client calls, outermost first, e.g. [UpgradeAgent GetAgentByName ListAgents]
+func operationChain(ctx context.Context) []string {
+	chain, _ := ctx.Value(operationChainKey{}).([]string)
+	return chain
+}
+
+// doAttempt sends one attempt of a request and reports it to the hooks.
+// The returned context is the one passed to the hooks, for notifyRetry to report the retry of this attempt
+func (clt *Client) doAttempt(ctx context.Context, attempt int, method, requestURL string, headers map[string]string, request interface{}) ([]byte, context.Context, error) {
+	info := RequestInfo{
+		Operation: OperationFromContext(ctx),
+		Method:    method,
+		URL:       requestURL,
+		Attempt:   attempt,
+	}
+	for _, hooks := range clt.hooks {
+		if hooks.OnRequest == nil {
+			continue
+		}
+		if hookCtx := hooks.OnRequest(ctx, info); hookCtx != nil {
+			ctx = hookCtx
+		}
+	}
+
+	attemptCtx, cancel := context.WithTimeout(ctx, clt.requestTimeout(ctx))
+	start := time.Now()
+	httpDo := httpDo{client: clt.httpClient, logger: clt.logger}
+	bytes, statusCode, err := httpDo.do(attemptCtx, method, requestURL, headers, request)
+	if stream := streamFromContext(ctx); stream != nil && stream.body != nil {
+		// The timeout keeps bounding the streamed body until it is closed
+		stream.cancel = cancel
+	} else {
+		cancel()
+	}
+
+	response := ResponseInfo{
+		RequestInfo: info,
+		StatusCode:  statusCode,
+		Duration:    time.Since(start),
+		Err:         err,
+	}
+	for idx := len(clt.hooks) - 1; idx >= 0; idx-- {
+		if clt.hooks[idx].OnResponse != nil {
+			clt.hooks[idx].OnResponse(ctx, response)
+		}
+	}
+	return bytes, ctx, err
+}
+
+func (clt *Client) notifyRetry(ctx context.Context, attempt int, method, requestURL string, delay time.Duration, err error) {
+	clt.logger.Info("Retrying request", "method", method, "url", requestURL, "attempt", attempt+1, "delay", delay, "error", err)
+	info := RequestInfo{
+		Operation: OperationFromContext(ctx),
+		Method:    method,
+		URL:       requestURL,
+		Attempt:   attempt,
+	}
+	for _, hooks := range clt.hooks {
+		if hooks.OnRetry != nil {
+			hooks.OnRetry(ctx, info, delay, err)
+		}
+	}
+}
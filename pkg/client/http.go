@@ -15,7 +15,7 @@ package client
 
 import (
 	"bytes"
-	"fmt"
+	"context"
 	"io"
 	"net/http"
 	"strings"
@@ -24,11 +24,18 @@ import (
 	json "github.com/json-iterator/go"
 )
 
+// replayableBody holds a request body which can be sent more than once
+type replayableBody []byte
+
 type httpDo struct {
-	timeout int
+	client *http.Client
+	logger Logger
 }
 
-func (hd *httpDo) do(method, url string, headers map[string]string, requestBody interface{}) (responseBody []byte, err error) {
+func (hd *httpDo) do(ctx context.Context, method, url string, headers map[string]string, requestBody interface{}) (responseBody []byte, statusCode int, err error) {
+	if replayable, ok := requestBody.(replayableBody); ok {
+		requestBody = bytes.NewReader(replayable)
+	}
 	body, isIoReader := requestBody.(io.Reader)
 	encodeType, ok := headers["Content-Type"]
 	if ok && encodeType == "application/json" {
@@ -44,52 +51,62 @@ func (hd *httpDo) do(method, url string, headers map[string]string, requestBody
 				jsonBody = string(jsonBodyBytes)
 			}
 
-			Verbose(fmt.Sprintf("===> [%s] %s \nBody: %s\n", method, url, jsonBody))
+			hd.logger.Debug("Sending request", "method", method, "url", url, "headers", redactHeaders(headers), "body", redactBody([]byte(jsonBody)))
 			body = strings.NewReader(jsonBody)
 		}
 	} else {
 		if !isIoReader {
-			return nil, NewInternalError("Failed to convert request body to io.Reader")
+			return nil, 0, NewInternalError("Failed to convert request body to io.Reader")
 		}
-		Verbose(fmt.Sprintf("===> [%s] %s \nContent-Type: %s\n", method, url, encodeType))
+		hd.logger.Debug("Sending request", "method", method, "url", url, "headers", redactHeaders(headers))
 	}
 
 	// Instantiate request
-	request, err := http.NewRequest(method, url, body)
+	request, err := http.NewRequestWithContext(ctx, method, url, body)
 	if err != nil {
 		return
 	}
 
-	// Don't re-use connections to avoid EOF error
-	request.Close = true
-
 	// Set headers on request
 	for key, val := range headers {
 		request.Header.Set(key, val)
 	}
 
 	// Perform request
-	client := &http.Client{
-		Timeout: time.Second * time.Duration(hd.timeout),
-	}
-
-	httpResp, err := client.Do(request)
+	start := time.Now()
+	httpResp, err := hd.client.Do(request)
 	if err != nil {
+		hd.logger.Debug("Request failed", "method", method, "url", url, "duration", time.Since(start), "error", err)
 		return
 	}
-	defer httpResp.Body.Close()
+	stream := streamFromContext(ctx)
+	defer func() {
+		// A streamed body is closed by its reader
+		if stream == nil || err != nil {
+			httpResp.Body.Close()
+		}
+	}()
+	statusCode = httpResp.StatusCode
 
 	// Check response
-	if err = checkStatusCode(httpResp.StatusCode, method, url, httpResp.Body); err != nil {
+	if err = checkStatusCode(httpResp, method, url); err != nil {
+		hd.logger.Debug("Received response", "method", method, "url", url, "status", statusCode, "duration", time.Since(start), "error", err)
 		return
 	}
 
+	// Hand the body over to the reader of a streamed response
+	if stream != nil {
+		stream.body = httpResp.Body
+		hd.logger.Debug("Streaming response", "method", method, "url", url, "status", statusCode, "duration", time.Since(start))
+		return nil, statusCode, nil
+	}
+
 	// Return body
 	buf := new(bytes.Buffer)
-	if _, err := buf.ReadFrom(httpResp.Body); err != nil {
-		return nil, err
+	if _, err = buf.ReadFrom(httpResp.Body); err != nil {
+		return nil, statusCode, err
 	}
 	responseBody = buf.Bytes()
-	Verbose(fmt.Sprintf("===> Response: %s\n\n", string(responseBody)))
-	return responseBody, err
+	hd.logger.Debug("Received response", "method", method, "url", url, "status", statusCode, "duration", time.Since(start), "body", redactBody(responseBody))
+	return responseBody, statusCode, err
 }
@@ -0,0 +1,437 @@
+/*
+ *  *******************************************************************************
+ *  * Copyright (c) 2019 Edgeworx, Inc.
+ *  *
+ *  * This program and the accompanying materials are made available under the
+ *  * terms of the Eclipse Public License v. 2.0 which is available at
+ *  * http://www.eclipse.org/legal/epl-2.0
+ *  *
+ *  * SPDX-License-Identifier: EPL-2.0
+ *  *******************************************************************************
+ *
+ */
+
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	json "github.com/json-iterator/go"
+)
+
+type streamKey struct{}
+
+// responseStream receives the body of a successful response instead of it being read into memory
+type responseStream struct {
+	body   io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func streamFromContext(ctx context.Context) *responseStream {
+	stream, _ := ctx.Value(streamKey{}).(*responseStream)
+	return stream
+}
+
+// withoutStream prevents requests made on behalf of a streamed request, e.g. a new login, from being streamed
+func withoutStream(ctx context.Context) context.Context {
+	return context.WithValue(ctx, streamKey{}, (*responseStream)(nil))
+}
+
+// streamedBody releases the request timeout once the body is closed
+type streamedBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (body *streamedBody) Close() error {
+	err := body.ReadCloser.Close()
+	body.cancel()
+	return err
+}
+
+// doStreamRequest performs a GET request like doRequest but returns the open response body.
+// The request timeout also bounds reading the body, which the caller must close
+func (clt *Client) doStreamRequest(ctx context.Context, requestPath string) (io.ReadCloser, error) {
+	stream := new(responseStream)
+	if _, err := clt.doRequest(context.WithValue(ctx, streamKey{}, stream), "GET", requestPath, nil); err != nil {
+		return nil, err
+	}
+	if stream.body == nil {
+		return nil, NewInternalError(fmt.Sprintf("No response body was streamed for %s", requestPath))
+	}
+	return &streamedBody{ReadCloser: stream.body, cancel: stream.cancel}, nil
+}
+
+// ListError is returned when a list assembled from several requests is incomplete.
+// The items returned by the other requests are still listed
+type ListError struct {
+	Kind string
+	// Total is the number of requests the list was assembled from
+	Total int
+	// Errors holds the failed requests by source, e.g. "flow 3"
+	Errors map[string]error
+}
+
+// Error export
+func (err *ListError) Error() string {
+	sources := make([]string, 0, len(err.Errors))
+	for source := range err.Errors {
+		sources = append(sources, source)
+	}
+	sort.Strings(sources)
+	msg := fmt.Sprintf("Failed to list %s from %d of %d sources", err.Kind, len(err.Errors), err.Total)
+	for _, source := range sources {
+		msg += fmt.Sprintf("\n%s: %s", source, err.Errors[source].Error())
+	}
+	return msg
+}
+
+// Is reports whether any of the failed requests failed with target
+func (err *ListError) Is(target error) bool {
+	for _, sourceErr := range err.Errors {
+		if errors.Is(sourceErr, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// listIterator decodes the items of the array held under key in the response of path one at a time.
+// When Options.PageSize is set and the Controller supports pagination, pages are requested with limit and offset query parameters
+type listIterator struct {
+	clt       *Client
+	ctx       context.Context
+	path      string
+	key       string
+	preflight func(ctx context.Context) error
+
+	started  bool
+	paginate bool
+	offset   int
+	inPage   int
+	body     io.ReadCloser
+	iter     *json.Iterator
+	done     bool
+	err      error
+}
+
+func (clt *Client) newListIterator(ctx context.Context, path, key string) *listIterator {
+	return &listIterator{clt: clt, ctx: ctx, path: path, key: key}
+}
+
+// next decodes the following item into item, it returns false once the list is exhausted or failed
+func (it *listIterator) next(item interface{}) bool {
+	for !it.done && it.err == nil {
+		if it.iter == nil && !it.open() {
+			return false
+		}
+		if it.iter.ReadArray() {
+			it.iter.ReadVal(item)
+			if it.iter.Error != nil {
+				return it.fail(it.iter.Error)
+			}
+			it.offset++
+			it.inPage++
+			return true
+		}
+		if it.iter.Error != nil {
+			return it.fail(it.iter.Error)
+		}
+
+		// A short page is the last one
+		it.close()
+		it.done = !it.paginate || it.inPage < it.clt.pageSize
+	}
+	return false
+}
+
+// open requests the next page and positions the decoder on the array
+func (it *listIterator) open() bool {
+	if !it.started {
+		it.started = true
+		if it.preflight != nil {
+			if err := it.preflight(it.ctx); err != nil {
+				return it.fail(err)
+			}
+		}
+		if it.clt.pageSize > 0 {
+			// The list is requested in one response when the support of pagination is unknown
+			supported, err := it.clt.SupportsWithContext(it.ctx, FeatureListPagination)
+			it.paginate = err == nil && supported
+		}
+	}
+
+	requestPath := it.path
+	if it.paginate {
+		separator := "?"
+		if strings.Contains(requestPath, "?") {
+			separator = "&"
+		}
+		requestPath += fmt.Sprintf("%slimit=%d&offset=%d", separator, it.clt.pageSize, it.offset)
+	}
+	body, err := it.clt.doStreamRequest(it.ctx, requestPath)
+	if err != nil {
+		return it.fail(err)
+	}
+	it.body = body
+	it.iter = json.Parse(json.ConfigCompatibleWithStandardLibrary, body, 4096)
+	it.inPage = 0
+
+	// Skip the fields preceding the list
+	for field := it.iter.ReadObject(); field != ""; field = it.iter.ReadObject() {
+		if strings.EqualFold(field, it.key) {
+			return true
+		}
+		it.iter.Skip()
+	}
+	if it.iter.Error != nil {
+		return it.fail(it.iter.Error)
+	}
+	// The response holds no list
+	it.close()
+	it.done = true
+	return false
+}
+
+func (it *listIterator) fail(err error) bool {
+	it.err = err
+	it.close()
+	return false
+}
+
+func (it *listIterator) close() {
+	if it.body != nil {
+		it.body.Close()
+	}
+	it.body = nil
+	it.iter = nil
+}
+
+// AgentIterator iterates over the Agents returned by IterateAgents
+type AgentIterator struct {
+	list    *listIterator
+	request ListAgentsRequest
+	agent   AgentInfo
+}
+
+// IterateAgents lists Agents like ListAgents, requesting pages when Options.PageSize is set and the Controller supports pagination
+// and decoding the response as it is received otherwise. The iterator must be closed unless Next returned false
+func (clt *Client) IterateAgents(ctx context.Context, request ListAgentsRequest) *AgentIterator {
+	ctx = withOperation(ctx, "IterateAgents")
+	list := clt.newListIterator(ctx, generateListAgentURL(request), "fogs")
+	list.preflight = func(ctx context.Context) error {
+		if !clt.isLoggedIn() {
+			return NewError("Controller client must be logged into perform List Agents request")
+		}
+		return nil
+	}
+	return &AgentIterator{list: list, request: request}
+}
+
+// Next advances to the next Agent, it returns false once all were listed or on failure, see Err
+func (it *AgentIterator) Next() bool {
+	for {
+		it.agent = AgentInfo{}
+		if !it.list.next(&it.agent) {
+			return false
+		}
+		if matchesAgent(&it.agent, it.request) {
+			return true
+		}
+	}
+}
+
+// Agent returns the current Agent
+func (it *AgentIterator) Agent() AgentInfo {
+	return it.agent
+}
+
+// Err returns the error that stopped the iteration, if any
+func (it *AgentIterator) Err() error {
+	return it.list.err
+}
+
+// Close releases the response being decoded
+func (it *AgentIterator) Close() error {
+	it.list.close()
+	return nil
+}
+
+// ApplicationIterator iterates over the applications returned by IterateApplications
+type ApplicationIterator struct {
+	list        *listIterator
+	application ApplicationInfo
+}
+
+// IterateApplications lists applications like GetAllApplications, see IterateAgents
+func (clt *Client) IterateApplications(ctx context.Context) *ApplicationIterator {
+	ctx = withOperation(ctx, "IterateApplications")
+	return &ApplicationIterator{list: clt.newListIterator(ctx, "/application", "applications")}
+}
+
+// Next advances to the next application, it returns false once all were listed or on failure, see Err
+func (it *ApplicationIterator) Next() bool {
+	it.application = ApplicationInfo{}
+	return it.list.next(&it.application)
+}
+
+// Application returns the current application
+func (it *ApplicationIterator) Application() ApplicationInfo {
+	return it.application
+}
+
+// Err returns the error that stopped the iteration, if any
+func (it *ApplicationIterator) Err() error {
+	return it.list.err
+}
+
+// Close releases the response being decoded
+func (it *ApplicationIterator) Close() error {
+	it.list.close()
+	return nil
+}
+
+// EdgeResourceIterator iterates over the Edge Resources returned by IterateEdgeResources
+type EdgeResourceIterator struct {
+	list         *listIterator
+	edgeResource EdgeResourceMetadata
+}
+
+// IterateEdgeResources lists Edge Resources like ListEdgeResources, see IterateAgents
+func (clt *Client) IterateEdgeResources(ctx context.Context) *EdgeResourceIterator {
+	ctx = withOperation(ctx, "IterateEdgeResources")
+	list := clt.newListIterator(ctx, "/edgeResources", "edgeResources")
+	list.preflight = clt.edgeResourcePreflight
+	return &EdgeResourceIterator{list: list}
+}
+
+// Next advances to the next Edge Resource, it returns false once all were listed or on failure, see Err
+func (it *EdgeResourceIterator) Next() bool {
+	it.edgeResource = EdgeResourceMetadata{}
+	return it.list.next(&it.edgeResource)
+}
+
+// EdgeResource returns the current Edge Resource
+func (it *EdgeResourceIterator) EdgeResource() EdgeResourceMetadata {
+	return it.edgeResource
+}
+
+// Err returns the error that stopped the iteration, if any
+func (it *EdgeResourceIterator) Err() error {
+	return it.list.err
+}
+
+// Close releases the response being decoded
+func (it *EdgeResourceIterator) Close() error {
+	it.list.close()
+	return nil
+}
+
+// MicroserviceIterator iterates over the microservices returned by IterateMicroservices
+type MicroserviceIterator struct {
+	clt          *Client
+	ctx          context.Context
+	started      bool
+	flows        []FlowInfo
+	flowCount    int
+	perFlow      bool
+	current      *listIterator
+	source       string
+	microservice MicroserviceInfo
+	err          error
+	failed       map[string]error
+}
+
+// IterateMicroservices lists microservices like GetAllMicroservices, see IterateAgents.
+// Controllers older than 2.0.2 are listed flow by flow, flows that fail to list do not stop the iteration
+// and are reported together by Err as a *ListError
+func (clt *Client) IterateMicroservices(ctx context.Context) *MicroserviceIterator {
+	ctx = withOperation(ctx, "IterateMicroservices")
+	return &MicroserviceIterator{clt: clt, ctx: ctx, failed: make(map[string]error)}
+}
+
+// Next advances to the next microservice, it returns false once all were listed or on failure, see Err
+func (it *MicroserviceIterator) Next() bool {
+	if !it.started && !it.start() {
+		return false
+	}
+	for it.current != nil {
+		it.microservice = MicroserviceInfo{}
+		if it.current.next(&it.microservice) {
+			return true
+		}
+		if err := it.current.err; err != nil {
+			if !it.perFlow {
+				it.err = err
+				return false
+			}
+			it.failed[it.source] = err
+		}
+		it.nextFlow()
+	}
+	return false
+}
+
+func (it *MicroserviceIterator) start() bool {
+	it.started = true
+	supported, err := it.clt.SupportsWithContext(it.ctx, FeatureListAllMicroservices)
+	if err != nil {
+		it.err = err
+		return false
+	}
+	if supported {
+		it.current = it.clt.newListIterator(it.ctx, "/microservices", "microservices")
+		return true
+	}
+
+	flows, err := it.clt.GetAllFlowsWithContext(it.ctx)
+	if err != nil {
+		it.err = err
+		return false
+	}
+	it.perFlow = true
+	it.flows = flows.Flows
+	it.flowCount = len(flows.Flows)
+	it.nextFlow()
+	return true
+}
+
+func (it *MicroserviceIterator) nextFlow() {
+	it.current = nil
+	if len(it.flows) == 0 {
+		return
+	}
+	flow := it.flows[0]
+	it.flows = it.flows[1:]
+	it.source = fmt.Sprintf("flow %d", flow.ID)
+	it.current = it.clt.newListIterator(it.ctx, fmt.Sprintf("/microservices?flowId=%d", flow.ID), "microservices")
+}
+
+// Microservice returns the current microservice
+func (it *MicroserviceIterator) Microservice() MicroserviceInfo {
+	return it.microservice
+}
+
+// Err returns the error that stopped the iteration, or a *ListError once done when some flows failed to list
+func (it *MicroserviceIterator) Err() error {
+	if it.err != nil {
+		return it.err
+	}
+	if len(it.failed) > 0 && it.current == nil {
+		return &ListError{Kind: "microservices", Total: it.flowCount, Errors: it.failed}
+	}
+	return nil
+}
+
+// Close releases the response being decoded
+func (it *MicroserviceIterator) Close() error {
+	if it.current != nil {
+		it.current.close()
+	}
+	return nil
+}
@@ -0,0 +1,173 @@
+/*
+ *  *******************************************************************************
+ *  * Copyright (c) 2019 Edgeworx, Inc.
+ *  *
+ *  * This program and the accompanying materials are made available under the
+ *  * terms of the Eclipse Public License v. 2.0 which is available at
+ *  * http://www.eclipse.org/legal/epl-2.0
+ *  *
+ *  * SPDX-License-Identifier: EPL-2.0
+ *  *******************************************************************************
+ *
+ */
+
+package client_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/eclipse-iofog/iofog-go-sdk/v3/pkg/client"
+	"github.com/eclipse-iofog/iofog-go-sdk/v3/pkg/client/fake"
+	"github.com/eclipse-iofog/iofog-go-sdk/v3/pkg/client/fake/faketest"
+)
+
+func newIteratorClient(t *testing.T) (*fake.Controller, *client.Client) {
+	ctrl, clt := faketest.NewLoggedIn(t, client.Options{PageSize: 2})
+	for _, name := range []string{"edge-1", "edge-2", "cloud-1", "edge-3", "edge-4"} {
+		if _, err := clt.CreateAgent(&client.CreateAgentRequest{AgentUpdateRequest: client.AgentUpdateRequest{Name: name}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return ctrl, clt
+}
+
+func TestIterateAgentsPaginated(t *testing.T) {
+	for _, paginated := range []bool{false, true} {
+		ctrl, clt := newIteratorClient(t)
+		ctrl.SetCapability(fake.ListPaginationCapability, paginated)
+
+		agents := clt.IterateAgents(context.Background(), client.NewAgentQuery().NameContains("edge").Request())
+		names := []string{}
+		for agents.Next() {
+			names = append(names, agents.Agent().Name)
+		}
+		if err := agents.Err(); err != nil {
+			t.Fatal(err)
+		}
+		if got := strings.Join(names, ","); got != "edge-1,edge-2,edge-3,edge-4" {
+			t.Errorf("paginated %t: expected the edge Agents, got %s", paginated, got)
+		}
+		// 4 matching Agents are listed by pages of 2, the last page being empty
+		expected := 1
+		if paginated {
+			expected = 3
+		}
+		if count := ctrl.RequestCount(http.MethodGet, "/iofog-list"); count != expected {
+			t.Errorf("paginated %t: expected %d list requests, got %d", paginated, expected, count)
+		}
+	}
+}
+
+func TestIterateAgentsPaginationOptIn(t *testing.T) {
+	ctrl, clt := faketest.NewLoggedIn(t, client.Options{})
+	ctrl.SetCapability(fake.ListPaginationCapability, true)
+	if _, err := clt.CreateAgent(&client.CreateAgentRequest{AgentUpdateRequest: client.AgentUpdateRequest{Name: "edge-1"}}); err != nil {
+		t.Fatal(err)
+	}
+	if list, err := clt.ListAgents(client.ListAgentsRequest{}); err != nil || len(list.Agents) != 1 {
+		t.Fatalf("expected edge-1 to be listed, got %v, %v", list, err)
+	}
+	if count := ctrl.RequestCount(http.MethodHead, "/capabilities/listPagination"); count != 0 {
+		t.Errorf("expected no pagination probe without PageSize, got %d", count)
+	}
+
+	// A failed probe lists in one response
+	ctrl, clt = newIteratorClient(t)
+	ctrl.SetCapability(fake.ListPaginationCapability, true)
+	ctrl.Intercept(func(w http.ResponseWriter, r *http.Request) bool {
+		if r.Method != http.MethodHead {
+			return false
+		}
+		w.WriteHeader(http.StatusForbidden)
+		return true
+	})
+	list, err := clt.ListAgents(client.ListAgentsRequest{})
+	if err != nil {
+		t.Fatalf("expected the probe failure to be ignored, got %v", err)
+	}
+	if len(list.Agents) != 5 || ctrl.RequestCount(http.MethodGet, "/iofog-list") != 1 {
+		t.Errorf("expected the Agents to be listed in one response, got %d Agents", len(list.Agents))
+	}
+}
+
+func TestIterateAgentsTruncatedResponse(t *testing.T) {
+	ctrl, clt := newIteratorClient(t)
+	ctrl.Intercept(func(w http.ResponseWriter, r *http.Request) bool {
+		if !strings.HasSuffix(r.URL.Path, "/iofog-list") {
+			return false
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"fogs":[{"uuid":"uuid-1","name":"edge-1"},{"uuid":"uuid-2","na`))
+		return true
+	})
+
+	agents := clt.IterateAgents(context.Background(), client.ListAgentsRequest{})
+	defer agents.Close()
+	if !agents.Next() || agents.Agent().UUID != "uuid-1" {
+		t.Fatalf("expected the first Agent to be decoded before the truncation, got %v", agents.Agent())
+	}
+	if agents.Next() {
+		t.Errorf("expected no Agent after the truncation, got %v", agents.Agent())
+	}
+	if agents.Err() == nil {
+		t.Error("expected the truncation to be reported")
+	}
+	if _, err := clt.ListAgents(client.ListAgentsRequest{}); err == nil {
+		t.Error("expected ListAgents to fail on the truncated response")
+	}
+}
+
+func TestGetAllMicroservicesPerFlow(t *testing.T) {
+	ctrl, clt := newIteratorClient(t)
+	// Controllers before 2.0.2 list microservices flow by flow
+	ctrl.SetVersion("2.0.0")
+	clt.ResetCapabilities()
+	flowIDs := []int{}
+	for _, name := range []string{"flow-1", "flow-2", "flow-3"} {
+		flow, err := clt.CreateFlow(name, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		flowIDs = append(flowIDs, flow.ID)
+	}
+	failing := fmt.Sprint(flowIDs[1])
+	ctrl.Intercept(func(w http.ResponseWriter, r *http.Request) bool {
+		flowID := r.URL.Query().Get("flowId")
+		if !strings.HasSuffix(r.URL.Path, "/microservices") || flowID == "" {
+			return false
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if flowID == failing {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"name":"NotFoundError","message":"Invalid flow id"}`))
+			return true
+		}
+		_, _ = fmt.Fprintf(w, `{"microservices":[{"uuid":"msvc-%s","name":"msvc-%s","flowId":%s}]}`, flowID, flowID, flowID)
+		return true
+	})
+
+	response, err := clt.GetAllMicroservices()
+	listErr := new(client.ListError)
+	if !errors.As(err, &listErr) {
+		t.Fatalf("expected a ListError, got %v", err)
+	}
+	if listErr.Total != 3 || len(listErr.Errors) != 1 || listErr.Errors["flow "+failing] == nil {
+		t.Errorf("expected flow %s to be reported, got %v", failing, listErr)
+	}
+	if !errors.Is(err, client.ErrNotFound) {
+		t.Errorf("expected the flow error to match ErrNotFound, got %v", err)
+	}
+	if response == nil || len(response.Microservices) != 2 {
+		t.Fatalf("expected the microservices of the other flows, got %v", response)
+	}
+	for idx, flowID := range []int{flowIDs[0], flowIDs[2]} {
+		if response.Microservices[idx].FlowID != flowID {
+			t.Errorf("expected microservice %d to be in flow %d, got %d", idx, flowID, response.Microservices[idx].FlowID)
+		}
+	}
+}
@@ -0,0 +1,145 @@
+/*
+ *  *******************************************************************************
+ *  * Copyright (c) 2019 Edgeworx, Inc.
+ *  *
+ *  * This program and the accompanying materials are made available under the
+ *  * terms of the Eclipse Public License v. 2.0 which is available at
+ *  * http://www.eclipse.org/legal/epl-2.0
+ *  *
+ *  * SPDX-License-Identifier: EPL-2.0
+ *  *******************************************************************************
+ *
+ */
+
+package client
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/go-logr/logr"
+	json "github.com/json-iterator/go"
+)
+
+// Logger receives structured records from the client, args being alternating keys and values.
+// NewLogrLogger adapts a logr.Logger.
+type Logger interface {
+	Debug(msg string, args ...interface{})
+	Info(msg string, args ...interface{})
+	Warn(msg string, args ...interface{})
+	Error(msg string, args ...interface{})
+}
+
+// NewLogrLogger adapts a logr.Logger to Logger. Debug records are logged at verbosity 1
+func NewLogrLogger(logger logr.Logger) Logger {
+	return logrLogger{logger: logger}
+}
+
+type logrLogger struct {
+	logger logr.Logger
+}
+
+func (l logrLogger) Debug(msg string, args ...interface{}) {
+	l.logger.V(1).Info(msg, args...)
+}
+
+func (l logrLogger) Info(msg string, args ...interface{}) {
+	l.logger.Info(msg, args...)
+}
+
+func (l logrLogger) Warn(msg string, args ...interface{}) {
+	l.logger.Info(msg, args...)
+}
+
+func (l logrLogger) Error(msg string, args ...interface{}) {
+	l.logger.Error(nil, msg, args...)
+}
+
+// verboseLogger is used when no Logger is configured and prints through Verbose
+type verboseLogger struct{}
+
+func (verboseLogger) Debug(msg string, args ...interface{}) {
+	Verbose(formatRecord(msg, args))
+}
+
+func (verboseLogger) Info(msg string, args ...interface{}) {
+	Verbose(formatRecord(msg, args))
+}
+
+func (verboseLogger) Warn(msg string, args ...interface{}) {
+	Verbose(formatRecord(msg, args))
+}
+
+func (verboseLogger) Error(msg string, args ...interface{}) {
+	Verbose(formatRecord(msg, args))
+}
+
+func formatRecord(msg string, args []interface{}) string {
+	var builder strings.Builder
+	builder.WriteString(msg)
+	for idx := 0; idx+1 < len(args); idx += 2 {
+		builder.WriteString(fmt.Sprintf(" %v=%v", args[idx], args[idx+1]))
+	}
+	return builder.String()
+}
+
+const redacted = "[REDACTED]"
+
+// Body fields whose value is never logged
+var sensitiveFields = []string{"password", "token", "secret"}
+
+func isSensitiveField(name string) bool {
+	name = strings.ToLower(name)
+	for _, field := range sensitiveFields {
+		if strings.Contains(name, field) {
+			return true
+		}
+	}
+	return false
+}
+
+// redactHeaders returns a copy of the headers without credentials
+func redactHeaders(headers map[string]string) map[string]string {
+	safe := make(map[string]string, len(headers))
+	for key, val := range headers {
+		if strings.EqualFold(key, "Authorization") && val != "" {
+			val = redacted
+		}
+		safe[key] = val
+	}
+	return safe
+}
+
+// redactBody returns a JSON body with the values of sensitive fields replaced
+func redactBody(body []byte) string {
+	if len(body) == 0 {
+		return ""
+	}
+	var content interface{}
+	if err := json.Unmarshal(body, &content); err != nil {
+		return fmt.Sprintf("<%d bytes>", len(body))
+	}
+	safe, err := json.Marshal(redactValue(content))
+	if err != nil {
+		return fmt.Sprintf("<%d bytes>", len(body))
+	}
+	return string(safe)
+}
+
+func redactValue(value interface{}) interface{} {
+	switch typed := value.(type) {
+	case map[string]interface{}:
+		for key, val := range typed {
+			if isSensitiveField(key) {
+				typed[key] = redacted
+				continue
+			}
+			typed[key] = redactValue(val)
+		}
+	case []interface{}:
+		for idx, val := range typed {
+			typed[idx] = redactValue(val)
+		}
+	}
+	return value
+}
@@ -0,0 +1,75 @@
+/*
+ *  *******************************************************************************
+ *  * Copyright (c) 2019 Edgeworx, Inc.
+ *  *
+ *  * This program and the accompanying materials are made available under the
+ *  * terms of the Eclipse Public License v. 2.0 which is available at
+ *  * http://www.eclipse.org/legal/epl-2.0
+ *  *
+ *  * SPDX-License-Identifier: EPL-2.0
+ *  *******************************************************************************
+ *
+ */
+
+package client
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+)
+
+type recordingLogger struct {
+	mu      sync.Mutex
+	records []string
+}
+
+func (l *recordingLogger) record(msg string, args []interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.records = append(l.records, formatRecord(msg, args))
+}
+
+func (l *recordingLogger) Debug(msg string, args ...interface{}) { l.record(msg, args) }
+func (l *recordingLogger) Info(msg string, args ...interface{})  { l.record(msg, args) }
+func (l *recordingLogger) Warn(msg string, args ...interface{})  { l.record(msg, args) }
+func (l *recordingLogger) Error(msg string, args ...interface{}) { l.record(msg, args) }
+
+func TestLoggerRedactsCredentials(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v3/user/login":
+			fmt.Fprint(w, `{"accessToken":"secret-token"}`)
+		default:
+			fmt.Fprint(w, `{"fogs":[]}`)
+		}
+	}))
+	defer server.Close()
+
+	baseURL, err := url.Parse(server.URL + "/api/v3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger := &recordingLogger{}
+	clt := New(Options{BaseURL: baseURL, Logger: logger})
+	if err := clt.Login(LoginRequest{Email: "user@domain.com", Password: "secret-password"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := clt.ListAgents(ListAgentsRequest{}); err != nil {
+		t.Fatal(err)
+	}
+
+	logged := strings.Join(logger.records, "\n")
+	for _, secret := range []string{"secret-password", "secret-token"} {
+		if strings.Contains(logged, secret) {
+			t.Errorf("Logs contain %s:\n%s", secret, logged)
+		}
+	}
+	if !strings.Contains(logged, "user@domain.com") || !strings.Contains(logged, "/iofog-list") {
+		t.Errorf("Logs miss request details:\n%s", logged)
+	}
+}
@@ -0,0 +1,83 @@
+/*
+ *  *******************************************************************************
+ *  * Copyright (c) 2019 Edgeworx, Inc.
+ *  *
+ *  * This program and the accompanying materials are made available under the
+ *  * terms of the Eclipse Public License v. 2.0 which is available at
+ *  * http://www.eclipse.org/legal/epl-2.0
+ *  *
+ *  * SPDX-License-Identifier: EPL-2.0
+ *  *******************************************************************************
+ *
+ */
+
+// Package metrics exports Prometheus metrics for the Controller requests sent by client.Client
+package metrics
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/eclipse-iofog/iofog-go-sdk/v3/pkg/client"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Metrics is a prometheus.Collector counting requests, retries and latencies per client operation
+type Metrics struct {
+	requests *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+	retries  *prometheus.CounterVec
+}
+
+// New creates the metrics, prefixed by namespace when not empty. They must be registered, e.g. with prometheus.MustRegister
+func New(namespace string) *Metrics {
+	return &Metrics{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "iofog_client",
+			Name:      "requests_total",
+			Help:      "Number of requests sent to the ioFog Controller, by operation, method and status code (0 when no response was received).",
+		}, []string{"operation", "method", "code"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "iofog_client",
+			Name:      "request_duration_seconds",
+			Help:      "Latency of the requests sent to the ioFog Controller, by operation and method.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"operation", "method"}),
+		retries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "iofog_client",
+			Name:      "retries_total",
+			Help:      "Number of retried requests to the ioFog Controller, by operation and method.",
+		}, []string{"operation", "method"}),
+	}
+}
+
+// Hooks returns the client hooks updating the metrics, to be set in client.Options
+func (m *Metrics) Hooks() client.Hooks {
+	return client.Hooks{
+		OnResponse: func(_ context.Context, info client.ResponseInfo) {
+			m.requests.WithLabelValues(info.Operation, info.Method, strconv.Itoa(info.StatusCode)).Inc()
+			m.duration.WithLabelValues(info.Operation, info.Method).Observe(info.Duration.Seconds())
+		},
+		OnRetry: func(_ context.Context, info client.RequestInfo, _ time.Duration, _ error) {
+			m.retries.WithLabelValues(info.Operation, info.Method).Inc()
+		},
+	}
+}
+
+// Describe implements prometheus.Collector
+func (m *Metrics) Describe(ch chan<- *prometheus.Desc) {
+	m.requests.Describe(ch)
+	m.duration.Describe(ch)
+	m.retries.Describe(ch)
+}
+
+// Collect implements prometheus.Collector
+func (m *Metrics) Collect(ch chan<- prometheus.Metric) {
+	m.requests.Collect(ch)
+	m.duration.Collect(ch)
+	m.retries.Collect(ch)
+}
@@ -0,0 +1,78 @@
+/*
+ *  *******************************************************************************
+ *  * Copyright (c) 2019 Edgeworx, Inc.
+ *  *
+ *  * This program and the accompanying materials are made available under the
+ *  * terms of the Eclipse Public License v. 2.0 which is available at
+ *  * http://www.eclipse.org/legal/epl-2.0
+ *  *
+ *  * SPDX-License-Identifier: EPL-2.0
+ *  *******************************************************************************
+ *
+ */
+
+package metrics
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/eclipse-iofog/iofog-go-sdk/v3/pkg/client"
+	"github.com/eclipse-iofog/iofog-go-sdk/v3/pkg/client/fake/faketest"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestMetricsPerOperation(t *testing.T) {
+	metrics := New("test")
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(metrics)
+	ctrl, clt := faketest.NewLoggedIn(t, client.Options{
+		Hooks:   []client.Hooks{metrics.Hooks()},
+		Retries: &client.Retries{Policy: &client.RetryPolicy{InitialBackoff: time.Millisecond}},
+	})
+
+	// Fail the first listing with a retryable status
+	failed := false
+	ctrl.Intercept(func(w http.ResponseWriter, r *http.Request) bool {
+		if r.URL.Path == "/api/v3/iofog-list" && !failed {
+			failed = true
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return true
+		}
+		return false
+	})
+
+	if _, err := clt.ListAgents(client.ListAgentsRequest{}); err != nil {
+		t.Fatal(err)
+	}
+
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	counts := make(map[string]float64)
+	for _, family := range families {
+		for _, metric := range family.GetMetric() {
+			labels := family.GetName()
+			for _, label := range metric.GetLabel() {
+				labels += " " + label.GetValue()
+			}
+			if metric.GetCounter() != nil {
+				counts[labels] = metric.GetCounter().GetValue()
+			}
+		}
+	}
+	// Label values are sorted by label name: code, method, operation
+	expected := map[string]float64{
+		"test_iofog_client_requests_total 503 GET ListAgents": 1,
+		"test_iofog_client_requests_total 200 GET ListAgents": 1,
+		"test_iofog_client_requests_total 200 POST Login":     1,
+		"test_iofog_client_retries_total GET ListAgents":      1,
+	}
+	for labels, value := range expected {
+		if counts[labels] != value {
+			t.Errorf("Expected %s to be %v, got %v", labels, value, counts[labels])
+		}
+	}
+}
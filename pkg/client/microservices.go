@@ -15,16 +15,23 @@ package client
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"mime/multipart"
-	"strings"
 )
 
 // GetMicroserviceByName retrieves a microservice information using Controller REST API
 func (clt *Client) GetMicroserviceByName(appName, name string) (response *MicroserviceInfo, err error) {
-	listMsvcs, err := clt.GetMicroservicesByApplication(appName)
+	return clt.GetMicroserviceByNameWithContext(context.Background(), appName, name)
+}
+
+// GetMicroserviceByNameWithContext is GetMicroserviceByName with a context that can cancel the request or bound its deadline
+func (clt *Client) GetMicroserviceByNameWithContext(ctx context.Context, appName, name string) (response *MicroserviceInfo, err error) {
+	ctx = withOperation(ctx, "GetMicroserviceByName")
+	listMsvcs, err := clt.GetMicroservicesByApplicationWithContext(ctx, appName)
 	if err != nil {
 		return nil, err
 	}
@@ -38,7 +45,13 @@ func (clt *Client) GetMicroserviceByName(appName, name string) (response *Micros
 
 // GetMicroserviceByID retrieves a microservice information using Controller REST API
 func (clt *Client) GetMicroserviceByID(uuid string) (response *MicroserviceInfo, err error) {
-	body, err := clt.doRequest("GET", fmt.Sprintf("/microservices/%s", uuid), nil)
+	return clt.GetMicroserviceByIDWithContext(context.Background(), uuid)
+}
+
+// GetMicroserviceByIDWithContext is GetMicroserviceByID with a context that can cancel the request or bound its deadline
+func (clt *Client) GetMicroserviceByIDWithContext(ctx context.Context, uuid string) (response *MicroserviceInfo, err error) {
+	ctx = withOperation(ctx, "GetMicroserviceByID")
+	body, err := clt.doRequest(ctx, "GET", fmt.Sprintf("/microservices/%s", uuid), nil)
 	if err != nil {
 		return
 	}
@@ -53,6 +66,12 @@ func (clt *Client) GetMicroserviceByID(uuid string) (response *MicroserviceInfo,
 // CreateMicroserviceFromYAML creates a new microservice using the Controller REST API
 // It sends the yaml file to Controller REST API
 func (clt *Client) CreateMicroserviceFromYAML(file io.Reader) (*MicroserviceInfo, error) {
+	return clt.CreateMicroserviceFromYAMLWithContext(context.Background(), file)
+}
+
+// CreateMicroserviceFromYAMLWithContext is CreateMicroserviceFromYAML with a context that can cancel the request or bound its deadline
+func (clt *Client) CreateMicroserviceFromYAMLWithContext(ctx context.Context, file io.Reader) (*MicroserviceInfo, error) {
+	ctx = withOperation(ctx, "CreateMicroserviceFromYAML")
 	requestBody := &bytes.Buffer{}
 	writer := multipart.NewWriter(requestBody)
 	part, _ := writer.CreateFormFile("microservice", "microservice.yaml")
@@ -65,7 +84,7 @@ func (clt *Client) CreateMicroserviceFromYAML(file io.Reader) (*MicroserviceInfo
 	headers := map[string]string{
 		"Content-Type": writer.FormDataContentType(),
 	}
-	body, err := clt.doRequestWithHeaders("POST", "/microservices/yaml", requestBody, headers)
+	body, err := clt.doRequestWithHeaders(ctx, "POST", "/microservices/yaml", requestBody, headers)
 
 	if err != nil {
 		return nil, err
@@ -74,23 +93,18 @@ func (clt *Client) CreateMicroserviceFromYAML(file io.Reader) (*MicroserviceInfo
 	if err := json.Unmarshal(body, &response); err != nil {
 		return nil, err
 	}
-	return clt.GetMicroserviceByID(response.UUID)
+	return clt.GetMicroserviceByIDWithContext(ctx, response.UUID)
 }
 
 // GetMicroservicesPerFlow (DEPRECATED) returns a list of microservices in a specific flow using Controller REST API
 func (clt *Client) GetMicroservicesPerFlow(flowID int) (response *MicroserviceListResponse, err error) {
-	body, err := clt.doRequest("GET", fmt.Sprintf("/microservices?flowId=%d", flowID), nil)
-	if err != nil {
-		return
-	}
-	response = new(MicroserviceListResponse)
-	err = json.Unmarshal(body, response)
-	return
+	return clt.GetMicroservicesPerFlowWithContext(context.Background(), flowID)
 }
 
-// GetMicroservicesByApplication returns a list of microservices in a specific application using Controller REST API
-func (clt *Client) GetMicroservicesByApplication(application string) (response *MicroserviceListResponse, err error) {
-	body, err := clt.doRequest("GET", fmt.Sprintf("/microservices?application=%s", application), nil)
+// GetMicroservicesPerFlowWithContext is GetMicroservicesPerFlow with a context that can cancel the request or bound its deadline
+func (clt *Client) GetMicroservicesPerFlowWithContext(ctx context.Context, flowID int) (response *MicroserviceListResponse, err error) {
+	ctx = withOperation(ctx, "GetMicroservicesPerFlow")
+	body, err := clt.doRequest(ctx, "GET", fmt.Sprintf("/microservices?flowId=%d", flowID), nil)
 	if err != nil {
 		return
 	}
@@ -99,28 +113,15 @@ func (clt *Client) GetMicroservicesByApplication(application string) (response *
 	return
 }
 
-// GetAllMicroservices returns all microservices on the Controller by listing all flows,
-// then getting a list of microservices per flow.
-func (clt *Client) getAllMicroservicesDeprecated() (response *MicroserviceListResponse, err error) {
-	flows, err := clt.GetAllFlows()
-	if err != nil {
-		return nil, err
-	}
-	response = new(MicroserviceListResponse)
-
-	for _, flow := range flows.Flows {
-		listPerFlow, err := clt.GetMicroservicesPerFlow(flow.ID)
-		if err != nil {
-			continue
-		}
-		response.Microservices = append(response.Microservices, listPerFlow.Microservices...)
-	}
-	return
+// GetMicroservicesByApplication returns a list of microservices in a specific application using Controller REST API
+func (clt *Client) GetMicroservicesByApplication(application string) (response *MicroserviceListResponse, err error) {
+	return clt.GetMicroservicesByApplicationWithContext(context.Background(), application)
 }
 
-// GetAllMicroservices returns all microservices on the Controller across all (non-system) flows
-func (clt *Client) getAllMicroservices() (response *MicroserviceListResponse, err error) {
-	body, err := clt.doRequest("GET", "/microservices", nil)
+// GetMicroservicesByApplicationWithContext is GetMicroservicesByApplication with a context that can cancel the request or bound its deadline
+func (clt *Client) GetMicroservicesByApplicationWithContext(ctx context.Context, application string) (response *MicroserviceListResponse, err error) {
+	ctx = withOperation(ctx, "GetMicroservicesByApplication")
+	body, err := clt.doRequest(ctx, "GET", fmt.Sprintf("/microservices?application=%s", application), nil)
 	if err != nil {
 		return
 	}
@@ -129,21 +130,39 @@ func (clt *Client) getAllMicroservices() (response *MicroserviceListResponse, er
 	return
 }
 
+// GetAllMicroservices returns all microservices on the Controller across all (non-system) flows.
+// Controllers older than 2.0.2 are listed flow by flow, the microservices of the flows that could be listed
+// are then returned along with a *ListError for the others
 func (clt *Client) GetAllMicroservices() (response *MicroserviceListResponse, err error) {
-	major, minor, patch, err := clt.GetVersionNumbers()
-	if err != nil {
-		return
+	return clt.GetAllMicroservicesWithContext(context.Background())
+}
+
+// GetAllMicroservicesWithContext is GetAllMicroservices with a context that can cancel the request or bound its deadline
+func (clt *Client) GetAllMicroservicesWithContext(ctx context.Context) (response *MicroserviceListResponse, err error) {
+	ctx = withOperation(ctx, "GetAllMicroservices")
+	microservices := clt.IterateMicroservices(ctx)
+	defer microservices.Close()
+	response = &MicroserviceListResponse{Microservices: []MicroserviceInfo{}}
+	for microservices.Next() {
+		response.Microservices = append(response.Microservices, microservices.Microservice())
 	}
-	isCapable := (major >= 2 && minor >= 0 && patch >= 2)
-	if strings.Contains(clt.status.version, "dev") || isCapable {
-		return clt.getAllMicroservices()
+	err = microservices.Err()
+	var listErr *ListError
+	if err != nil && !errors.As(err, &listErr) {
+		return nil, err
 	}
-	return clt.getAllMicroservicesDeprecated()
+	return response, err
 }
 
 // GetMicroservicePortMapping retrieves a microservice port mappings using Controller REST API
 func (clt *Client) GetMicroservicePortMapping(uuid string) (response *MicroservicePortMappingListResponse, err error) {
-	body, err := clt.doRequest("GET", fmt.Sprintf("/microservices/%s/port-mapping", uuid), nil)
+	return clt.GetMicroservicePortMappingWithContext(context.Background(), uuid)
+}
+
+// GetMicroservicePortMappingWithContext is GetMicroservicePortMapping with a context that can cancel the request or bound its deadline
+func (clt *Client) GetMicroservicePortMappingWithContext(ctx context.Context, uuid string) (response *MicroservicePortMappingListResponse, err error) {
+	ctx = withOperation(ctx, "GetMicroservicePortMapping")
+	body, err := clt.doRequest(ctx, "GET", fmt.Sprintf("/microservices/%s/port-mapping", uuid), nil)
 	if err != nil {
 		return
 	}
@@ -155,18 +174,36 @@ func (clt *Client) GetMicroservicePortMapping(uuid string) (response *Microservi
 
 // DeleteMicroservicePortMapping deletes a microservice port mapping using Controller REST API
 func (clt *Client) DeleteMicroservicePortMapping(uuid string, portMapping *MicroservicePortMappingInfo) (err error) {
-	_, err = clt.doRequest("DELETE", fmt.Sprintf("/microservices/%s/port-mapping/%v", uuid, portMapping.Internal), nil)
+	return clt.DeleteMicroservicePortMappingWithContext(context.Background(), uuid, portMapping)
+}
+
+// DeleteMicroservicePortMappingWithContext is DeleteMicroservicePortMapping with a context that can cancel the request or bound its deadline
+func (clt *Client) DeleteMicroservicePortMappingWithContext(ctx context.Context, uuid string, portMapping *MicroservicePortMappingInfo) (err error) {
+	ctx = withOperation(ctx, "DeleteMicroservicePortMapping")
+	_, err = clt.doRequest(ctx, "DELETE", fmt.Sprintf("/microservices/%s/port-mapping/%v", uuid, portMapping.Internal), nil)
 	return
 }
 
 // CreateMicroservicePortMapping creates a microservice port mapping using Controller REST API
 func (clt *Client) CreateMicroservicePortMapping(uuid string, portMapping *MicroservicePortMappingInfo) (err error) {
-	_, err = clt.doRequest("POST", fmt.Sprintf("/microservices/%s/port-mapping", uuid), portMapping)
+	return clt.CreateMicroservicePortMappingWithContext(context.Background(), uuid, portMapping)
+}
+
+// CreateMicroservicePortMappingWithContext is CreateMicroservicePortMapping with a context that can cancel the request or bound its deadline
+func (clt *Client) CreateMicroservicePortMappingWithContext(ctx context.Context, uuid string, portMapping *MicroservicePortMappingInfo) (err error) {
+	ctx = withOperation(ctx, "CreateMicroservicePortMapping")
+	_, err = clt.doRequest(ctx, "POST", fmt.Sprintf("/microservices/%s/port-mapping", uuid), portMapping)
 	return
 }
 
 func (clt *Client) GetAllMicroservicePublicPorts() (response []MicroservicePublicPort, err error) {
-	body, err := clt.doRequest("GET", "/microservices/public-ports", nil)
+	return clt.GetAllMicroservicePublicPortsWithContext(context.Background())
+}
+
+// GetAllMicroservicePublicPortsWithContext is GetAllMicroservicePublicPorts with a context that can cancel the request or bound its deadline
+func (clt *Client) GetAllMicroservicePublicPortsWithContext(ctx context.Context) (response []MicroservicePublicPort, err error) {
+	ctx = withOperation(ctx, "GetAllMicroservicePublicPorts")
+	body, err := clt.doRequest(ctx, "GET", "/microservices/public-ports", nil)
 	if err != nil {
 		return
 	}
@@ -186,17 +223,35 @@ func mapFromArray(arr []string) map[string]bool {
 
 // CreateMicroserviceRoute creates a microservice route using Controller REST API
 func (clt *Client) CreateMicroserviceRoute(uuid, destUUID string) (err error) {
-	_, err = clt.doRequest("POST", fmt.Sprintf("/microservices/%s/routes/%s", uuid, destUUID), nil)
+	return clt.CreateMicroserviceRouteWithContext(context.Background(), uuid, destUUID)
+}
+
+// CreateMicroserviceRouteWithContext is CreateMicroserviceRoute with a context that can cancel the request or bound its deadline
+func (clt *Client) CreateMicroserviceRouteWithContext(ctx context.Context, uuid, destUUID string) (err error) {
+	ctx = withOperation(ctx, "CreateMicroserviceRoute")
+	_, err = clt.doRequest(ctx, "POST", fmt.Sprintf("/microservices/%s/routes/%s", uuid, destUUID), nil)
 	return
 }
 
 // DeleteMicroserviceRoute deletes a microservice route using Controller REST API
 func (clt *Client) DeleteMicroserviceRoute(uuid, destUUID string) (err error) {
-	_, err = clt.doRequest("DELETE", fmt.Sprintf("/microservices/%s/routes/%s", uuid, destUUID), nil)
+	return clt.DeleteMicroserviceRouteWithContext(context.Background(), uuid, destUUID)
+}
+
+// DeleteMicroserviceRouteWithContext is DeleteMicroserviceRoute with a context that can cancel the request or bound its deadline
+func (clt *Client) DeleteMicroserviceRouteWithContext(ctx context.Context, uuid, destUUID string) (err error) {
+	ctx = withOperation(ctx, "DeleteMicroserviceRoute")
+	_, err = clt.doRequest(ctx, "DELETE", fmt.Sprintf("/microservices/%s/routes/%s", uuid, destUUID), nil)
 	return
 }
 
 func (clt *Client) UpdateMicroserviceRoutes(uuid string, currentRoutes, newRoutes []string) (err error) {
+	return clt.UpdateMicroserviceRoutesWithContext(context.Background(), uuid, currentRoutes, newRoutes)
+}
+
+// UpdateMicroserviceRoutesWithContext is UpdateMicroserviceRoutes with a context that can cancel the request or bound its deadline
+func (clt *Client) UpdateMicroserviceRoutesWithContext(ctx context.Context, uuid string, currentRoutes, newRoutes []string) (err error) {
+	ctx = withOperation(ctx, "UpdateMicroserviceRoutes")
 	currentRouteMap := mapFromArray(currentRoutes)
 	newRouteMap := mapFromArray(newRoutes)
 
@@ -204,7 +259,7 @@ func (clt *Client) UpdateMicroserviceRoutes(uuid string, currentRoutes, newRoute
 	for _, currentRouteDest := range currentRoutes {
 		_, found := newRouteMap[currentRouteDest]
 		if !found {
-			if err = clt.DeleteMicroserviceRoute(uuid, currentRouteDest); err != nil {
+			if err = clt.DeleteMicroserviceRouteWithContext(ctx, uuid, currentRouteDest); err != nil {
 				return
 			}
 		}
@@ -214,7 +269,7 @@ func (clt *Client) UpdateMicroserviceRoutes(uuid string, currentRoutes, newRoute
 	for _, newRouteDest := range newRoutes {
 		_, found := currentRouteMap[newRouteDest]
 		if !found {
-			if err = clt.CreateMicroserviceRoute(uuid, newRouteDest); err != nil {
+			if err = clt.CreateMicroserviceRouteWithContext(ctx, uuid, newRouteDest); err != nil {
 				return
 			}
 		}
@@ -225,6 +280,12 @@ func (clt *Client) UpdateMicroserviceRoutes(uuid string, currentRoutes, newRoute
 // UpdateMicroserviceFromYAML updates a microservice using the Controller REST API
 // It sends the yaml file to Controller REST API
 func (clt *Client) UpdateMicroserviceFromYAML(uuid string, file io.Reader) (*MicroserviceInfo, error) {
+	return clt.UpdateMicroserviceFromYAMLWithContext(context.Background(), uuid, file)
+}
+
+// UpdateMicroserviceFromYAMLWithContext is UpdateMicroserviceFromYAML with a context that can cancel the request or bound its deadline
+func (clt *Client) UpdateMicroserviceFromYAMLWithContext(ctx context.Context, uuid string, file io.Reader) (*MicroserviceInfo, error) {
+	ctx = withOperation(ctx, "UpdateMicroserviceFromYAML")
 	requestBody := &bytes.Buffer{}
 	writer := multipart.NewWriter(requestBody)
 	part, _ := writer.CreateFormFile("microservice", "microservice.yaml")
@@ -238,15 +299,21 @@ func (clt *Client) UpdateMicroserviceFromYAML(uuid string, file io.Reader) (*Mic
 		"Content-Type": writer.FormDataContentType(),
 	}
 
-	_, err = clt.doRequestWithHeaders("PATCH", fmt.Sprintf("/microservices/yaml/%s", uuid), requestBody, headers)
+	_, err = clt.doRequestWithHeaders(ctx, "PATCH", fmt.Sprintf("/microservices/yaml/%s", uuid), requestBody, headers)
 	if err != nil {
 		return nil, err
 	}
-	return clt.GetMicroserviceByID(uuid)
+	return clt.GetMicroserviceByIDWithContext(ctx, uuid)
 }
 
 // DeleteMicroservice deletes a microservice using Controller REST API
 func (clt *Client) DeleteMicroservice(uuid string) (err error) {
-	_, err = clt.doRequest("DELETE", fmt.Sprintf("/microservices/%s", uuid), nil)
+	return clt.DeleteMicroserviceWithContext(context.Background(), uuid)
+}
+
+// DeleteMicroserviceWithContext is DeleteMicroservice with a context that can cancel the request or bound its deadline
+func (clt *Client) DeleteMicroserviceWithContext(ctx context.Context, uuid string) (err error) {
+	ctx = withOperation(ctx, "DeleteMicroservice")
+	_, err = clt.doRequest(ctx, "DELETE", fmt.Sprintf("/microservices/%s", uuid), nil)
 	return
 }
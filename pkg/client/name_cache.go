@@ -0,0 +1,113 @@
+/*
+ *  *******************************************************************************
+ *  * Copyright (c) 2019 Edgeworx, Inc.
+ *  *
+ *  * This program and the accompanying materials are made available under the
+ *  * terms of the Eclipse Public License v. 2.0 which is available at
+ *  * http://www.eclipse.org/legal/epl-2.0
+ *  *
+ *  * SPDX-License-Identifier: EPL-2.0
+ *  *******************************************************************************
+ *
+ */
+
+package client
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+type nameKind string
+
+const (
+	agentNames   nameKind = "agent"
+	catalogNames nameKind = "catalog item"
+	flowNames    nameKind = "flow"
+)
+
+type nameCacheEntry struct {
+	id      string
+	expires time.Time
+}
+
+// nameCache maps resource names to IDs so that name lookups can fetch a single resource instead of listing them all.
+// Entries expire after the TTL and are dropped whenever the client changes a resource of the same kind.
+type nameCache struct {
+	ttl     time.Duration
+	mu      sync.Mutex
+	entries map[string]nameCacheEntry
+}
+
+func newNameCache(ttl time.Duration) *nameCache {
+	return &nameCache{
+		ttl:     ttl,
+		entries: make(map[string]nameCacheEntry),
+	}
+}
+
+func nameCacheKey(kind nameKind, name string) string {
+	return string(kind) + "/" + name
+}
+
+func (cache *nameCache) get(kind nameKind, name string) (string, bool) {
+	if cache == nil {
+		return "", false
+	}
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	key := nameCacheKey(kind, name)
+	entry, exists := cache.entries[key]
+	if !exists {
+		return "", false
+	}
+	if time.Now().After(entry.expires) {
+		delete(cache.entries, key)
+		return "", false
+	}
+	return entry.id, true
+}
+
+func (cache *nameCache) set(kind nameKind, name, id string) {
+	if cache == nil {
+		return
+	}
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	cache.entries[nameCacheKey(kind, name)] = nameCacheEntry{
+		id:      id,
+		expires: time.Now().Add(cache.ttl),
+	}
+}
+
+func (cache *nameCache) remove(kind nameKind, name string) {
+	if cache == nil {
+		return
+	}
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	delete(cache.entries, nameCacheKey(kind, name))
+}
+
+// invalidate drops every entry of kind, renames and deletions make any of them stale
+func (cache *nameCache) invalidate(kind nameKind) {
+	if cache == nil {
+		return
+	}
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	prefix := string(kind) + "/"
+	for key := range cache.entries {
+		if strings.HasPrefix(key, prefix) {
+			delete(cache.entries, key)
+		}
+	}
+}
+
+// InvalidateNameCache drops every cached name to ID mapping, e.g. after resources were changed by another client
+func (clt *Client) InvalidateNameCache() {
+	for _, kind := range []nameKind{agentNames, catalogNames, flowNames} {
+		clt.names.invalidate(kind)
+	}
+}
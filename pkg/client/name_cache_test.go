@@ -0,0 +1,77 @@
+/*
+ *  *******************************************************************************
+ *  * Copyright (c) 2019 Edgeworx, Inc.
+ *  *
+ *  * This program and the accompanying materials are made available under the
+ *  * terms of the Eclipse Public License v. 2.0 which is available at
+ *  * http://www.eclipse.org/legal/epl-2.0
+ *  *
+ *  * SPDX-License-Identifier: EPL-2.0
+ *  *******************************************************************************
+ *
+ */
+
+package client_test
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/eclipse-iofog/iofog-go-sdk/v3/pkg/client"
+	"github.com/eclipse-iofog/iofog-go-sdk/v3/pkg/client/fake/faketest"
+)
+
+func TestGetAgentByNameCache(t *testing.T) {
+	ctrl, clt := faketest.NewLoggedIn(t, client.Options{NameCacheTTL: time.Minute})
+	created, err := clt.CreateAgent(&client.CreateAgentRequest{AgentUpdateRequest: client.AgentUpdateRequest{Name: "agent-1"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for idx := 0; idx < 3; idx++ {
+		agent, err := clt.GetAgentByName("agent-1", false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if agent.UUID != created.UUID {
+			t.Errorf("Expected agent %s, got %s", created.UUID, agent.UUID)
+		}
+	}
+	if count := ctrl.RequestCount("GET", "/iofog-list"); count != 1 {
+		t.Errorf("Expected 1 list request with a warm cache, got %d", count)
+	}
+
+	// Renaming invalidates the cache
+	if _, err := clt.UpdateAgent(&client.AgentUpdateRequest{UUID: created.UUID, Name: "agent-2"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := clt.GetAgentByName("agent-1", false); !errors.Is(err, client.ErrNotFound) {
+		t.Errorf("Expected renamed agent not to be found, got %v", err)
+	}
+	if count := ctrl.RequestCount("GET", "/iofog-list"); count != 2 {
+		t.Errorf("Expected the update to invalidate the cache, got %d list requests", count)
+	}
+}
+
+func TestGetAgentByNameAmbiguous(t *testing.T) {
+	ctrl, clt := faketest.NewLoggedIn(t, client.Options{})
+	ctrl.Intercept(func(w http.ResponseWriter, r *http.Request) bool {
+		if r.URL.Path != "/api/v3/iofog-list" {
+			return false
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"fogs":[{"uuid":"first","name":"agent"},{"uuid":"second","name":"agent"}]}`))
+		return true
+	})
+
+	_, err := clt.GetAgentByName("agent", false)
+	if !errors.Is(err, client.ErrAmbiguous) {
+		t.Fatalf("Expected an ambiguous name error, got %v", err)
+	}
+	var ambiguousErr *client.AmbiguousError
+	if !errors.As(err, &ambiguousErr) || len(ambiguousErr.IDs) != 2 {
+		t.Errorf("Expected both agents in the error, got %v", err)
+	}
+}
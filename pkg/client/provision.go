@@ -0,0 +1,111 @@
+/*
+ *  *******************************************************************************
+ *  * Copyright (c) 2019 Edgeworx, Inc.
+ *  *
+ *  * This program and the accompanying materials are made available under the
+ *  * terms of the Eclipse Public License v. 2.0 which is available at
+ *  * http://www.eclipse.org/legal/epl-2.0
+ *  *
+ *  * SPDX-License-Identifier: EPL-2.0
+ *  *******************************************************************************
+ *
+ */
+
+package client
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+const defaultMinKeyValidity = 5 * time.Minute
+
+// ProvisionRequest describes the Agent to provision with ProvisionAgent
+type ProvisionRequest struct {
+	// Name of the Agent, which is created when it does not exist yet
+	Name string
+	// Agent holds the settings used when the Agent is created, its Name is ignored
+	Agent AgentUpdateRequest
+	// Previous is the bundle of an earlier run. Its key is reused while it is valid for at least MinKeyValidity
+	Previous *ProvisionBundle
+	// MinKeyValidity is the time a key must remain valid to be reused, 5 minutes by default
+	MinKeyValidity time.Duration
+}
+
+func (request *ProvisionRequest) minKeyValidity() time.Duration {
+	if request.MinKeyValidity <= 0 {
+		return defaultMinKeyValidity
+	}
+	return request.MinKeyValidity
+}
+
+// ProvisionBundle holds everything an Agent needs to provision itself with the Controller
+type ProvisionBundle struct {
+	ControllerURL string    `json:"controllerUrl" yaml:"controllerUrl"`
+	AgentUUID     string    `json:"agentUuid" yaml:"agentUuid"`
+	AgentName     string    `json:"agentName" yaml:"agentName"`
+	Key           string    `json:"key" yaml:"key"`
+	ExpiresAt     time.Time `json:"expiresAt" yaml:"expiresAt"`
+	// Created is set when the Agent was created by this run
+	Created bool `json:"-" yaml:"-"`
+}
+
+// ValidFor reports whether the key is still valid after duration
+func (bundle *ProvisionBundle) ValidFor(duration time.Duration) bool {
+	return bundle.Key != "" && time.Now().Add(duration).Before(bundle.ExpiresAt)
+}
+
+// ProvisionAgent creates the Agent unless an Agent with the same name exists and returns a provisioning key for it.
+// Running it again for the same name reuses the Agent, and the key of request.Previous while it is not about to expire
+func (clt *Client) ProvisionAgent(ctx context.Context, request ProvisionRequest) (*ProvisionBundle, error) {
+	ctx = withOperation(ctx, "ProvisionAgent")
+	if request.Name == "" {
+		return nil, NewInputError("Agent name is required to provision an Agent")
+	}
+
+	bundle := &ProvisionBundle{
+		ControllerURL: clt.GetBaseURL(),
+		AgentName:     request.Name,
+	}
+	agent, err := clt.GetAgentByNameWithContext(ctx, request.Name, false)
+	switch {
+	case err == nil:
+		bundle.AgentUUID = agent.UUID
+	case errors.Is(err, ErrNotFound):
+		createRequest := CreateAgentRequest{AgentUpdateRequest: request.Agent}
+		createRequest.Name = request.Name
+		created, err := clt.CreateAgentWithContext(ctx, &createRequest)
+		if errors.Is(err, ErrConflict) {
+			// Another run created the Agent in the meantime
+			agent, err = clt.GetAgentByNameWithContext(ctx, request.Name, false)
+			if err != nil {
+				return nil, err
+			}
+			bundle.AgentUUID = agent.UUID
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		bundle.AgentUUID = created.UUID
+		bundle.Created = true
+	default:
+		return nil, err
+	}
+
+	// Reuse the previous key of the same Agent while it is valid
+	if previous := request.Previous; previous != nil && previous.AgentUUID == bundle.AgentUUID && previous.ValidFor(request.minKeyValidity()) {
+		bundle.Key = previous.Key
+		bundle.ExpiresAt = previous.ExpiresAt
+		return bundle, nil
+	}
+
+	key, err := clt.GetAgentProvisionKeyWithContext(ctx, bundle.AgentUUID)
+	if err != nil {
+		return nil, err
+	}
+	bundle.Key = key.Key
+	bundle.ExpiresAt = time.Unix(0, key.ExpireTimeMsUTC*int64(time.Millisecond))
+	return bundle, nil
+}
@@ -0,0 +1,61 @@
+/*
+ *  *******************************************************************************
+ *  * Copyright (c) 2019 Edgeworx, Inc.
+ *  *
+ *  * This program and the accompanying materials are made available under the
+ *  * terms of the Eclipse Public License v. 2.0 which is available at
+ *  * http://www.eclipse.org/legal/epl-2.0
+ *  *
+ *  * SPDX-License-Identifier: EPL-2.0
+ *  *******************************************************************************
+ *
+ */
+
+package client_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/eclipse-iofog/iofog-go-sdk/v3/pkg/client"
+	"github.com/eclipse-iofog/iofog-go-sdk/v3/pkg/client/fake/faketest"
+)
+
+func TestProvisionAgentIsIdempotent(t *testing.T) {
+	ctrl, clt := faketest.NewLoggedIn(t, client.Options{})
+	ctx := context.Background()
+
+	first, err := clt.ProvisionAgent(ctx, client.ProvisionRequest{Name: "edge-1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !first.Created || first.Key == "" || !first.ValidFor(time.Minute) {
+		t.Fatalf("Expected a new Agent with a valid key, got %+v", first)
+	}
+	keyPath := "/iofog/" + first.AgentUUID + "/provisioning-key"
+
+	// The previous key is still valid
+	second, err := clt.ProvisionAgent(ctx, client.ProvisionRequest{Name: "edge-1", Previous: first})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second.Created || second.AgentUUID != first.AgentUUID || second.Key != first.Key {
+		t.Errorf("Expected the Agent and key to be reused, got %+v", second)
+	}
+	if count := ctrl.RequestCount("GET", keyPath); count != 1 {
+		t.Errorf("Expected 1 provisioning key request, got %d", count)
+	}
+
+	// The previous key expires too soon
+	third, err := clt.ProvisionAgent(ctx, client.ProvisionRequest{Name: "edge-1", Previous: first, MinKeyValidity: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if third.AgentUUID != first.AgentUUID || third.Key == first.Key {
+		t.Errorf("Expected a new key for the same Agent, got %+v", third)
+	}
+	if count := ctrl.RequestCount("GET", keyPath); count != 2 {
+		t.Errorf("Expected 2 provisioning key requests, got %d", count)
+	}
+}
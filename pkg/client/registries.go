@@ -14,14 +14,21 @@
 package client
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 )
 
 // CreateRegistry creates a new registry using the Controller REST API
 func (clt *Client) CreateRegistry(request *RegistryCreateRequest) (int, error) {
+	return clt.CreateRegistryWithContext(context.Background(), request)
+}
+
+// CreateRegistryWithContext is CreateRegistry with a context that can cancel the request or bound its deadline
+func (clt *Client) CreateRegistryWithContext(ctx context.Context, request *RegistryCreateRequest) (int, error) {
+	ctx = withOperation(ctx, "CreateRegistry")
 	response := RegistryCreateResponse{}
-	body, err := clt.doRequest("POST", "/registries", request)
+	body, err := clt.doRequest(ctx, "POST", "/registries", request)
 	if err != nil {
 		return -1, err
 	}
@@ -33,7 +40,13 @@ func (clt *Client) CreateRegistry(request *RegistryCreateRequest) (int, error) {
 
 // UpdateRegistry patches a registry using the Controller REST API
 func (clt *Client) UpdateRegistry(request RegistryUpdateRequest) error {
-	_, err := clt.doRequest("PATCH", fmt.Sprintf("/registries/%d", request.ID), request)
+	return clt.UpdateRegistryWithContext(context.Background(), request)
+}
+
+// UpdateRegistryWithContext is UpdateRegistry with a context that can cancel the request or bound its deadline
+func (clt *Client) UpdateRegistryWithContext(ctx context.Context, request RegistryUpdateRequest) error {
+	ctx = withOperation(ctx, "UpdateRegistry")
+	_, err := clt.doRequest(ctx, "PATCH", fmt.Sprintf("/registries/%d", request.ID), request)
 	if err != nil {
 		return err
 	}
@@ -42,7 +55,13 @@ func (clt *Client) UpdateRegistry(request RegistryUpdateRequest) error {
 
 // ListRegistries retrieve all registries information from the Controller REST API
 func (clt *Client) ListRegistries() (response RegistryListResponse, err error) {
-	body, err := clt.doRequest("GET", "/registries", nil)
+	return clt.ListRegistriesWithContext(context.Background())
+}
+
+// ListRegistriesWithContext is ListRegistries with a context that can cancel the request or bound its deadline
+func (clt *Client) ListRegistriesWithContext(ctx context.Context) (response RegistryListResponse, err error) {
+	ctx = withOperation(ctx, "ListRegistries")
+	body, err := clt.doRequest(ctx, "GET", "/registries", nil)
 	if err != nil {
 		return
 	}
@@ -54,6 +73,12 @@ func (clt *Client) ListRegistries() (response RegistryListResponse, err error) {
 
 // DeleteRegistry deletes a registry using the Controller REST API
 func (clt *Client) DeleteRegistry(id int) (err error) {
-	_, err = clt.doRequest("DELETE", fmt.Sprintf("/registries/%d", id), nil)
+	return clt.DeleteRegistryWithContext(context.Background(), id)
+}
+
+// DeleteRegistryWithContext is DeleteRegistry with a context that can cancel the request or bound its deadline
+func (clt *Client) DeleteRegistryWithContext(ctx context.Context, id int) (err error) {
+	ctx = withOperation(ctx, "DeleteRegistry")
+	_, err = clt.doRequest(ctx, "DELETE", fmt.Sprintf("/registries/%d", id), nil)
 	return
 }
@@ -0,0 +1,176 @@
+/*
+ *  *******************************************************************************
+ *  * Copyright (c) 2019 Edgeworx, Inc.
+ *  *
+ *  * This program and the accompanying materials are made available under the
+ *  * terms of the Eclipse Public License v. 2.0 which is available at
+ *  * http://www.eclipse.org/legal/epl-2.0
+ *  *
+ *  * SPDX-License-Identifier: EPL-2.0
+ *  *******************************************************************************
+ *
+ */
+
+package client
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy configures exponential backoff with jitter for Controller requests.
+// Zero values are replaced by the values of DefaultRetryPolicy.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first request
+	MaxAttempts int
+	// InitialBackoff is the delay before the first retry
+	InitialBackoff time.Duration
+	// MaxBackoff caps the delay between two attempts, Retry-After headers excepted
+	MaxBackoff time.Duration
+	// Multiplier grows the delay after every attempt
+	Multiplier float64
+	// Jitter randomises each delay by up to this fraction of it, between 0 and 1
+	Jitter float64
+	// MaxElapsedTime stops retrying once this much time has passed since the first attempt, 0 means no limit
+	MaxElapsedTime time.Duration
+	// RetryableStatusCodes lists the HTTP status codes worth retrying
+	RetryableStatusCodes []int
+	// NoConnectionErrorRetries disables retries on connection failures and client timeouts
+	NoConnectionErrorRetries bool
+	// RetryNonIdempotent allows retrying POST and PATCH requests, which may have reached the Controller already
+	RetryNonIdempotent bool
+}
+
+// DefaultRetryPolicy returns the policy used for zero fields of a RetryPolicy
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:    4,
+		InitialBackoff: 500 * time.Millisecond,
+		MaxBackoff:     30 * time.Second,
+		Multiplier:     2,
+		Jitter:         0.2,
+		RetryableStatusCodes: []int{
+			http.StatusRequestTimeout,
+			http.StatusTooManyRequests,
+			http.StatusBadGateway,
+			http.StatusServiceUnavailable,
+			http.StatusGatewayTimeout,
+		},
+	}
+}
+
+func (policy RetryPolicy) withDefaults() RetryPolicy {
+	defaults := DefaultRetryPolicy()
+	if policy.MaxAttempts == 0 {
+		policy.MaxAttempts = defaults.MaxAttempts
+	}
+	if policy.InitialBackoff == 0 {
+		policy.InitialBackoff = defaults.InitialBackoff
+	}
+	if policy.MaxBackoff == 0 {
+		policy.MaxBackoff = defaults.MaxBackoff
+	}
+	if policy.Multiplier == 0 {
+		policy.Multiplier = defaults.Multiplier
+	}
+	if policy.RetryableStatusCodes == nil {
+		policy.RetryableStatusCodes = defaults.RetryableStatusCodes
+	}
+	return policy
+}
+
+// backoff returns the delay to wait before the given retry, starting at 1
+func (policy RetryPolicy) backoff(retry int) time.Duration {
+	delay := float64(policy.InitialBackoff) * math.Pow(policy.Multiplier, float64(retry-1))
+	if delay > float64(policy.MaxBackoff) {
+		delay = float64(policy.MaxBackoff)
+	}
+	if policy.Jitter > 0 {
+		delay += delay * policy.Jitter * (2*rand.Float64() - 1) //nolint:gosec
+	}
+	return time.Duration(delay)
+}
+
+// isRetryable reports whether the failed request may be sent again and how long the Controller asked to wait
+func (policy RetryPolicy) isRetryable(ctx context.Context, method string, err error) (retryAfter time.Duration, ok bool) {
+	// Never retry once the caller gave up
+	if ctx.Err() != nil {
+		return 0, false
+	}
+	if !policy.RetryNonIdempotent && !isIdempotent(method) {
+		return 0, false
+	}
+
+	if httpErr, isHTTPErr := err.(*HTTPError); isHTTPErr {
+		for _, code := range policy.RetryableStatusCodes {
+			if httpErr.Code == code {
+				return httpErr.retryAfter, true
+			}
+		}
+		return 0, false
+	}
+
+	var urlErr *url.Error
+	if !policy.NoConnectionErrorRetries && errors.As(err, &urlErr) {
+		return 0, true
+	}
+	return 0, false
+}
+
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// parseRetryAfter reads a Retry-After header given either in seconds or as an HTTP date
+func parseRetryAfter(header string) time.Duration {
+	if header == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(header); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if date, err := http.ParseTime(header); err == nil {
+		if delay := time.Until(date); delay > 0 {
+			return delay
+		}
+	}
+	return 0
+}
+
+func (clt *Client) doRequestWithPolicy(ctx context.Context, policy RetryPolicy, method, requestURL string, headers map[string]string, request interface{}) ([]byte, error) {
+	policy = policy.withDefaults()
+	start := time.Now()
+	for attempt := 1; ; attempt++ {
+		bytes, attemptCtx, err := clt.doAttempt(ctx, attempt, method, requestURL, headers, request)
+		if err == nil || attempt >= policy.MaxAttempts {
+			return bytes, err
+		}
+		retryAfter, retryable := policy.isRetryable(ctx, method, err)
+		if !retryable {
+			return bytes, err
+		}
+
+		// Honour the delay requested by the Controller over our own backoff
+		delay := policy.backoff(attempt)
+		if retryAfter > 0 {
+			delay = retryAfter
+		}
+		if policy.MaxElapsedTime > 0 && time.Since(start)+delay > policy.MaxElapsedTime {
+			return bytes, err
+		}
+		clt.notifyRetry(attemptCtx, attempt, method, requestURL, delay, err)
+		if err := sleepWithContext(ctx, delay); err != nil {
+			return nil, err
+		}
+	}
+}
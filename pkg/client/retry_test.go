@@ -0,0 +1,138 @@
+/*
+ *  *******************************************************************************
+ *  * Copyright (c) 2019 Edgeworx, Inc.
+ *  *
+ *  * This program and the accompanying materials are made available under the
+ *  * terms of the Eclipse Public License v. 2.0 which is available at
+ *  * http://www.eclipse.org/legal/epl-2.0
+ *  *
+ *  * SPDX-License-Identifier: EPL-2.0
+ *  *******************************************************************************
+ *
+ */
+
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newRetryTestClient(t *testing.T, handler http.HandlerFunc) *Client {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	baseURL, err := url.Parse(server.URL + "/api/v3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	clt := New(Options{BaseURL: baseURL})
+	clt.SetRetries(Retries{Policy: &RetryPolicy{InitialBackoff: time.Millisecond, MaxAttempts: 5}})
+	return clt
+}
+
+func TestRetryPolicyRetriesIdempotentRequests(t *testing.T) {
+	var attempts int32
+	clt := newRetryTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&attempts, 1) < 3 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(`{"status":"online"}`))
+	})
+	// Ignore the status probe sent by New
+	atomic.StoreInt32(&attempts, 0)
+
+	if _, err := clt.GetStatus(); err != nil {
+		t.Fatal(err)
+	}
+	if count := atomic.LoadInt32(&attempts); count != 3 {
+		t.Errorf("Expected 3 attempts, got %d", count)
+	}
+}
+
+func TestRetryPolicySkipsNonIdempotentRequests(t *testing.T) {
+	var attempts int32
+	clt := newRetryTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			atomic.AddInt32(&attempts, 1)
+		}
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+
+	if err := clt.RebootAgent("uuid"); err == nil {
+		t.Error("Expected reboot to fail")
+	}
+	if count := atomic.LoadInt32(&attempts); count != 1 {
+		t.Errorf("Expected a single POST attempt, got %d", count)
+	}
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	policy := RetryPolicy{InitialBackoff: time.Second, MaxBackoff: 5 * time.Second}.withDefaults()
+	policy.Jitter = 0
+	expected := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second}
+	for idx, delay := range expected {
+		if backoff := policy.backoff(idx + 1); backoff != delay {
+			t.Errorf("Retry %d: expected backoff %s, got %s", idx+1, delay, backoff)
+		}
+	}
+	if delay := parseRetryAfter("3"); delay != 3*time.Second {
+		t.Errorf("Failed to parse Retry-After seconds: %s", delay)
+	}
+}
+
+func TestRetryHookGetsAttemptContext(t *testing.T) {
+	var attempts int32
+	clt := newRetryTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&attempts, 1) < 3 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(`{"status":"online"}`))
+	})
+	atomic.StoreInt32(&attempts, 0)
+
+	type attemptKey struct{}
+	retried := []int{}
+	clt.hooks = []Hooks{{
+		OnRequest: func(ctx context.Context, info RequestInfo) context.Context {
+			return context.WithValue(ctx, attemptKey{}, info.Attempt)
+		},
+		OnRetry: func(ctx context.Context, info RequestInfo, _ time.Duration, _ error) {
+			if attempt, _ := ctx.Value(attemptKey{}).(int); attempt != info.Attempt {
+				t.Errorf("Expected the context of attempt %d, got attempt %d", info.Attempt, attempt)
+			}
+			retried = append(retried, info.Attempt)
+		},
+	}}
+
+	if _, err := clt.GetStatus(); err != nil {
+		t.Fatal(err)
+	}
+	if len(retried) != 2 {
+		t.Errorf("Expected 2 retries, got %v", retried)
+	}
+}
+
+func TestRetriesCloneKeepsEmptyStatusCodes(t *testing.T) {
+	retries := Retries{Policy: &RetryPolicy{RetryableStatusCodes: []int{}}}
+	if codes := retries.clone().Policy.RetryableStatusCodes; codes == nil || len(codes) != 0 {
+		t.Errorf("Expected an empty list of status codes, got %#v", codes)
+	}
+	retries.Policy.RetryableStatusCodes = []int{http.StatusServiceUnavailable}
+	clone := retries.clone()
+	clone.Policy.RetryableStatusCodes[0] = http.StatusBadGateway
+	if retries.Policy.RetryableStatusCodes[0] != http.StatusServiceUnavailable {
+		t.Error("Expected the clone not to share the status codes")
+	}
+	if codes := (Retries{Policy: &RetryPolicy{}}).clone().Policy.RetryableStatusCodes; codes != nil {
+		t.Errorf("Expected nil status codes to stay nil, got %#v", codes)
+	}
+}
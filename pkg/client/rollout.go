@@ -0,0 +1,323 @@
+/*
+ *  *******************************************************************************
+ *  * Copyright (c) 2019 Edgeworx, Inc.
+ *  *
+ *  * This program and the accompanying materials are made available under the
+ *  * terms of the Eclipse Public License v. 2.0 which is available at
+ *  * http://www.eclipse.org/legal/epl-2.0
+ *  *
+ *  * SPDX-License-Identifier: EPL-2.0
+ *  *******************************************************************************
+ *
+ */
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	defaultRolloutHealthTimeout = 10 * time.Minute
+	defaultRolloutPollInterval  = 5 * time.Second
+	// rolloutRollbackTimeout bounds a rollback, which still runs once the context of the rollout is done
+	rolloutRollbackTimeout = time.Minute
+)
+
+// RolloutOptions configures RollingUpgradeAgents. A nil *RolloutOptions uses the defaults
+type RolloutOptions struct {
+	// WaveSize is the number of Agents upgraded at the same time, 1 by default
+	WaveSize int
+	// FailureThreshold is the number of failed Agents tolerated before the rollout halts, 0 by default
+	FailureThreshold int
+	// TargetVersion is the version Agents must report after the upgrade.
+	// When empty, any version other than the one before the upgrade is accepted
+	TargetVersion string
+	// HealthTimeout bounds the wait for an upgraded Agent to report the new version and a running daemon, 10 minutes by default
+	HealthTimeout time.Duration
+	// PollInterval is the delay between two health queries, 5 seconds by default
+	PollInterval time.Duration
+	// DisableRollback leaves failed Agents and the Agents upgraded before a halt as they are
+	DisableRollback bool
+	// OnProgress is called after each Agent of a wave completes. It may be called concurrently
+	OnProgress func(result RolloutResult)
+}
+
+func (opts *RolloutOptions) waveSize() int {
+	if opts == nil || opts.WaveSize <= 0 {
+		return 1
+	}
+	return opts.WaveSize
+}
+
+func (opts *RolloutOptions) failureThreshold() int {
+	if opts == nil || opts.FailureThreshold < 0 {
+		return 0
+	}
+	return opts.FailureThreshold
+}
+
+func (opts *RolloutOptions) targetVersion() string {
+	if opts == nil {
+		return ""
+	}
+	return opts.TargetVersion
+}
+
+func (opts *RolloutOptions) healthTimeout() time.Duration {
+	if opts == nil || opts.HealthTimeout <= 0 {
+		return defaultRolloutHealthTimeout
+	}
+	return opts.HealthTimeout
+}
+
+func (opts *RolloutOptions) pollInterval() time.Duration {
+	if opts == nil || opts.PollInterval <= 0 {
+		return defaultRolloutPollInterval
+	}
+	return opts.PollInterval
+}
+
+func (opts *RolloutOptions) rollback() bool {
+	return opts == nil || !opts.DisableRollback
+}
+
+func (opts *RolloutOptions) progress(result RolloutResult) {
+	if opts != nil && opts.OnProgress != nil {
+		opts.OnProgress(result)
+	}
+}
+
+// RolloutResult is the outcome of the upgrade of one Agent
+type RolloutResult struct {
+	UUID string
+	Name string
+	// Wave is the index of the wave the Agent was upgraded in
+	Wave        int
+	FromVersion string
+	ToVersion   string
+	// Skipped is set when the Agent was not ready to upgrade
+	Skipped bool
+	Err     error
+	// RolledBack is set when a rollback was requested for the Agent, RollbackErr holds its failure
+	RolledBack  bool
+	RollbackErr error
+}
+
+// RolloutReport holds the result of each upgraded Agent, in upgrade order
+type RolloutReport struct {
+	Results []RolloutResult
+	// Halted is set when the failure threshold was exceeded or the context was done
+	Halted bool
+	// Pending lists the UUIDs of the Agents which were not attempted because the rollout halted
+	Pending []string
+}
+
+// Failed returns the results with an error
+func (report *RolloutReport) Failed() (results []RolloutResult) {
+	for _, result := range report.Results {
+		if result.Err != nil {
+			results = append(results, result)
+		}
+	}
+	return
+}
+
+// Skipped returns the results of the Agents which were not ready to upgrade
+func (report *RolloutReport) Skipped() (results []RolloutResult) {
+	for _, result := range report.Results {
+		if result.Skipped {
+			results = append(results, result)
+		}
+	}
+	return
+}
+
+// Err returns a *RolloutError when any Agent failed to upgrade or was skipped, or the rollout halted, nil otherwise
+func (report *RolloutReport) Err() error {
+	failed := report.Failed()
+	skipped := report.Skipped()
+	if len(failed) == 0 && len(skipped) == 0 && !report.Halted {
+		return nil
+	}
+	return &RolloutError{Halted: report.Halted, Failed: failed, Skipped: skipped, Pending: len(report.Pending)}
+}
+
+// RolloutError summarises the failures of a rolling upgrade
+type RolloutError struct {
+	Halted bool
+	Failed []RolloutResult
+	// Skipped holds the Agents which were not upgraded because they were not ready to upgrade
+	Skipped []RolloutResult
+	Pending int
+}
+
+// Error export
+func (err *RolloutError) Error() string {
+	msg := fmt.Sprintf("Rolling upgrade failed on %d Agents", len(err.Failed))
+	if err.Halted {
+		msg = fmt.Sprintf("Rolling upgrade halted after %d failed Agents, %d Agents were not attempted", len(err.Failed), err.Pending)
+	}
+	if len(err.Skipped) > 0 {
+		msg += fmt.Sprintf(", %d Agents were not ready to upgrade", len(err.Skipped))
+	}
+	for _, result := range err.Failed {
+		msg += fmt.Sprintf("\n%s: %s", result.Name, result.Err.Error())
+		if result.RollbackErr != nil {
+			msg += fmt.Sprintf(" (rollback failed: %s)", result.RollbackErr.Error())
+		}
+	}
+	for _, result := range err.Skipped {
+		msg += fmt.Sprintf("\n%s: not ready to upgrade", result.Name)
+	}
+	return msg
+}
+
+// RollingUpgradeAgents upgrades the selected Agents in waves.
+// After each upgrade the Agent must report the new version and a running daemon within the health timeout, otherwise it is rolled back.
+// When more Agents failed than the failure threshold, the rollout halts and the Agents it upgraded are rolled back too.
+// Rollbacks are also requested when ctx is done mid-rollout, each of them bounded by its own timeout.
+// The returned error is only set when the Agents could not be selected, see RolloutReport.Err for upgrade failures
+func (clt *Client) RollingUpgradeAgents(ctx context.Context, selector AgentSelector, opts *RolloutOptions) (*RolloutReport, error) {
+	ctx = withOperation(ctx, "RollingUpgradeAgents")
+	agents, selected, err := clt.selectAgents(ctx, selector)
+	if err != nil {
+		return nil, err
+	}
+	// Do not start a rollout on a partial selection
+	for _, result := range selected {
+		if result.Err != nil {
+			return nil, result.Err
+		}
+	}
+
+	report := &RolloutReport{}
+	failures := 0
+	waveSize := opts.waveSize()
+	for start, wave := 0, 0; start < len(agents); start, wave = start+waveSize, wave+1 {
+		end := start + waveSize
+		if end > len(agents) {
+			end = len(agents)
+		}
+		if ctx.Err() != nil || failures > opts.failureThreshold() {
+			report.Halted = true
+			for _, agent := range agents[start:] {
+				report.Pending = append(report.Pending, agent.UUID)
+			}
+			break
+		}
+
+		results := make([]RolloutResult, end-start)
+		wg := sync.WaitGroup{}
+		for idx, agent := range agents[start:end] {
+			wg.Add(1)
+			go func(idx int, agent *AgentInfo) {
+				defer wg.Done()
+				results[idx] = clt.upgradeAgentWithHealthCheck(ctx, agent, opts)
+				results[idx].Wave = wave
+				opts.progress(results[idx])
+			}(idx, agent)
+		}
+		wg.Wait()
+		for _, result := range results {
+			if result.Err != nil {
+				failures++
+			}
+		}
+		report.Results = append(report.Results, results...)
+	}
+
+	if (report.Halted || failures > opts.failureThreshold()) && opts.rollback() {
+		report.Halted = true
+		clt.rollbackUpgraded(ctx, report)
+	}
+	return report, nil
+}
+
+// upgradeAgentWithHealthCheck upgrades one Agent, waits for it to be healthy and rolls it back on failure
+func (clt *Client) upgradeAgentWithHealthCheck(ctx context.Context, agent *AgentInfo, opts *RolloutOptions) (result RolloutResult) {
+	result = RolloutResult{UUID: agent.UUID, Name: agent.Name, FromVersion: agent.Version}
+	if !agent.IsReadyToUpgrade {
+		result.Skipped = true
+		return
+	}
+	if result.Err = clt.changeAgentVersion(ctx, agent.UUID, "upgrade"); result.Err != nil {
+		return
+	}
+
+	healthCtx, cancel := context.WithTimeout(ctx, opts.healthTimeout())
+	defer cancel()
+	var current *AgentInfo
+	lastErr, err := poll(healthCtx, opts.pollInterval(), func() (bool, error) {
+		info, err := clt.GetAgentByIDWithContext(healthCtx, agent.UUID)
+		if err != nil {
+			return false, err
+		}
+		current = info
+		return isUpgraded(agent.Version, opts.targetVersion(), current), nil
+	})
+	if current != nil {
+		result.ToVersion = current.Version
+	}
+	if err != nil {
+		if healthCtx.Err() != nil {
+			waitErr := &WaitError{Resource: "agent " + agent.Name, LastErr: lastErr, err: healthCtx.Err()}
+			if current != nil {
+				waitErr.Status = fmt.Sprintf("%s on version %s", current.DaemonStatus, current.Version)
+			}
+			err = waitErr
+		}
+		result.Err = err
+		if opts.rollback() {
+			result.RolledBack = true
+			result.RollbackErr = clt.rollbackAgent(ctx, agent.UUID)
+		}
+	}
+	return
+}
+
+func isUpgraded(fromVersion, targetVersion string, agent *AgentInfo) bool {
+	if agent.DaemonStatus != AgentStatusRunning {
+		return false
+	}
+	if targetVersion != "" {
+		return agent.Version == targetVersion
+	}
+	return agent.Version != fromVersion
+}
+
+// rollbackUpgraded rolls back the Agents upgraded successfully before the rollout halted
+func (clt *Client) rollbackUpgraded(ctx context.Context, report *RolloutReport) {
+	for idx := range report.Results {
+		result := &report.Results[idx]
+		if result.Skipped || result.Err != nil || result.RolledBack {
+			continue
+		}
+		result.RolledBack = true
+		result.RollbackErr = clt.rollbackAgent(ctx, result.UUID)
+	}
+}
+
+// rollbackAgent requests the rollback of an Agent even when ctx is done, so that a cancelled rollout does not leave Agents half upgraded.
+// The values of ctx, such as WithRequestTimeout, still apply to the rollback
+func (clt *Client) rollbackAgent(ctx context.Context, uuid string) error {
+	rollbackCtx, cancel := context.WithTimeout(detachedContext{parent: ctx}, rolloutRollbackTimeout)
+	defer cancel()
+	return clt.changeAgentVersion(rollbackCtx, uuid, "rollback")
+}
+
+// detachedContext keeps the values of its parent but neither its deadline nor its cancellation
+type detachedContext struct {
+	parent context.Context
+}
+
+func (detachedContext) Deadline() (time.Time, bool) { return time.Time{}, false }
+
+func (detachedContext) Done() <-chan struct{} { return nil }
+
+func (detachedContext) Err() error { return nil }
+
+func (ctx detachedContext) Value(key interface{}) interface{} { return ctx.parent.Value(key) }
@@ -0,0 +1,233 @@
+/*
+ *  *******************************************************************************
+ *  * Copyright (c) 2019 Edgeworx, Inc.
+ *  *
+ *  * This program and the accompanying materials are made available under the
+ *  * terms of the Eclipse Public License v. 2.0 which is available at
+ *  * http://www.eclipse.org/legal/epl-2.0
+ *  *
+ *  * SPDX-License-Identifier: EPL-2.0
+ *  *******************************************************************************
+ *
+ */
+
+package client_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/eclipse-iofog/iofog-go-sdk/v3/pkg/client"
+	"github.com/eclipse-iofog/iofog-go-sdk/v3/pkg/client/fake"
+	"github.com/eclipse-iofog/iofog-go-sdk/v3/pkg/client/fake/faketest"
+)
+
+func TestRollingUpgradeAgentsHalts(t *testing.T) {
+	ctrl, clt := faketest.NewLoggedIn(t, client.Options{})
+	names := []string{"good-1", "bad", "good-2", "good-3"}
+	uuids := make(map[string]string)
+	for _, name := range names {
+		agent, err := clt.CreateAgent(&client.CreateAgentRequest{AgentUpdateRequest: client.AgentUpdateRequest{Name: name}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		uuids[name] = agent.UUID
+		_ = ctrl.UpdateAgent(agent.UUID, func(agent *client.AgentInfo) {
+			agent.Version = "3.0.0"
+			agent.IsReadyToUpgrade = true
+			agent.DaemonStatus = client.AgentStatusRunning
+		})
+	}
+
+	// Every Agent but bad comes back healthy on the new version
+	ctrl.Intercept(func(w http.ResponseWriter, r *http.Request) bool {
+		if !strings.HasSuffix(r.URL.Path, "/version/upgrade") {
+			return false
+		}
+		uuid := strings.Split(strings.TrimPrefix(r.URL.Path, "/api/v3/iofog/"), "/")[0]
+		_ = ctrl.UpdateAgent(uuid, func(agent *client.AgentInfo) {
+			if agent.Name == "bad" {
+				agent.DaemonStatus = "UNKNOWN"
+				return
+			}
+			agent.Version = "3.1.0"
+		})
+		return false
+	})
+
+	report, err := clt.RollingUpgradeAgents(context.Background(), client.AgentSelector{Names: names}, &client.RolloutOptions{
+		TargetVersion: "3.1.0",
+		HealthTimeout: 200 * time.Millisecond,
+		PollInterval:  10 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !report.Halted || len(report.Results) != 2 || len(report.Pending) != 2 {
+		t.Fatalf("Expected the rollout to halt after 2 Agents, got %+v", report)
+	}
+	var waitErr *client.WaitError
+	if failed := report.Failed(); len(failed) != 1 || failed[0].Name != "bad" || !errors.As(failed[0].Err, &waitErr) {
+		t.Errorf("Expected bad to fail its health check, got %v", failed)
+	}
+	expected := map[string][]string{
+		"good-1": {fake.UpgradeCommand, fake.RollbackCommand},
+		"bad":    {fake.UpgradeCommand, fake.RollbackCommand},
+		"good-2": {},
+		"good-3": {},
+	}
+	for name, commands := range expected {
+		if actual := ctrl.AgentCommands(uuids[name]); strings.Join(actual, ",") != strings.Join(commands, ",") {
+			t.Errorf("Expected %s to receive %v, got %v", name, commands, actual)
+		}
+	}
+	var rolloutErr *client.RolloutError
+	if !errors.As(report.Err(), &rolloutErr) || !rolloutErr.Halted {
+		t.Errorf("Expected a halted rollout error, got %v", report.Err())
+	}
+}
+
+func TestRollingUpgradeAgentsCancelled(t *testing.T) {
+	ctrl, clt := faketest.NewLoggedIn(t, client.Options{})
+	names := []string{"good", "slow", "pending"}
+	uuids := make(map[string]string)
+	for _, name := range names {
+		agent, err := clt.CreateAgent(&client.CreateAgentRequest{AgentUpdateRequest: client.AgentUpdateRequest{Name: name}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		uuids[name] = agent.UUID
+		_ = ctrl.UpdateAgent(agent.UUID, func(agent *client.AgentInfo) {
+			agent.Version = "3.0.0"
+			agent.IsReadyToUpgrade = true
+			agent.DaemonStatus = client.AgentStatusRunning
+		})
+	}
+
+	// The rollout is cancelled while slow is being upgraded
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ctrl.Intercept(func(w http.ResponseWriter, r *http.Request) bool {
+		if !strings.HasSuffix(r.URL.Path, "/version/upgrade") {
+			return false
+		}
+		uuid := strings.Split(strings.TrimPrefix(r.URL.Path, "/api/v3/iofog/"), "/")[0]
+		_ = ctrl.UpdateAgent(uuid, func(agent *client.AgentInfo) {
+			if agent.Name == "slow" {
+				time.AfterFunc(30*time.Millisecond, cancel)
+				return
+			}
+			agent.Version = "3.1.0"
+		})
+		return false
+	})
+
+	report, err := clt.RollingUpgradeAgents(ctx, client.AgentSelector{Names: names}, &client.RolloutOptions{
+		TargetVersion: "3.1.0",
+		HealthTimeout: 5 * time.Second,
+		PollInterval:  10 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !report.Halted || len(report.Results) != 2 || len(report.Pending) != 1 {
+		t.Fatalf("Expected the rollout to halt after 2 Agents, got %+v", report)
+	}
+	for _, result := range report.Results {
+		if !result.RolledBack || result.RollbackErr != nil {
+			t.Errorf("Expected %s to be rolled back, got %+v", result.Name, result)
+		}
+	}
+	expected := map[string][]string{
+		"good":    {fake.UpgradeCommand, fake.RollbackCommand},
+		"slow":    {fake.UpgradeCommand, fake.RollbackCommand},
+		"pending": {},
+	}
+	for name, commands := range expected {
+		if actual := ctrl.AgentCommands(uuids[name]); strings.Join(actual, ",") != strings.Join(commands, ",") {
+			t.Errorf("Expected %s to receive %v, got %v", name, commands, actual)
+		}
+	}
+}
+
+func TestRollingUpgradeAgentsSkipped(t *testing.T) {
+	ctrl, clt := faketest.NewLoggedIn(t, client.Options{})
+	names := []string{"ready", "busy"}
+	for _, name := range names {
+		agent, err := clt.CreateAgent(&client.CreateAgentRequest{AgentUpdateRequest: client.AgentUpdateRequest{Name: name}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		_ = ctrl.UpdateAgent(agent.UUID, func(agent *client.AgentInfo) {
+			agent.Version = "3.0.0"
+			agent.IsReadyToUpgrade = agent.Name == "ready"
+			agent.DaemonStatus = client.AgentStatusRunning
+		})
+	}
+	ctrl.Intercept(func(w http.ResponseWriter, r *http.Request) bool {
+		if strings.HasSuffix(r.URL.Path, "/version/upgrade") {
+			uuid := strings.Split(strings.TrimPrefix(r.URL.Path, "/api/v3/iofog/"), "/")[0]
+			_ = ctrl.UpdateAgent(uuid, func(agent *client.AgentInfo) { agent.Version = "3.1.0" })
+		}
+		return false
+	})
+
+	report, err := clt.RollingUpgradeAgents(context.Background(), client.AgentSelector{Names: names}, &client.RolloutOptions{
+		WaveSize:     2,
+		PollInterval: 10 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(report.Failed()) != 0 || report.Halted {
+		t.Fatalf("Expected no failure, got %+v", report)
+	}
+	var rolloutErr *client.RolloutError
+	if !errors.As(report.Err(), &rolloutErr) || len(rolloutErr.Skipped) != 1 || rolloutErr.Skipped[0].Name != "busy" {
+		t.Errorf("Expected busy to be reported as skipped, got %v", report.Err())
+	}
+}
+
+func TestRollingUpgradeAgentsRollbackKeepsContextValues(t *testing.T) {
+	ctrl, clt := faketest.NewLoggedIn(t, client.Options{})
+	agent, err := clt.CreateAgent(&client.CreateAgentRequest{AgentUpdateRequest: client.AgentUpdateRequest{Name: "bad"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = ctrl.UpdateAgent(agent.UUID, func(agent *client.AgentInfo) {
+		agent.Version = "3.0.0"
+		agent.IsReadyToUpgrade = true
+		agent.DaemonStatus = client.AgentStatusRunning
+	})
+	// The Agent never reports the new version and the rollback is slower than the request timeout
+	ctrl.Intercept(func(w http.ResponseWriter, r *http.Request) bool {
+		if strings.HasSuffix(r.URL.Path, "/version/rollback") {
+			time.Sleep(200 * time.Millisecond)
+		}
+		return false
+	})
+
+	ctx := client.WithRequestTimeout(context.Background(), 50*time.Millisecond)
+	report, err := clt.RollingUpgradeAgents(ctx, client.AgentSelector{Names: []string{"bad"}}, &client.RolloutOptions{
+		TargetVersion: "3.1.0",
+		HealthTimeout: 100 * time.Millisecond,
+		PollInterval:  10 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(report.Results) != 1 || !report.Results[0].RolledBack {
+		t.Fatalf("Expected bad to be rolled back, got %+v", report)
+	}
+	if err := report.Results[0].RollbackErr; !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected the request timeout to bound the rollback, got %v", err)
+	}
+}
@@ -13,17 +13,32 @@
 
 package client
 
-import "encoding/json"
+import (
+	"context"
+	"encoding/json"
+)
 
 func (clt *Client) PutDefaultRouter(router Router) (err error) {
+	return clt.PutDefaultRouterWithContext(context.Background(), router)
+}
+
+// PutDefaultRouterWithContext is PutDefaultRouter with a context that can cancel the request or bound its deadline
+func (clt *Client) PutDefaultRouterWithContext(ctx context.Context, router Router) (err error) {
+	ctx = withOperation(ctx, "PutDefaultRouter")
 	// Send request
-	_, err = clt.doRequest("PUT", "/router", router)
+	_, err = clt.doRequest(ctx, "PUT", "/router", router)
 	return err
 }
 
 func (clt *Client) GetDefaultRouter() (router Router, err error) {
+	return clt.GetDefaultRouterWithContext(context.Background())
+}
+
+// GetDefaultRouterWithContext is GetDefaultRouter with a context that can cancel the request or bound its deadline
+func (clt *Client) GetDefaultRouterWithContext(ctx context.Context) (router Router, err error) {
+	ctx = withOperation(ctx, "GetDefaultRouter")
 	// Send request
-	body, err := clt.doRequest("GET", "/router", nil)
+	body, err := clt.doRequest(ctx, "GET", "/router", nil)
 	if err != nil {
 		return
 	}
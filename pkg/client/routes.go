@@ -14,17 +14,24 @@
 package client
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 )
 
 func (clt *Client) ListRoutes() (response RouteListResponse, err error) {
+	return clt.ListRoutesWithContext(context.Background())
+}
+
+// ListRoutesWithContext is ListRoutes with a context that can cancel the request or bound its deadline
+func (clt *Client) ListRoutesWithContext(ctx context.Context) (response RouteListResponse, err error) {
+	ctx = withOperation(ctx, "ListRoutes")
 	if !clt.isLoggedIn() {
 		err = NewError("Controller client must be logged into perform List Routes request")
 		return
 	}
 
-	body, err := clt.doRequest("GET", "/routes", nil)
+	body, err := clt.doRequest(ctx, "GET", "/routes", nil)
 	if err != nil {
 		return
 	}
@@ -34,13 +41,19 @@ func (clt *Client) ListRoutes() (response RouteListResponse, err error) {
 }
 
 func (clt *Client) GetRoute(appName, name string) (route Route, err error) {
+	return clt.GetRouteWithContext(context.Background(), appName, name)
+}
+
+// GetRouteWithContext is GetRoute with a context that can cancel the request or bound its deadline
+func (clt *Client) GetRouteWithContext(ctx context.Context, appName, name string) (route Route, err error) {
+	ctx = withOperation(ctx, "GetRoute")
 	if !clt.isLoggedIn() {
 		err = NewError("Controller client must be logged into perform Get Route request")
 		return
 	}
 
 	// Send request
-	body, err := clt.doRequest("GET", fmt.Sprintf("/routes/%s/%s", appName, name), nil)
+	body, err := clt.doRequest(ctx, "GET", fmt.Sprintf("/routes/%s/%s", appName, name), nil)
 	if err != nil {
 		return
 	}
@@ -54,13 +67,19 @@ func (clt *Client) GetRoute(appName, name string) (route Route, err error) {
 }
 
 func (clt *Client) CreateRoute(route *Route) (err error) {
+	return clt.CreateRouteWithContext(context.Background(), route)
+}
+
+// CreateRouteWithContext is CreateRoute with a context that can cancel the request or bound its deadline
+func (clt *Client) CreateRouteWithContext(ctx context.Context, route *Route) (err error) {
+	ctx = withOperation(ctx, "CreateRoute")
 	if !clt.isLoggedIn() {
 		err = NewError("Controller client must be logged into perform Create Route request")
 		return
 	}
 
 	// Send request
-	if _, err = clt.doRequest("POST", "/routes", route); err != nil {
+	if _, err = clt.doRequest(ctx, "POST", "/routes", route); err != nil {
 		return
 	}
 
@@ -68,26 +87,38 @@ func (clt *Client) CreateRoute(route *Route) (err error) {
 }
 
 func (clt *Client) UpdateRoute(route *Route) (err error) {
+	return clt.UpdateRouteWithContext(context.Background(), route)
+}
+
+// UpdateRouteWithContext is UpdateRoute with a context that can cancel the request or bound its deadline
+func (clt *Client) UpdateRouteWithContext(ctx context.Context, route *Route) (err error) {
+	ctx = withOperation(ctx, "UpdateRoute")
 	if !clt.isLoggedIn() {
 		err = NewError("Controller client must be logged into perform Update Route request")
 		return
 	}
 
-	if _, err = clt.GetRoute(route.Application, route.Name); err == nil {
-		return clt.PatchRoute(route.Application, route.Name, route)
+	if _, err = clt.GetRouteWithContext(ctx, route.Application, route.Name); err == nil {
+		return clt.PatchRouteWithContext(ctx, route.Application, route.Name, route)
 	}
 
-	return clt.CreateRoute(route)
+	return clt.CreateRouteWithContext(ctx, route)
 }
 
 func (clt *Client) PatchRoute(appName, name string, route *Route) (err error) {
+	return clt.PatchRouteWithContext(context.Background(), appName, name, route)
+}
+
+// PatchRouteWithContext is PatchRoute with a context that can cancel the request or bound its deadline
+func (clt *Client) PatchRouteWithContext(ctx context.Context, appName, name string, route *Route) (err error) {
+	ctx = withOperation(ctx, "PatchRoute")
 	if !clt.isLoggedIn() {
 		err = NewError("Controller client must be logged into perform Update Route request")
 		return
 	}
 
 	// Send request
-	if _, err = clt.doRequest("PATCH", fmt.Sprintf("/routes/%s/%s", appName, name), &route); err != nil {
+	if _, err = clt.doRequest(ctx, "PATCH", fmt.Sprintf("/routes/%s/%s", appName, name), &route); err != nil {
 		return
 	}
 
@@ -95,13 +126,19 @@ func (clt *Client) PatchRoute(appName, name string, route *Route) (err error) {
 }
 
 func (clt *Client) DeleteRoute(appName, name string) (err error) {
+	return clt.DeleteRouteWithContext(context.Background(), appName, name)
+}
+
+// DeleteRouteWithContext is DeleteRoute with a context that can cancel the request or bound its deadline
+func (clt *Client) DeleteRouteWithContext(ctx context.Context, appName, name string) (err error) {
+	ctx = withOperation(ctx, "DeleteRoute")
 	if !clt.isLoggedIn() {
 		err = NewError("Controller client must be logged into perform Delete Route request")
 		return
 	}
 
 	// Send request
-	if _, err = clt.doRequest("DELETE", fmt.Sprintf("/routes/%s/%s", appName, name), nil); err != nil {
+	if _, err = clt.doRequest(ctx, "DELETE", fmt.Sprintf("/routes/%s/%s", appName, name), nil); err != nil {
 		return
 	}
 
@@ -15,6 +15,7 @@ package client
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -22,19 +23,18 @@ import (
 )
 
 func (clt *Client) IsApplicationTemplateCapable() error {
-	if _, err := clt.doRequest("HEAD", "/capabilities/applicationTemplates", nil); err != nil {
-		// If 404, not capable
-		if _, ok := err.(*NotFoundError); ok {
-			return NewNotSupportedError("Application Templates")
-		}
-		return err
-	}
-	return nil
+	return clt.IsApplicationTemplateCapableWithContext(context.Background())
+}
+
+// IsApplicationTemplateCapableWithContext is IsApplicationTemplateCapable with a context that can cancel the request or bound its deadline
+func (clt *Client) IsApplicationTemplateCapableWithContext(ctx context.Context) error {
+	ctx = withOperation(ctx, "IsApplicationTemplateCapable")
+	return clt.requireFeature(ctx, FeatureApplicationTemplates, "Application Templates")
 }
 
-func (clt *Client) applicationTemplatePreflight() error {
+func (clt *Client) applicationTemplatePreflight(ctx context.Context) error {
 	// Check capability
-	if err := clt.IsApplicationTemplateCapable(); err != nil {
+	if err := clt.IsApplicationTemplateCapableWithContext(ctx); err != nil {
 		return err
 	}
 
@@ -48,6 +48,12 @@ func (clt *Client) applicationTemplatePreflight() error {
 // CreateApplicationTemplateFromYAML creates a new application template using the Controller REST API
 // It sends the yaml file to Controller REST API
 func (clt *Client) CreateApplicationTemplateFromYAML(file io.Reader) (*ApplicationTemplate, error) {
+	return clt.CreateApplicationTemplateFromYAMLWithContext(context.Background(), file)
+}
+
+// CreateApplicationTemplateFromYAMLWithContext is CreateApplicationTemplateFromYAML with a context that can cancel the request or bound its deadline
+func (clt *Client) CreateApplicationTemplateFromYAMLWithContext(ctx context.Context, file io.Reader) (*ApplicationTemplate, error) {
+	ctx = withOperation(ctx, "CreateApplicationTemplateFromYAML")
 	requestBody := &bytes.Buffer{}
 	writer := multipart.NewWriter(requestBody)
 	part, _ := writer.CreateFormFile("template", "application.yaml")
@@ -60,7 +66,7 @@ func (clt *Client) CreateApplicationTemplateFromYAML(file io.Reader) (*Applicati
 	headers := map[string]string{
 		"Content-Type": writer.FormDataContentType(),
 	}
-	body, err := clt.doRequestWithHeaders("POST", "/applicationTemplate/yaml", requestBody, headers)
+	body, err := clt.doRequestWithHeaders(ctx, "POST", "/applicationTemplate/yaml", requestBody, headers)
 
 	if err != nil {
 		return nil, err
@@ -69,12 +75,18 @@ func (clt *Client) CreateApplicationTemplateFromYAML(file io.Reader) (*Applicati
 	if err := json.Unmarshal(body, &response); err != nil {
 		return nil, err
 	}
-	return clt.GetApplicationTemplate(response.Name)
+	return clt.GetApplicationTemplateWithContext(ctx, response.Name)
 }
 
 // UpdateApplicationTemplateFromYAML updates an application template using the Controller REST API
 // It sends the yaml file to Controller REST API
 func (clt *Client) UpdateApplicationTemplateFromYAML(name string, file io.Reader) (*ApplicationTemplate, error) {
+	return clt.UpdateApplicationTemplateFromYAMLWithContext(context.Background(), name, file)
+}
+
+// UpdateApplicationTemplateFromYAMLWithContext is UpdateApplicationTemplateFromYAML with a context that can cancel the request or bound its deadline
+func (clt *Client) UpdateApplicationTemplateFromYAMLWithContext(ctx context.Context, name string, file io.Reader) (*ApplicationTemplate, error) {
+	ctx = withOperation(ctx, "UpdateApplicationTemplateFromYAML")
 	requestBody := &bytes.Buffer{}
 	writer := multipart.NewWriter(requestBody)
 	part, _ := writer.CreateFormFile("template", "microservice.yaml")
@@ -88,34 +100,46 @@ func (clt *Client) UpdateApplicationTemplateFromYAML(name string, file io.Reader
 		"Content-Type": writer.FormDataContentType(),
 	}
 
-	_, err = clt.doRequestWithHeaders("PUT", fmt.Sprintf("/applicationTemplate/yaml/%s", name), requestBody, headers)
+	_, err = clt.doRequestWithHeaders(ctx, "PUT", fmt.Sprintf("/applicationTemplate/yaml/%s", name), requestBody, headers)
 	if err != nil {
 		return nil, err
 	}
-	return clt.GetApplicationTemplate(name)
+	return clt.GetApplicationTemplateWithContext(ctx, name)
 }
 
 func (clt *Client) UpdateApplicationTemplateMetadata(name string, newMeta *ApplicationTemplateMetadataUpdateRequest) error {
-	if err := clt.applicationTemplatePreflight(); err != nil {
+	return clt.UpdateApplicationTemplateMetadataWithContext(context.Background(), name, newMeta)
+}
+
+// UpdateApplicationTemplateMetadataWithContext is UpdateApplicationTemplateMetadata with a context that can cancel the request or bound its deadline
+func (clt *Client) UpdateApplicationTemplateMetadataWithContext(ctx context.Context, name string, newMeta *ApplicationTemplateMetadataUpdateRequest) error {
+	ctx = withOperation(ctx, "UpdateApplicationTemplateMetadata")
+	if err := clt.applicationTemplatePreflight(ctx); err != nil {
 		return err
 	}
 
 	// Run request
 	url := fmt.Sprintf("/applicationTemplate/%s", name)
-	if _, err := clt.doRequest("PATCH", url, newMeta); err != nil {
+	if _, err := clt.doRequest(ctx, "PATCH", url, newMeta); err != nil {
 		return err
 	}
 	return nil
 }
 
 func (clt *Client) ListApplicationTemplates() (*ApplicationTemplateListResponse, error) {
-	if err := clt.applicationTemplatePreflight(); err != nil {
+	return clt.ListApplicationTemplatesWithContext(context.Background())
+}
+
+// ListApplicationTemplatesWithContext is ListApplicationTemplates with a context that can cancel the request or bound its deadline
+func (clt *Client) ListApplicationTemplatesWithContext(ctx context.Context) (*ApplicationTemplateListResponse, error) {
+	ctx = withOperation(ctx, "ListApplicationTemplates")
+	if err := clt.applicationTemplatePreflight(ctx); err != nil {
 		return nil, err
 	}
 
 	// Run request
 	response := ApplicationTemplateListResponse{}
-	body, err := clt.doRequest("GET", "/applicationTemplates", nil)
+	body, err := clt.doRequest(ctx, "GET", "/applicationTemplates", nil)
 	if err != nil {
 		return nil, err
 	}
@@ -126,14 +150,20 @@ func (clt *Client) ListApplicationTemplates() (*ApplicationTemplateListResponse,
 }
 
 func (clt *Client) GetApplicationTemplate(name string) (*ApplicationTemplate, error) {
-	if err := clt.applicationTemplatePreflight(); err != nil {
+	return clt.GetApplicationTemplateWithContext(context.Background(), name)
+}
+
+// GetApplicationTemplateWithContext is GetApplicationTemplate with a context that can cancel the request or bound its deadline
+func (clt *Client) GetApplicationTemplateWithContext(ctx context.Context, name string) (*ApplicationTemplate, error) {
+	ctx = withOperation(ctx, "GetApplicationTemplate")
+	if err := clt.applicationTemplatePreflight(ctx); err != nil {
 		return nil, err
 	}
 
 	// Run request
 	response := ApplicationTemplate{}
 	url := fmt.Sprintf("/applicationTemplate/%s", name)
-	body, err := clt.doRequest("GET", url, nil)
+	body, err := clt.doRequest(ctx, "GET", url, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -144,13 +174,19 @@ func (clt *Client) GetApplicationTemplate(name string) (*ApplicationTemplate, er
 }
 
 func (clt *Client) DeleteApplicationTemplate(name string) error {
-	if err := clt.applicationTemplatePreflight(); err != nil {
+	return clt.DeleteApplicationTemplateWithContext(context.Background(), name)
+}
+
+// DeleteApplicationTemplateWithContext is DeleteApplicationTemplate with a context that can cancel the request or bound its deadline
+func (clt *Client) DeleteApplicationTemplateWithContext(ctx context.Context, name string) error {
+	ctx = withOperation(ctx, "DeleteApplicationTemplate")
+	if err := clt.applicationTemplatePreflight(ctx); err != nil {
 		return err
 	}
 
 	// Run request
 	url := fmt.Sprintf("/applicationTemplate/%s", name)
-	if _, err := clt.doRequest("DELETE", url, nil); err != nil {
+	if _, err := clt.doRequest(ctx, "DELETE", url, nil); err != nil {
 		return err
 	}
 	return nil
@@ -0,0 +1,58 @@
+/*
+ *  *******************************************************************************
+ *  * Copyright (c) 2019 Edgeworx, Inc.
+ *  *
+ *  * This program and the accompanying materials are made available under the
+ *  * terms of the Eclipse Public License v. 2.0 which is available at
+ *  * http://www.eclipse.org/legal/epl-2.0
+ *  *
+ *  * SPDX-License-Identifier: EPL-2.0
+ *  *******************************************************************************
+ *
+ */
+
+package client
+
+import (
+	"context"
+	"time"
+)
+
+const defaultRequestTimeout = 5 * time.Second
+
+type requestTimeoutKey struct{}
+
+// WithRequestTimeout bounds each request sent with ctx to timeout, overriding the timeouts of Options.
+// Unlike a context deadline, which bounds the whole call including retries, the timeout applies to every attempt
+func WithRequestTimeout(ctx context.Context, timeout time.Duration) context.Context {
+	return context.WithValue(ctx, requestTimeoutKey{}, timeout)
+}
+
+// requestTimeout returns the timeout of one attempt, from the most to the least specific setting:
+// WithRequestTimeout, Options.OperationTimeouts, Options.RequestTimeout and Options.Timeout.
+// OperationTimeouts are looked up for each nested operation from the innermost outwards, then for the operation reported to Hooks
+func (clt *Client) requestTimeout(ctx context.Context) time.Duration {
+	if timeout, ok := ctx.Value(requestTimeoutKey{}).(time.Duration); ok && timeout > 0 {
+		return timeout
+	}
+	chain := operationChain(ctx)
+	for idx := len(chain) - 1; idx >= 0; idx-- {
+		if timeout, ok := clt.operationTimeouts[chain[idx]]; ok && timeout > 0 {
+			return timeout
+		}
+	}
+	if timeout, ok := clt.operationTimeouts[OperationFromContext(ctx)]; ok && timeout > 0 {
+		return timeout
+	}
+	return clt.timeout
+}
+
+func newRequestTimeout(opt Options) time.Duration {
+	switch {
+	case opt.RequestTimeout > 0:
+		return opt.RequestTimeout
+	case opt.Timeout > 0:
+		return time.Duration(opt.Timeout) * time.Second
+	}
+	return defaultRequestTimeout
+}
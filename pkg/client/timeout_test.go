@@ -0,0 +1,78 @@
+/*
+ *  *******************************************************************************
+ *  * Copyright (c) 2019 Edgeworx, Inc.
+ *  *
+ *  * This program and the accompanying materials are made available under the
+ *  * terms of the Eclipse Public License v. 2.0 which is available at
+ *  * http://www.eclipse.org/legal/epl-2.0
+ *  *
+ *  * SPDX-License-Identifier: EPL-2.0
+ *  *******************************************************************************
+ *
+ */
+
+package client_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/eclipse-iofog/iofog-go-sdk/v3/pkg/client"
+	"github.com/eclipse-iofog/iofog-go-sdk/v3/pkg/client/fake/faketest"
+)
+
+func TestRequestTimeoutOverrides(t *testing.T) {
+	ctrl, clt := faketest.NewLoggedIn(t, client.Options{
+		SkipStatusProbe:   true,
+		RequestTimeout:    50 * time.Millisecond,
+		OperationTimeouts: map[string]time.Duration{"GetStatus": time.Second},
+	})
+	ctrl.Intercept(func(w http.ResponseWriter, r *http.Request) bool {
+		time.Sleep(150 * time.Millisecond)
+		return false
+	})
+
+	if _, err := clt.ListAgents(client.ListAgentsRequest{}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected ListAgents to time out, got %v", err)
+	}
+	if _, err := clt.GetStatus(); err != nil {
+		t.Errorf("Expected the GetStatus timeout to apply, got %v", err)
+	}
+	// The status probe sent by Supports is bounded by the GetStatus timeout
+	clt.ResetCapabilities()
+	if _, err := clt.Supports(client.FeatureListAllMicroservices); err != nil {
+		t.Errorf("Expected the GetStatus timeout to apply within Supports, got %v", err)
+	}
+	ctx := client.WithRequestTimeout(context.Background(), time.Second)
+	if _, err := clt.ListAgentsWithContext(ctx, client.ListAgentsRequest{}); err != nil {
+		t.Errorf("Expected the context timeout to apply, got %v", err)
+	}
+}
+
+func TestRequestTimeoutNestedOperations(t *testing.T) {
+	ctrl, clt := faketest.NewLoggedIn(t, client.Options{
+		SkipStatusProbe:   true,
+		RequestTimeout:    50 * time.Millisecond,
+		OperationTimeouts: map[string]time.Duration{"GetAgentByName": time.Second},
+	})
+	if _, err := clt.CreateAgent(&client.CreateAgentRequest{AgentUpdateRequest: client.AgentUpdateRequest{Name: "edge"}}); err != nil {
+		t.Fatal(err)
+	}
+	ctrl.Intercept(func(w http.ResponseWriter, r *http.Request) bool {
+		if r.URL.Path == "/api/v3/iofog-list" {
+			time.Sleep(150 * time.Millisecond)
+		}
+		return false
+	})
+
+	if _, err := clt.ListAgents(client.ListAgentsRequest{}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected ListAgents to time out, got %v", err)
+	}
+	// UpgradeAgent lists Agents through GetAgentByName, whose timeout applies to the list
+	if err := clt.UpgradeAgent("edge"); err != nil {
+		t.Errorf("Expected the GetAgentByName timeout to apply within UpgradeAgent, got %v", err)
+	}
+}
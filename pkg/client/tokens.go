@@ -0,0 +1,84 @@
+/*
+ *  *******************************************************************************
+ *  * Copyright (c) 2019 Edgeworx, Inc.
+ *  *
+ *  * This program and the accompanying materials are made available under the
+ *  * terms of the Eclipse Public License v. 2.0 which is available at
+ *  * http://www.eclipse.org/legal/epl-2.0
+ *  *
+ *  * SPDX-License-Identifier: EPL-2.0
+ *  *******************************************************************************
+ *
+ */
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// TokenSource supplies a long-lived access token, e.g. provisioned by an operator, used instead of logging in with an email and password.
+// It is queried before every request, so that rotated tokens are picked up without restarting
+type TokenSource interface {
+	Token(ctx context.Context) (string, error)
+}
+
+// StaticToken is a TokenSource always returning the same token
+type StaticToken string
+
+// Token export
+func (token StaticToken) Token(context.Context) (string, error) {
+	return string(token), nil
+}
+
+// EnvTokenSource reads the token from the environment variable name on every request
+func EnvTokenSource(name string) TokenSource {
+	return envTokenSource(name)
+}
+
+type envTokenSource string
+
+func (name envTokenSource) Token(context.Context) (string, error) {
+	token := strings.TrimSpace(os.Getenv(string(name)))
+	if token == "" {
+		return "", NewInputError(fmt.Sprintf("Environment variable %s does not hold an access token", string(name)))
+	}
+	return token, nil
+}
+
+// FileTokenSource reads the token from the file at path on every request, e.g. a mounted Kubernetes secret
+func FileTokenSource(path string) TokenSource {
+	return fileTokenSource(path)
+}
+
+type fileTokenSource string
+
+func (path fileTokenSource) Token(context.Context) (string, error) {
+	content, err := os.ReadFile(string(path))
+	if err != nil {
+		return "", err
+	}
+	token := strings.TrimSpace(string(content))
+	if token == "" {
+		return "", NewInputError(fmt.Sprintf("File %s does not hold an access token", string(path)))
+	}
+	return token, nil
+}
+
+// refreshToken replaces the access token with the one of the TokenSource, if any, and returns the token to send
+func (clt *Client) refreshToken(ctx context.Context) (string, error) {
+	if clt.tokenSource == nil {
+		return clt.GetAccessToken(), nil
+	}
+	token, err := clt.tokenSource.Token(ctx)
+	if err != nil {
+		return "", err
+	}
+	if token != clt.GetAccessToken() {
+		clt.SetAccessToken(token)
+	}
+	return token, nil
+}
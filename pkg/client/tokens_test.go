@@ -0,0 +1,85 @@
+/*
+ *  *******************************************************************************
+ *  * Copyright (c) 2019 Edgeworx, Inc.
+ *  *
+ *  * This program and the accompanying materials are made available under the
+ *  * terms of the Eclipse Public License v. 2.0 which is available at
+ *  * http://www.eclipse.org/legal/epl-2.0
+ *  *
+ *  * SPDX-License-Identifier: EPL-2.0
+ *  *******************************************************************************
+ *
+ */
+
+package client_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/eclipse-iofog/iofog-go-sdk/v3/pkg/client"
+	"github.com/eclipse-iofog/iofog-go-sdk/v3/pkg/client/fake"
+)
+
+func TestFileTokenSourceRotation(t *testing.T) {
+	ctrl := fake.New()
+	defer ctrl.Close()
+	ctrl.AddUser(fake.UserEmail, fake.UserPassword)
+	first, err := ctrl.IssueToken(fake.UserEmail)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(path, []byte(first+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	clt := client.New(client.Options{BaseURL: ctrl.URL(), TokenSource: client.FileTokenSource(path)})
+	if _, err := clt.CreateAgent(&client.CreateAgentRequest{AgentUpdateRequest: client.AgentUpdateRequest{Name: "agent-1"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Rotate the token without recreating the client
+	second, err := ctrl.IssueToken(fake.UserEmail)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(second), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	ctrl.RevokeToken(first)
+	if _, err := clt.GetAgentByName("agent-1", false); err != nil {
+		t.Errorf("Expected the rotated token to be used, got %v", err)
+	}
+	if clt.GetAccessToken() != second {
+		t.Error("Expected the access token to be the rotated one")
+	}
+
+	// Revoked tokens are rejected
+	ctrl.RevokeToken(second)
+	if _, err := clt.ListAgents(client.ListAgentsRequest{}); !errors.Is(err, client.ErrUnauthorized) {
+		t.Errorf("Expected the revoked token to be rejected, got %v", err)
+	}
+}
+
+func TestEnvTokenSource(t *testing.T) {
+	ctrl := fake.New()
+	defer ctrl.Close()
+	ctrl.AddUser(fake.UserEmail, fake.UserPassword)
+	token, err := ctrl.IssueToken(fake.UserEmail)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	clt := client.New(client.Options{BaseURL: ctrl.URL(), TokenSource: client.EnvTokenSource("IOFOG_TEST_TOKEN")})
+	t.Setenv("IOFOG_TEST_TOKEN", "")
+	if _, err := clt.ListAgents(client.ListAgentsRequest{}); !errors.Is(err, client.ErrInput) {
+		t.Errorf("Expected an empty environment variable to be rejected, got %v", err)
+	}
+	t.Setenv("IOFOG_TEST_TOKEN", token)
+	if _, err := clt.ListAgents(client.ListAgentsRequest{}); err != nil {
+		t.Errorf("Expected the token of the environment variable to be used, got %v", err)
+	}
+}
@@ -0,0 +1,79 @@
+/*
+ *  *******************************************************************************
+ *  * Copyright (c) 2019 Edgeworx, Inc.
+ *  *
+ *  * This program and the accompanying materials are made available under the
+ *  * terms of the Eclipse Public License v. 2.0 which is available at
+ *  * http://www.eclipse.org/legal/epl-2.0
+ *  *
+ *  * SPDX-License-Identifier: EPL-2.0
+ *  *******************************************************************************
+ *
+ */
+
+// Package tracing records OpenTelemetry spans for the Controller requests sent by client.Client
+package tracing
+
+import (
+	"context"
+	"time"
+
+	"github.com/eclipse-iofog/iofog-go-sdk/v3/pkg/client"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/eclipse-iofog/iofog-go-sdk/v3/pkg/client"
+
+// parentSpanKey holds the span enclosing the attempt span, which has ended by the time the attempt is retried
+type parentSpanKey struct{}
+
+// Hooks returns client hooks starting one span per request attempt, named after the client operation.
+// The global TracerProvider is used when provider is nil.
+func Hooks(provider trace.TracerProvider) client.Hooks {
+	if provider == nil {
+		provider = otel.GetTracerProvider()
+	}
+	tracer := provider.Tracer(instrumentationName)
+	return client.Hooks{
+		OnRequest: func(ctx context.Context, info client.RequestInfo) context.Context {
+			name := info.Operation
+			if name == "" {
+				name = info.Method
+			}
+			ctx = context.WithValue(ctx, parentSpanKey{}, trace.SpanFromContext(ctx))
+			ctx, _ = tracer.Start(ctx, name,
+				trace.WithSpanKind(trace.SpanKindClient),
+				trace.WithAttributes(
+					attribute.String("http.method", info.Method),
+					attribute.String("http.url", info.URL),
+					attribute.Int("iofog.attempt", info.Attempt),
+				),
+			)
+			return ctx
+		},
+		OnResponse: func(ctx context.Context, info client.ResponseInfo) {
+			span := trace.SpanFromContext(ctx)
+			if info.StatusCode != 0 {
+				span.SetAttributes(attribute.Int("http.status_code", info.StatusCode))
+			}
+			if info.Err != nil {
+				span.RecordError(info.Err)
+				span.SetStatus(codes.Error, info.Err.Error())
+			}
+			span.End()
+		},
+		OnRetry: func(ctx context.Context, info client.RequestInfo, delay time.Duration, err error) {
+			span, ok := ctx.Value(parentSpanKey{}).(trace.Span)
+			if !ok {
+				span = trace.SpanFromContext(ctx)
+			}
+			span.AddEvent("retry", trace.WithAttributes(
+				attribute.Int("iofog.attempt", info.Attempt),
+				attribute.String("iofog.retry_delay", delay.String()),
+			))
+		},
+	}
+}
@@ -329,9 +329,36 @@ type LoginResponse struct {
 	AccessToken string `json:"accessToken"`
 }
 
+// UserProfile is the profile of the logged in user
+type UserProfile struct {
+	FirstName string `json:"firstName"`
+	LastName  string `json:"lastName"`
+	Email     string `json:"email"`
+}
+
+type UpdateUserProfileRequest struct {
+	FirstName string `json:"firstName,omitempty"`
+	LastName  string `json:"lastName,omitempty"`
+}
+
+type DeleteUserAccountRequest struct {
+	// Force deletes the account even when it still owns resources
+	Force bool `json:"force"`
+}
+
+type ResetUserPasswordRequest struct {
+	Email string `json:"email"`
+}
+
+type ActivateUserAccountRequest struct {
+	ActivationCode string `json:"activationCode"`
+}
+
 type ListAgentsRequest struct {
 	System  bool              `json:"system"`
 	Filters []AgentListFilter `json:"filters"`
+	// Filters of AgentQuery evaluated by the client
+	predicates []agentPredicate
 }
 
 type CreateAgentRequest struct {
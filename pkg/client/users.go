@@ -13,11 +13,21 @@
 
 package client
 
-import "encoding/json"
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+)
 
 func (clt *Client) CreateUser(request User) error {
+	return clt.CreateUserWithContext(context.Background(), request)
+}
+
+// CreateUserWithContext is CreateUser with a context that can cancel the request or bound its deadline
+func (clt *Client) CreateUserWithContext(ctx context.Context, request User) error {
+	ctx = withOperation(ctx, "CreateUser")
 	// Send request
-	if _, err := clt.doRequest("POST", "/user/signup", request); err != nil {
+	if _, err := clt.doRequest(ctx, "POST", "/user/signup", request); err != nil {
 		return err
 	}
 
@@ -25,8 +35,14 @@ func (clt *Client) CreateUser(request User) error {
 }
 
 func (clt *Client) Login(request LoginRequest) (err error) {
-	// Send request
-	body, err := clt.doRequest("POST", "/user/login", request)
+	return clt.LoginWithContext(context.Background(), request)
+}
+
+// LoginWithContext is Login with a context that can cancel the request or bound its deadline
+func (clt *Client) LoginWithContext(ctx context.Context, request LoginRequest) (err error) {
+	ctx = withOperation(ctx, "Login")
+	// Send request, a rejected login must not trigger another login
+	body, err := clt.doRequest(withoutRelogin(ctx), "POST", "/user/login", request)
 	if err != nil {
 		return
 	}
@@ -36,17 +52,154 @@ func (clt *Client) Login(request LoginRequest) (err error) {
 	if err = json.Unmarshal(body, &response); err != nil {
 		return
 	}
+
+	// Remember credentials to log in again once the token expires
+	clt.mu.Lock()
 	clt.accessToken = response.AccessToken
+	clt.lastLogin = &request
+	clt.loggedOut = false
+	clt.mu.Unlock()
 
 	return
 }
 
 func (clt *Client) UpdateUserPassword(request UpdateUserPasswordRequest) (err error) {
+	return clt.UpdateUserPasswordWithContext(context.Background(), request)
+}
+
+// UpdateUserPasswordWithContext is UpdateUserPassword with a context that can cancel the request or bound its deadline
+func (clt *Client) UpdateUserPasswordWithContext(ctx context.Context, request UpdateUserPasswordRequest) (err error) {
+	ctx = withOperation(ctx, "UpdateUserPassword")
 	// Send request
-	_, err = clt.doRequest("PATCH", "/user/password", request)
+	_, err = clt.doRequest(ctx, "PATCH", "/user/password", request)
 	if err != nil {
 		return
 	}
 
+	// Log in again with the new password once the token expires
+	clt.mu.Lock()
+	if clt.lastLogin != nil {
+		login := *clt.lastLogin
+		login.Password = request.NewPassword
+		clt.lastLogin = &login
+	}
+	clt.mu.Unlock()
+
 	return
 }
+
+// Logout invalidates the access token on the Controller and forgets it along with the credentials of the last Login.
+// The token is forgotten even when the Controller already rejected it. Options.Credentials are not used again until the next Login
+func (clt *Client) Logout() error {
+	return clt.LogoutWithContext(context.Background())
+}
+
+// LogoutWithContext is Logout with a context that can cancel the request or bound its deadline
+func (clt *Client) LogoutWithContext(ctx context.Context) error {
+	ctx = withOperation(ctx, "Logout")
+	_, err := clt.doRequest(withoutRelogin(ctx), "POST", "/user/logout", nil)
+	if err != nil && !isUnauthorized(err) {
+		return err
+	}
+	clt.forgetLogin()
+	return nil
+}
+
+// GetUserProfile returns the profile of the logged in user
+func (clt *Client) GetUserProfile() (*UserProfile, error) {
+	return clt.GetUserProfileWithContext(context.Background())
+}
+
+// GetUserProfileWithContext is GetUserProfile with a context that can cancel the request or bound its deadline
+func (clt *Client) GetUserProfileWithContext(ctx context.Context) (*UserProfile, error) {
+	ctx = withOperation(ctx, "GetUserProfile")
+	body, err := clt.doRequest(ctx, "GET", "/user/profile", nil)
+	if err != nil {
+		return nil, err
+	}
+	profile := new(UserProfile)
+	if err := json.Unmarshal(body, profile); err != nil {
+		return nil, err
+	}
+	return profile, nil
+}
+
+// UpdateUserProfile updates the names of the logged in user and returns the updated profile
+func (clt *Client) UpdateUserProfile(request UpdateUserProfileRequest) (*UserProfile, error) {
+	return clt.UpdateUserProfileWithContext(context.Background(), request)
+}
+
+// UpdateUserProfileWithContext is UpdateUserProfile with a context that can cancel the request or bound its deadline
+func (clt *Client) UpdateUserProfileWithContext(ctx context.Context, request UpdateUserProfileRequest) (*UserProfile, error) {
+	ctx = withOperation(ctx, "UpdateUserProfile")
+	body, err := clt.doRequest(ctx, "PATCH", "/user/profile", request)
+	if err != nil {
+		return nil, err
+	}
+	profile := new(UserProfile)
+	if err := json.Unmarshal(body, profile); err != nil {
+		return nil, err
+	}
+	return profile, nil
+}
+
+// DeleteUserAccount deletes the account of the logged in user and forgets the access token
+func (clt *Client) DeleteUserAccount(request DeleteUserAccountRequest) error {
+	return clt.DeleteUserAccountWithContext(context.Background(), request)
+}
+
+// DeleteUserAccountWithContext is DeleteUserAccount with a context that can cancel the request or bound its deadline
+func (clt *Client) DeleteUserAccountWithContext(ctx context.Context, request DeleteUserAccountRequest) error {
+	ctx = withOperation(ctx, "DeleteUserAccount")
+	if _, err := clt.doRequest(ctx, "DELETE", "/user/profile", request); err != nil {
+		return err
+	}
+	clt.forgetLogin()
+	return nil
+}
+
+// ResetUserPassword asks the Controller to email a temporary password to the user
+func (clt *Client) ResetUserPassword(request ResetUserPasswordRequest) error {
+	return clt.ResetUserPasswordWithContext(context.Background(), request)
+}
+
+// ResetUserPasswordWithContext is ResetUserPassword with a context that can cancel the request or bound its deadline
+func (clt *Client) ResetUserPasswordWithContext(ctx context.Context, request ResetUserPasswordRequest) error {
+	ctx = withOperation(ctx, "ResetUserPassword")
+	_, err := clt.doRequest(withoutRelogin(ctx), "POST", "/user/password", request)
+	return err
+}
+
+// ResendUserActivation asks the Controller to email the account activation code again
+func (clt *Client) ResendUserActivation(email string) error {
+	return clt.ResendUserActivationWithContext(context.Background(), email)
+}
+
+// ResendUserActivationWithContext is ResendUserActivation with a context that can cancel the request or bound its deadline
+func (clt *Client) ResendUserActivationWithContext(ctx context.Context, email string) error {
+	ctx = withOperation(ctx, "ResendUserActivation")
+	_, err := clt.doRequest(withoutRelogin(ctx), "GET", "/user/signup/resend-activation?email="+url.QueryEscape(email), nil)
+	return err
+}
+
+// ActivateUserAccount activates a new account with the code emailed by the Controller
+func (clt *Client) ActivateUserAccount(request ActivateUserAccountRequest) error {
+	return clt.ActivateUserAccountWithContext(context.Background(), request)
+}
+
+// ActivateUserAccountWithContext is ActivateUserAccount with a context that can cancel the request or bound its deadline
+func (clt *Client) ActivateUserAccountWithContext(ctx context.Context, request ActivateUserAccountRequest) error {
+	ctx = withOperation(ctx, "ActivateUserAccount")
+	_, err := clt.doRequest(withoutRelogin(ctx), "POST", "/user/account/activate", request)
+	return err
+}
+
+// forgetLogin clears the access token and the credentials of the last Login so that the client does not log in again by itself.
+// Options.Credentials are kept for the next Login but not used until then
+func (clt *Client) forgetLogin() {
+	clt.mu.Lock()
+	defer clt.mu.Unlock()
+	clt.accessToken = ""
+	clt.lastLogin = nil
+	clt.loggedOut = true
+}
@@ -0,0 +1,132 @@
+/*
+ *  *******************************************************************************
+ *  * Copyright (c) 2019 Edgeworx, Inc.
+ *  *
+ *  * This program and the accompanying materials are made available under the
+ *  * terms of the Eclipse Public License v. 2.0 which is available at
+ *  * http://www.eclipse.org/legal/epl-2.0
+ *  *
+ *  * SPDX-License-Identifier: EPL-2.0
+ *  *******************************************************************************
+ *
+ */
+
+package client_test
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/eclipse-iofog/iofog-go-sdk/v3/pkg/client"
+	"github.com/eclipse-iofog/iofog-go-sdk/v3/pkg/client/fake"
+	"github.com/eclipse-iofog/iofog-go-sdk/v3/pkg/client/fake/faketest"
+)
+
+func TestUserAccount(t *testing.T) {
+	ctrl := fake.New()
+	defer ctrl.Close()
+	clt := client.New(client.Options{BaseURL: ctrl.URL()})
+	if err := clt.CreateUser(client.User{Name: "Jane", Surname: "Doe", Email: "jane@domain.com", Password: "secret"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Activate the account with the code sent again
+	code := ctrl.ActivationCode("jane@domain.com")
+	if err := clt.ResendUserActivation("jane@domain.com"); err != nil {
+		t.Fatal(err)
+	}
+	if err := clt.ActivateUserAccount(client.ActivateUserAccountRequest{ActivationCode: code}); !errors.Is(err, client.ErrNotFound) {
+		t.Errorf("Expected the previous activation code to be rejected, got %v", err)
+	}
+	if err := clt.ActivateUserAccount(client.ActivateUserAccountRequest{ActivationCode: ctrl.ActivationCode("jane@domain.com")}); err != nil {
+		t.Fatal(err)
+	}
+	if code := ctrl.ActivationCode("jane@domain.com"); code != "" {
+		t.Errorf("Expected the account to be activated, got pending code %s", code)
+	}
+
+	// Reset the password and log in with the temporary one
+	if err := clt.ResetUserPassword(client.ResetUserPasswordRequest{Email: "jane@domain.com"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := clt.Login(client.LoginRequest{Email: "jane@domain.com", Password: "secret"}); !errors.Is(err, client.ErrUnauthorized) {
+		t.Errorf("Expected the old password to be rejected, got %v", err)
+	}
+	if err := clt.Login(client.LoginRequest{Email: "jane@domain.com", Password: ctrl.Password("jane@domain.com")}); err != nil {
+		t.Fatal(err)
+	}
+
+	profile, err := clt.UpdateUserProfile(client.UpdateUserProfileRequest{LastName: "Smith"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := client.UserProfile{FirstName: "Jane", LastName: "Smith", Email: "jane@domain.com"}
+	if *profile != expected {
+		t.Errorf("Expected profile %v, got %v", expected, *profile)
+	}
+
+	// Logging out forgets the token and does not log in again
+	if err := clt.Logout(); err != nil {
+		t.Fatal(err)
+	}
+	if clt.GetAccessToken() != "" {
+		t.Error("Expected the access token to be cleared by Logout")
+	}
+	if _, err := clt.GetUserProfile(); !errors.Is(err, client.ErrUnauthorized) {
+		t.Errorf("Expected requests to be rejected after Logout, got %v", err)
+	}
+
+	if err := clt.Login(client.LoginRequest{Email: "jane@domain.com", Password: ctrl.Password("jane@domain.com")}); err != nil {
+		t.Fatal(err)
+	}
+	if err := clt.DeleteUserAccount(client.DeleteUserAccountRequest{Force: true}); err != nil {
+		t.Fatal(err)
+	}
+	if count := ctrl.RequestCount(http.MethodDelete, "/user/profile"); count != 1 {
+		t.Errorf("Expected DeleteUserAccount to delete the profile, got %d requests", count)
+	}
+	if _, err := clt.GetUserProfile(); !errors.Is(err, client.ErrUnauthorized) {
+		t.Errorf("Expected requests to be rejected after DeleteUserAccount, got %v", err)
+	}
+}
+
+func TestUpdateUserPasswordRelogin(t *testing.T) {
+	ctrl, clt := faketest.NewLoggedIn(t, client.Options{})
+	if err := clt.UpdateUserPassword(client.UpdateUserPasswordRequest{OldPassword: "secret", NewPassword: "changed"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Logging in again once the token expires uses the new password
+	ctrl.ExpireTokens()
+	if _, err := clt.GetUserProfile(); err != nil {
+		t.Errorf("Expected the client to log in again with the new password, got %v", err)
+	}
+}
+
+func TestLogoutForgetsCredentials(t *testing.T) {
+	ctrl, clt := faketest.NewLoggedIn(t, client.Options{
+		Credentials: client.StaticCredentials{Email: fake.UserEmail, Password: fake.UserPassword},
+	})
+	if err := clt.Logout(); err != nil {
+		t.Fatal(err)
+	}
+	logins := ctrl.RequestCount(http.MethodPost, "/user/login")
+
+	// Options.Credentials are not used to log in again after Logout
+	if _, err := clt.GetUserProfile(); !errors.Is(err, client.ErrUnauthorized) {
+		t.Errorf("Expected requests to be rejected after Logout, got %v", err)
+	}
+	if count := ctrl.RequestCount(http.MethodPost, "/user/login"); count != logins {
+		t.Errorf("Expected no login after Logout, got %d", count-logins)
+	}
+
+	// Until the next Login
+	if err := clt.Login(client.LoginRequest{Email: fake.UserEmail, Password: fake.UserPassword}); err != nil {
+		t.Fatal(err)
+	}
+	ctrl.ExpireTokens()
+	if _, err := clt.GetUserProfile(); err != nil {
+		t.Errorf("Expected the client to log in again after Login, got %v", err)
+	}
+}
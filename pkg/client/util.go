@@ -15,8 +15,10 @@ package client
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"io"
+	"net/http"
 	"strings"
 )
 
@@ -54,17 +56,34 @@ func getString(in io.Reader) (out string, err error) {
 	return
 }
 
-func checkStatusCode(code int, method, url string, body io.Reader) error {
+func checkStatusCode(response *http.Response, method, url string) error {
+	code := response.StatusCode
 	if code < 200 || code >= 300 {
-		bodyString, err := getString(body)
+		bodyString, err := getString(response.Body)
 		if err != nil {
 			return err
 		}
+		httpErr := NewHTTPError(fmt.Sprintf("Received %d from %s %s\n%s", code, method, url, bodyString), code)
+		httpErr.Method = method
+		httpErr.URL = url
+		httpErr.RequestID = response.Header.Get("X-Request-Id")
+		httpErr.retryAfter = parseRetryAfter(response.Header.Get("Retry-After"))
+		_ = json.Unmarshal([]byte(bodyString), &httpErr.Controller)
 		switch code {
-		case 404:
-			return NewNotFoundError(fmt.Sprintf("Received Not found from %s %s\n: %s\n", method, url, bodyString))
+		case http.StatusNotFound:
+			notFoundErr := NewNotFoundError(fmt.Sprintf("Received Not found from %s %s\n: %s\n", method, url, bodyString))
+			notFoundErr.httpErr = httpErr
+			return notFoundErr
+		case http.StatusConflict:
+			conflictErr := NewConflictError(fmt.Sprintf("Received Conflict from %s %s\n: %s\n", method, url, bodyString))
+			conflictErr.httpErr = httpErr
+			return conflictErr
+		case http.StatusUnauthorized:
+			return &UnauthorizedError{httpErr: httpErr}
+		case http.StatusForbidden:
+			return &ForbiddenError{httpErr: httpErr}
 		default:
-			return NewHTTPError(fmt.Sprintf("Received %d from %s %s\n%s", code, method, url, bodyString), code)
+			return httpErr
 		}
 	}
 	return nil
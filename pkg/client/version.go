@@ -13,33 +13,62 @@
 
 package client
 
-import (
-	"errors"
-	"fmt"
-	"strconv"
-)
+import "context"
 
+// GetVersion returns the version reported by the Controller, empty if it could not be queried
 func (clt *Client) GetVersion() string {
-	return clt.status.version
+	status, _ := clt.loadStatus(context.Background())
+	return status.version
 }
 
 func (clt *Client) GetVersionNumbers() (major, minor, patch int, err error) {
-	errMsg := fmt.Sprintf("Controller did not return a valid API version: %s", clt.status.version)
-
-	// Split version string
-	if len(clt.status.versionNums) != 3 {
-		err = errors.New(errMsg)
+	version, err := clt.ControllerVersion()
+	if err != nil {
 		return
 	}
+	return version.Major, version.Minor, version.Patch, nil
+}
 
-	// Convert to int
-	major, majErr := strconv.Atoi(clt.status.versionNums[0])
-	minor, minErr := strconv.Atoi(clt.status.versionNums[1])
-	patch, patErr := strconv.Atoi(clt.status.versionNums[2])
-	if majErr != nil || minErr != nil || patErr != nil {
-		err = errors.New(errMsg)
-		return
+// ControllerVersion returns the semantic version of the Controller.
+// The Controller status is queried on first use and again after each failed attempt.
+func (clt *Client) ControllerVersion() (Version, error) {
+	return clt.ControllerVersionWithContext(context.Background())
+}
+
+// ControllerVersionWithContext is ControllerVersion with a context that can cancel the request or bound its deadline
+func (clt *Client) ControllerVersionWithContext(ctx context.Context) (Version, error) {
+	ctx = withOperation(ctx, "ControllerVersion")
+	status, err := clt.loadStatus(ctx)
+	if err != nil {
+		return Version{}, err
+	}
+	return status.semver, status.err
+}
+
+// StatusError returns the error of the last failed attempt to query the Controller status, nil once an attempt succeeded
+func (clt *Client) StatusError() error {
+	clt.mu.RLock()
+	defer clt.mu.RUnlock()
+	return clt.statusErr
+}
+
+// loadStatus returns the cached Controller status, querying it when unknown
+func (clt *Client) loadStatus(ctx context.Context) (controllerStatus, error) {
+	// Concurrent callers wait for a single query
+	clt.statusMu.Lock()
+	defer clt.statusMu.Unlock()
+
+	clt.mu.RLock()
+	status := clt.status
+	clt.mu.RUnlock()
+	if status.fetched {
+		return status, nil
 	}
 
-	return
+	if _, err := clt.GetStatusWithContext(ctx); err != nil {
+		return status, err
+	}
+	clt.mu.RLock()
+	defer clt.mu.RUnlock()
+	return clt.status, nil
 }
@@ -0,0 +1,229 @@
+/*
+ *  *******************************************************************************
+ *  * Copyright (c) 2019 Edgeworx, Inc.
+ *  *
+ *  * This program and the accompanying materials are made available under the
+ *  * terms of the Eclipse Public License v. 2.0 which is available at
+ *  * http://www.eclipse.org/legal/epl-2.0
+ *  *
+ *  * SPDX-License-Identifier: EPL-2.0
+ *  *******************************************************************************
+ *
+ */
+
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Statuses reported by the Controller for Agents and Microservices
+const (
+	AgentStatusRunning = "RUNNING"
+
+	MicroserviceStatusQueued   = "QUEUED"
+	MicroserviceStatusPulling  = "PULLING"
+	MicroserviceStatusStarting = "STARTING"
+	MicroserviceStatusRunning  = "RUNNING"
+	MicroserviceStatusStopped  = "STOPPED"
+	MicroserviceStatusFailed   = "FAILED"
+)
+
+const defaultPollInterval = 2 * time.Second
+
+// WaitOptions configures the WaitFor functions. A nil *WaitOptions uses the defaults
+type WaitOptions struct {
+	// PollInterval is the delay between two queries, 2 seconds by default
+	PollInterval time.Duration
+	// OnProgress is called with the observed Microservices after each query, e.g. to report the image pull Percentage
+	OnProgress func(microservices []MicroserviceInfo)
+}
+
+func (opts *WaitOptions) pollInterval() time.Duration {
+	if opts == nil || opts.PollInterval <= 0 {
+		return defaultPollInterval
+	}
+	return opts.PollInterval
+}
+
+func (opts *WaitOptions) progress(microservices []MicroserviceInfo) {
+	if opts != nil && opts.OnProgress != nil {
+		opts.OnProgress(microservices)
+	}
+}
+
+// WaitError is returned when the context of a WaitFor function is done before the resource is running,
+// or when the awaited Microservice fails
+type WaitError struct {
+	// Resource is the kind and name of the awaited resource, e.g. application my-app
+	Resource string
+	// Status is the last status observed for Agents
+	Status string
+	// NotReady lists the Microservices which were not running at the last query
+	NotReady []MicroserviceInfo
+	// LastErr is the error of the last failed query, if any
+	LastErr error
+	err     error
+}
+
+// Error export
+func (err *WaitError) Error() string {
+	msg := fmt.Sprintf("%s is not running: %s", err.Resource, err.err.Error())
+	if err.Status != "" {
+		msg += fmt.Sprintf("\nLast status: %s", err.Status)
+	}
+	for idx := range err.NotReady {
+		msvc := &err.NotReady[idx]
+		msg += fmt.Sprintf("\nMicroservice %s is %s", msvc.Name, describeMicroserviceStatus(msvc.Status))
+	}
+	if err.LastErr != nil {
+		msg += fmt.Sprintf("\nLast error: %s", err.LastErr.Error())
+	}
+	return msg
+}
+
+// Unwrap returns the context error, e.g. context.DeadlineExceeded, or ErrMicroserviceFailed
+func (err *WaitError) Unwrap() error {
+	return err.err
+}
+
+func describeMicroserviceStatus(status MicroserviceStatusInfo) string {
+	desc := status.Status
+	if desc == "" {
+		desc = "unknown"
+	}
+	if status.Status == MicroserviceStatusPulling {
+		desc += fmt.Sprintf(" (%.0f%%)", status.Percentage)
+	}
+	if status.ErrorMessage != "" {
+		desc += ": " + status.ErrorMessage
+	}
+	return desc
+}
+
+// poll calls check until it reports done or fails with a permanent error.
+// Transient errors are kept in lastErr and the check is attempted again.
+func poll(ctx context.Context, interval time.Duration, check func() (done bool, err error)) (lastErr error, err error) {
+	for {
+		done, checkErr := check()
+		if checkErr != nil {
+			if ctx.Err() != nil || isPermanentWaitError(checkErr) {
+				return lastErr, checkErr
+			}
+			lastErr = checkErr
+		} else if done {
+			return nil, nil
+		}
+		if err := sleepWithContext(ctx, interval); err != nil {
+			return lastErr, err
+		}
+	}
+}
+
+// isPermanentWaitError reports errors worth giving up on, request timeouts are transient unless ctx itself is done
+func isPermanentWaitError(err error) bool {
+	return errors.Is(err, ErrNotFound) || errors.Is(err, ErrUnauthorized) || errors.Is(err, ErrForbidden) || errors.Is(err, ErrMicroserviceFailed)
+}
+
+// WaitForAgentRunning polls the Agent until the Controller reports its daemon as running or ctx is done
+func (clt *Client) WaitForAgentRunning(ctx context.Context, uuid string, opts *WaitOptions) (agent *AgentInfo, err error) {
+	ctx = withOperation(ctx, "WaitForAgentRunning")
+	lastErr, err := poll(ctx, opts.pollInterval(), func() (bool, error) {
+		current, err := clt.GetAgentByIDWithContext(ctx, uuid)
+		if err != nil {
+			return false, err
+		}
+		agent = current
+		return agent.DaemonStatus == AgentStatusRunning, nil
+	})
+	if err != nil && ctx.Err() != nil {
+		waitErr := &WaitError{Resource: "agent " + uuid, LastErr: lastErr, err: ctx.Err()}
+		if agent != nil {
+			waitErr.Resource = "agent " + agent.Name
+			waitErr.Status = agent.DaemonStatus
+		}
+		return agent, waitErr
+	}
+	return agent, err
+}
+
+// WaitForMicroserviceRunning polls the Microservice until it is running or ctx is done.
+// The pull progress is available through WaitOptions.OnProgress.
+// A *WaitError wrapping ErrMicroserviceFailed is returned as soon as the Microservice is reported as failed.
+func (clt *Client) WaitForMicroserviceRunning(ctx context.Context, uuid string, opts *WaitOptions) (msvc *MicroserviceInfo, err error) {
+	ctx = withOperation(ctx, "WaitForMicroserviceRunning")
+	lastErr, err := poll(ctx, opts.pollInterval(), func() (bool, error) {
+		current, err := clt.GetMicroserviceByIDWithContext(ctx, uuid)
+		if err != nil {
+			return false, err
+		}
+		msvc = current
+		opts.progress([]MicroserviceInfo{*msvc})
+		if msvc.Status.Status == MicroserviceStatusFailed {
+			return false, ErrMicroserviceFailed
+		}
+		return msvc.Status.Status == MicroserviceStatusRunning, nil
+	})
+	if errors.Is(err, ErrMicroserviceFailed) {
+		return msvc, &WaitError{
+			Resource: fmt.Sprintf("microservice %s/%s", msvc.Application, msvc.Name),
+			NotReady: []MicroserviceInfo{*msvc},
+			err:      err,
+		}
+	}
+	if err != nil && ctx.Err() != nil {
+		waitErr := &WaitError{Resource: "microservice " + uuid, LastErr: lastErr, err: ctx.Err()}
+		if msvc != nil {
+			waitErr.Resource = fmt.Sprintf("microservice %s/%s", msvc.Application, msvc.Name)
+			waitErr.NotReady = []MicroserviceInfo{*msvc}
+		}
+		return msvc, waitErr
+	}
+	return msvc, err
+}
+
+// WaitForApplicationRunning polls the Application until it is activated and all of its Microservices are running or ctx is done.
+// On timeout, the returned *WaitError lists the Microservices which were not running.
+// A *WaitError wrapping ErrMicroserviceFailed is returned as soon as any of its Microservices is reported as failed.
+func (clt *Client) WaitForApplicationRunning(ctx context.Context, name string, opts *WaitOptions) (app *ApplicationInfo, err error) {
+	ctx = withOperation(ctx, "WaitForApplicationRunning")
+	var notReady []MicroserviceInfo
+	lastErr, err := poll(ctx, opts.pollInterval(), func() (bool, error) {
+		current, err := clt.GetApplicationByNameWithContext(ctx, name)
+		if err != nil {
+			return false, err
+		}
+		app = current
+		opts.progress(app.Microservices)
+		notReady = notRunningMicroservices(app.Microservices)
+		for idx := range notReady {
+			if notReady[idx].Status.Status == MicroserviceStatusFailed {
+				return false, ErrMicroserviceFailed
+			}
+		}
+		return app.IsActivated && len(notReady) == 0, nil
+	})
+	if errors.Is(err, ErrMicroserviceFailed) {
+		return app, &WaitError{Resource: "application " + name, NotReady: notReady, err: err}
+	}
+	if err != nil && ctx.Err() != nil {
+		waitErr := &WaitError{Resource: "application " + name, NotReady: notReady, LastErr: lastErr, err: ctx.Err()}
+		if app != nil && !app.IsActivated {
+			waitErr.Status = "not activated"
+		}
+		return app, waitErr
+	}
+	return app, err
+}
+
+func notRunningMicroservices(msvcs []MicroserviceInfo) (notReady []MicroserviceInfo) {
+	for idx := range msvcs {
+		if msvcs[idx].Status.Status != MicroserviceStatusRunning {
+			notReady = append(notReady, msvcs[idx])
+		}
+	}
+	return
+}
@@ -0,0 +1,128 @@
+/*
+ *  *******************************************************************************
+ *  * Copyright (c) 2019 Edgeworx, Inc.
+ *  *
+ *  * This program and the accompanying materials are made available under the
+ *  * terms of the Eclipse Public License v. 2.0 which is available at
+ *  * http://www.eclipse.org/legal/epl-2.0
+ *  *
+ *  * SPDX-License-Identifier: EPL-2.0
+ *  *******************************************************************************
+ *
+ */
+
+package client_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/eclipse-iofog/iofog-go-sdk/v3/pkg/client"
+	"github.com/eclipse-iofog/iofog-go-sdk/v3/pkg/client/fake/faketest"
+)
+
+const waitApplicationYAML = `kind: Application
+metadata:
+  name: wait-app
+spec:
+  microservices:
+  - name: server
+    agent:
+      name: agent-1
+    images:
+      x86: nginx
+  - name: client
+    agent:
+      name: agent-1
+    images:
+      x86: curl
+`
+
+func TestWaitForApplicationRunning(t *testing.T) {
+	ctrl, clt := faketest.NewLoggedIn(t, client.Options{})
+	agent, err := clt.CreateAgent(&client.CreateAgentRequest{AgentUpdateRequest: client.AgentUpdateRequest{Name: "agent-1"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	app, err := clt.CreateApplicationFromYAML(strings.NewReader(waitApplicationYAML))
+	if err != nil {
+		t.Fatal(err)
+	}
+	uuids := make(map[string]string)
+	for _, msvc := range app.Microservices {
+		uuids[msvc.Name] = msvc.UUID
+	}
+	opts := &client.WaitOptions{PollInterval: 5 * time.Millisecond}
+
+	// The client microservice is still pulling when the deadline expires
+	_ = ctrl.UpdateMicroservice(uuids["server"], func(msvc *client.MicroserviceInfo) {
+		msvc.Status.Status = client.MicroserviceStatusRunning
+	})
+	_ = ctrl.UpdateMicroservice(uuids["client"], func(msvc *client.MicroserviceInfo) {
+		msvc.Status = client.MicroserviceStatusInfo{Status: client.MicroserviceStatusPulling, Percentage: 42}
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err = clt.WaitForApplicationRunning(ctx, "wait-app", opts)
+	waitErr := &client.WaitError{}
+	if !errors.As(err, &waitErr) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected wait error, got %v", err)
+	}
+	if len(waitErr.NotReady) != 1 || !strings.Contains(err.Error(), "client is PULLING (42%)") {
+		t.Errorf("Unexpected wait error: %v", err)
+	}
+
+	// Both microservices and the agent end up running
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		_ = ctrl.UpdateMicroservice(uuids["client"], func(msvc *client.MicroserviceInfo) {
+			msvc.Status = client.MicroserviceStatusInfo{Status: client.MicroserviceStatusRunning}
+		})
+		_ = ctrl.UpdateAgent(agent.UUID, func(agent *client.AgentInfo) {
+			agent.DaemonStatus = client.AgentStatusRunning
+		})
+	}()
+	ctx, cancel = context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if _, err = clt.WaitForApplicationRunning(ctx, "wait-app", opts); err != nil {
+		t.Errorf("WaitForApplicationRunning failed: %v", err)
+	}
+	if _, err = clt.WaitForAgentRunning(ctx, agent.UUID, opts); err != nil {
+		t.Errorf("WaitForAgentRunning failed: %v", err)
+	}
+	if _, err = clt.WaitForMicroserviceRunning(ctx, "missing", opts); !errors.Is(err, client.ErrNotFound) {
+		t.Errorf("Expected not found error, got %v", err)
+	}
+
+	// A failed microservice ends the wait without waiting for the deadline
+	_ = ctrl.UpdateMicroservice(uuids["client"], func(msvc *client.MicroserviceInfo) {
+		msvc.Status = client.MicroserviceStatusInfo{Status: client.MicroserviceStatusFailed, ErrorMessage: "image not found"}
+	})
+	start := time.Now()
+	_, err = clt.WaitForMicroserviceRunning(ctx, uuids["client"], opts)
+	if !errors.Is(err, client.ErrMicroserviceFailed) || !errors.As(err, &waitErr) {
+		t.Fatalf("Expected failed microservice error, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected the wait to stop on failure, took %s", elapsed)
+	}
+	if !strings.Contains(err.Error(), "wait-app/client is not running: microservice failed") || !strings.Contains(err.Error(), "FAILED: image not found") {
+		t.Errorf("Unexpected failed microservice error: %v", err)
+	}
+
+	// The application waiter stops on the failed microservice too
+	start = time.Now()
+	_, err = clt.WaitForApplicationRunning(ctx, "wait-app", opts)
+	if !errors.Is(err, client.ErrMicroserviceFailed) || !errors.As(err, &waitErr) {
+		t.Fatalf("Expected failed microservice error, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected the wait to stop on failure, took %s", elapsed)
+	}
+	if len(waitErr.NotReady) != 1 || !strings.Contains(err.Error(), "client is FAILED: image not found") {
+		t.Errorf("Unexpected failed microservice error: %v", err)
+	}
+}
@@ -0,0 +1,424 @@
+/*
+ *  *******************************************************************************
+ *  * Copyright (c) 2019 Edgeworx, Inc.
+ *  *
+ *  * This program and the accompanying materials are made available under the
+ *  * terms of the Eclipse Public License v. 2.0 which is available at
+ *  * http://www.eclipse.org/legal/epl-2.0
+ *  *
+ *  * SPDX-License-Identifier: EPL-2.0
+ *  *******************************************************************************
+ *
+ */
+
+package client
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+)
+
+// EventType is the kind of change reported by a Watcher
+type EventType string
+
+const (
+	EventAdded    EventType = "ADDED"
+	EventModified EventType = "MODIFIED"
+	EventDeleted  EventType = "DELETED"
+)
+
+// ResourceKind is a kind of Controller resource which can be watched
+type ResourceKind string
+
+const (
+	KindAgent        ResourceKind = "Agent"
+	KindMicroservice ResourceKind = "Microservice"
+	KindApplication  ResourceKind = "Application"
+)
+
+// Event is a change observed by a Watcher
+type Event struct {
+	Type EventType
+	Kind ResourceKind
+	// Key is the UUID of Agents and Microservices and the name of Applications
+	Key string
+	// Object is the current resource, or the last known one for deletions: *AgentInfo, *MicroserviceInfo or *ApplicationInfo.
+	// It is shared with the cache of the Watcher and must not be modified
+	Object interface{}
+	// Old is the previous resource for modifications
+	Old interface{}
+}
+
+// Agent returns the Agent of the event, nil for other kinds
+func (event Event) Agent() *AgentInfo {
+	agent, _ := event.Object.(*AgentInfo)
+	return agent
+}
+
+// Microservice returns the Microservice of the event, nil for other kinds
+func (event Event) Microservice() *MicroserviceInfo {
+	msvc, _ := event.Object.(*MicroserviceInfo)
+	return msvc
+}
+
+// Application returns the Application of the event, nil for other kinds
+func (event Event) Application() *ApplicationInfo {
+	app, _ := event.Object.(*ApplicationInfo)
+	return app
+}
+
+// WatchOptions configures a Watcher
+type WatchOptions struct {
+	// Kinds to watch, all of them when empty
+	Kinds []ResourceKind
+	// Interval between two polls of the Controller, 10 seconds by default
+	Interval time.Duration
+	// System includes system Agents
+	System bool
+	// BufferSize of the events channel, 100 by default
+	BufferSize int
+	// Equal reports whether a resource is unchanged between two polls, no EventModified is emitted when it returns true.
+	// By default the resources are compared without the telemetry refreshed by every Agent status report,
+	// e.g. LastActive, UptimeMs and the resource usages.
+	Equal func(kind ResourceKind, old, current interface{}) bool
+}
+
+// Watcher polls the Controller, keeps a local cache of the watched resources and emits an Event for each change.
+// The first successful poll of each kind emits an EventAdded per existing resource.
+type Watcher struct {
+	clt    *Client
+	opts   WatchOptions
+	events chan Event
+
+	mu     sync.RWMutex
+	caches map[ResourceKind]map[string]interface{}
+	// errs holds the error of the last poll of each kind
+	errs map[ResourceKind]error
+
+	synced     chan struct{}
+	syncedOnce sync.Once
+}
+
+// Watch starts watching the Controller until ctx is done, at which point the events channel is closed.
+// Events must be consumed, polling is paused while the channel is full.
+// Unknown kinds are rejected with an *InputError.
+func (clt *Client) Watch(ctx context.Context, opts WatchOptions) (*Watcher, error) {
+	if len(opts.Kinds) == 0 {
+		opts.Kinds = []ResourceKind{KindAgent, KindMicroservice, KindApplication}
+	}
+	// WaitForSync counts the listed kinds, duplicates would prevent it from returning
+	kinds := make([]ResourceKind, 0, len(opts.Kinds))
+	seen := make(map[ResourceKind]bool)
+	for _, kind := range opts.Kinds {
+		switch kind {
+		case KindAgent, KindMicroservice, KindApplication:
+		default:
+			return nil, NewInputError("Cannot watch unknown resource kind " + string(kind))
+		}
+		if !seen[kind] {
+			seen[kind] = true
+			kinds = append(kinds, kind)
+		}
+	}
+	opts.Kinds = kinds
+	if opts.Equal == nil {
+		opts.Equal = equalWithoutTelemetry
+	}
+	if opts.Interval <= 0 {
+		opts.Interval = 10 * time.Second
+	}
+	if opts.BufferSize <= 0 {
+		opts.BufferSize = 100
+	}
+	watcher := &Watcher{
+		clt:    clt,
+		opts:   opts,
+		events: make(chan Event, opts.BufferSize),
+		caches: make(map[ResourceKind]map[string]interface{}),
+		errs:   make(map[ResourceKind]error),
+		synced: make(chan struct{}),
+	}
+	go watcher.run(withOperation(ctx, "Watch"))
+	return watcher, nil
+}
+
+// Events returns the channel of observed changes
+func (watcher *Watcher) Events() <-chan Event {
+	return watcher.events
+}
+
+// Err returns the error of the last poll of the first watched kind which failed, nil once every kind was polled successfully.
+// A *ListError is returned when only part of the Microservices could be listed, the others being still watched
+func (watcher *Watcher) Err() error {
+	watcher.mu.RLock()
+	defer watcher.mu.RUnlock()
+	for _, kind := range watcher.opts.Kinds {
+		if err := watcher.errs[kind]; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WaitForSync blocks until every watched kind was listed once or ctx is done
+func (watcher *Watcher) WaitForSync(ctx context.Context) error {
+	select {
+	case <-watcher.synced:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// ListAgents returns the cached Agents sorted by name
+func (watcher *Watcher) ListAgents() []AgentInfo {
+	agents := []AgentInfo{}
+	for _, obj := range watcher.list(KindAgent) {
+		agents = append(agents, *obj.(*AgentInfo))
+	}
+	sort.Slice(agents, func(i, j int) bool { return agents[i].Name < agents[j].Name })
+	return agents
+}
+
+// GetAgent returns the cached Agent with the given UUID
+func (watcher *Watcher) GetAgent(uuid string) (AgentInfo, bool) {
+	if obj, exists := watcher.get(KindAgent, uuid); exists {
+		return *obj.(*AgentInfo), true
+	}
+	return AgentInfo{}, false
+}
+
+// ListMicroservices returns the cached Microservices of an Application, or of all Applications when application is empty
+func (watcher *Watcher) ListMicroservices(application string) []MicroserviceInfo {
+	msvcs := []MicroserviceInfo{}
+	for _, obj := range watcher.list(KindMicroservice) {
+		msvc := obj.(*MicroserviceInfo)
+		if application == "" || msvc.Application == application {
+			msvcs = append(msvcs, *msvc)
+		}
+	}
+	sort.Slice(msvcs, func(i, j int) bool {
+		if msvcs[i].Application != msvcs[j].Application {
+			return msvcs[i].Application < msvcs[j].Application
+		}
+		return msvcs[i].Name < msvcs[j].Name
+	})
+	return msvcs
+}
+
+// GetMicroservice returns the cached Microservice with the given UUID
+func (watcher *Watcher) GetMicroservice(uuid string) (MicroserviceInfo, bool) {
+	if obj, exists := watcher.get(KindMicroservice, uuid); exists {
+		return *obj.(*MicroserviceInfo), true
+	}
+	return MicroserviceInfo{}, false
+}
+
+// ListApplications returns the cached Applications sorted by name
+func (watcher *Watcher) ListApplications() []ApplicationInfo {
+	apps := []ApplicationInfo{}
+	for _, obj := range watcher.list(KindApplication) {
+		apps = append(apps, *obj.(*ApplicationInfo))
+	}
+	sort.Slice(apps, func(i, j int) bool { return apps[i].Name < apps[j].Name })
+	return apps
+}
+
+// GetApplication returns the cached Application with the given name
+func (watcher *Watcher) GetApplication(name string) (ApplicationInfo, bool) {
+	if obj, exists := watcher.get(KindApplication, name); exists {
+		return *obj.(*ApplicationInfo), true
+	}
+	return ApplicationInfo{}, false
+}
+
+func (watcher *Watcher) list(kind ResourceKind) []interface{} {
+	watcher.mu.RLock()
+	defer watcher.mu.RUnlock()
+	objs := make([]interface{}, 0, len(watcher.caches[kind]))
+	for _, obj := range watcher.caches[kind] {
+		objs = append(objs, obj)
+	}
+	return objs
+}
+
+func (watcher *Watcher) get(kind ResourceKind, key string) (interface{}, bool) {
+	watcher.mu.RLock()
+	defer watcher.mu.RUnlock()
+	obj, exists := watcher.caches[kind][key]
+	return obj, exists
+}
+
+func (watcher *Watcher) run(ctx context.Context) {
+	defer close(watcher.events)
+	for {
+		if !watcher.poll(ctx) {
+			return
+		}
+		if err := sleepWithContext(ctx, watcher.opts.Interval); err != nil {
+			return
+		}
+	}
+}
+
+// poll lists every watched kind and emits the changes, it returns false once ctx is done
+func (watcher *Watcher) poll(ctx context.Context) bool {
+	for _, kind := range watcher.opts.Kinds {
+		current, err := watcher.fetch(ctx, kind)
+		if err != nil {
+			if ctx.Err() != nil {
+				return false
+			}
+			watcher.clt.logger.Warn("Failed to poll watched resources", "kind", kind, "error", err)
+		}
+		// A partial list is applied, other errors leave the cache as is
+		var listErr *ListError
+		partial := errors.As(err, &listErr)
+		if err != nil && !partial {
+			watcher.mu.Lock()
+			watcher.errs[kind] = err
+			watcher.mu.Unlock()
+			continue
+		}
+		for _, event := range watcher.replace(kind, current, err) {
+			select {
+			case watcher.events <- event:
+			case <-ctx.Done():
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// fetch lists the resources of a kind, keyed like the events
+func (watcher *Watcher) fetch(ctx context.Context, kind ResourceKind) (map[string]interface{}, error) {
+	objs := make(map[string]interface{})
+	switch kind {
+	case KindAgent:
+		response, err := watcher.clt.ListAgentsWithContext(ctx, ListAgentsRequest{System: watcher.opts.System})
+		if err != nil {
+			return nil, err
+		}
+		for idx := range response.Agents {
+			objs[response.Agents[idx].UUID] = &response.Agents[idx]
+		}
+	case KindMicroservice:
+		// The Microservices of the flows which could be listed come with a *ListError
+		response, err := watcher.clt.GetAllMicroservicesWithContext(ctx)
+		if response == nil {
+			return nil, err
+		}
+		for idx := range response.Microservices {
+			objs[response.Microservices[idx].UUID] = &response.Microservices[idx]
+		}
+		return objs, err
+	case KindApplication:
+		response, err := watcher.clt.GetAllApplicationsWithContext(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for idx := range response.Applications {
+			objs[response.Applications[idx].Name] = &response.Applications[idx]
+		}
+	}
+	return objs, nil
+}
+
+// replace swaps the cache of a kind for the current resources and returns the differences.
+// When err reports a partial list, the cached resources missing from it are kept rather than deleted
+func (watcher *Watcher) replace(kind ResourceKind, current map[string]interface{}, err error) (events []Event) {
+	watcher.mu.Lock()
+	previous := watcher.caches[kind]
+	if err != nil {
+		for key, obj := range previous {
+			if _, exists := current[key]; !exists {
+				current[key] = obj
+			}
+		}
+	}
+	watcher.caches[kind] = current
+	watcher.errs[kind] = err
+	synced := len(watcher.caches) == len(watcher.opts.Kinds)
+	watcher.mu.Unlock()
+	if synced {
+		watcher.syncedOnce.Do(func() { close(watcher.synced) })
+	}
+
+	for _, key := range sortedKeys(current) {
+		old, existed := previous[key]
+		switch {
+		case !existed:
+			events = append(events, Event{Type: EventAdded, Kind: kind, Key: key, Object: current[key]})
+		case !watcher.opts.Equal(kind, old, current[key]):
+			events = append(events, Event{Type: EventModified, Kind: kind, Key: key, Object: current[key], Old: old})
+		}
+	}
+	for _, key := range sortedKeys(previous) {
+		if _, exists := current[key]; !exists {
+			events = append(events, Event{Type: EventDeleted, Kind: kind, Key: key, Object: previous[key]})
+		}
+	}
+	return events
+}
+
+// equalWithoutTelemetry is the default WatchOptions.Equal
+func equalWithoutTelemetry(kind ResourceKind, old, current interface{}) bool {
+	switch kind {
+	case KindAgent:
+		return reflect.DeepEqual(agentWithoutTelemetry(*old.(*AgentInfo)), agentWithoutTelemetry(*current.(*AgentInfo)))
+	case KindMicroservice:
+		return reflect.DeepEqual(microserviceWithoutTelemetry(*old.(*MicroserviceInfo)), microserviceWithoutTelemetry(*current.(*MicroserviceInfo)))
+	case KindApplication:
+		return reflect.DeepEqual(applicationWithoutTelemetry(*old.(*ApplicationInfo)), applicationWithoutTelemetry(*current.(*ApplicationInfo)))
+	}
+	return reflect.DeepEqual(old, current)
+}
+
+// agentWithoutTelemetry clears the fields updated by every status report of the Agent
+func agentWithoutTelemetry(agent AgentInfo) AgentInfo {
+	agent.UpdatedTimeRFC3339 = ""
+	agent.LastActive = 0
+	agent.LastStatusTimeMsUTC = 0
+	agent.LastCommandTimeMsUTC = 0
+	agent.UptimeMs = 0
+	agent.MemoryUsage = 0
+	agent.DiskUsage = 0
+	agent.CPUUsage = 0
+	agent.SystemAvailableMemory = 0
+	agent.SystemAvailableDisk = 0
+	agent.ProcessedMessaged = 0
+	agent.MicroserviceMessageCount = 0
+	agent.MessageSpeed = 0
+	return agent
+}
+
+// microserviceWithoutTelemetry clears the resource usage reported with the status of the Microservice
+func microserviceWithoutTelemetry(msvc MicroserviceInfo) MicroserviceInfo {
+	msvc.Status.OperatingDuration = 0
+	msvc.Status.MemoryUsage = 0
+	msvc.Status.CPUUsage = 0
+	return msvc
+}
+
+func applicationWithoutTelemetry(app ApplicationInfo) ApplicationInfo {
+	msvcs := make([]MicroserviceInfo, len(app.Microservices))
+	for idx := range app.Microservices {
+		msvcs[idx] = microserviceWithoutTelemetry(app.Microservices[idx])
+	}
+	app.Microservices = msvcs
+	return app
+}
+
+func sortedKeys(objs map[string]interface{}) []string {
+	keys := make([]string, 0, len(objs))
+	for key := range objs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
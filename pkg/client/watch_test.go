@@ -0,0 +1,212 @@
+/*
+ *  *******************************************************************************
+ *  * Copyright (c) 2019 Edgeworx, Inc.
+ *  *
+ *  * This program and the accompanying materials are made available under the
+ *  * terms of the Eclipse Public License v. 2.0 which is available at
+ *  * http://www.eclipse.org/legal/epl-2.0
+ *  *
+ *  * SPDX-License-Identifier: EPL-2.0
+ *  *******************************************************************************
+ *
+ */
+
+package client_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/eclipse-iofog/iofog-go-sdk/v3/pkg/client"
+	"github.com/eclipse-iofog/iofog-go-sdk/v3/pkg/client/fake/faketest"
+)
+
+func TestWatchAgents(t *testing.T) {
+	ctrl, clt := faketest.NewLoggedIn(t, client.Options{})
+	existing, err := clt.CreateAgent(&client.CreateAgentRequest{AgentUpdateRequest: client.AgentUpdateRequest{Name: "existing"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	watcher, err := clt.Watch(ctx, client.WatchOptions{Interval: 5 * time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := watcher.WaitForSync(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if agents := watcher.ListAgents(); len(agents) != 1 || agents[0].Name != "existing" {
+		t.Errorf("Unexpected cached agents %v", agents)
+	}
+
+	expect := func(eventType client.EventType, key string) {
+		t.Helper()
+		select {
+		case event := <-watcher.Events():
+			if event.Type != eventType || event.Kind != client.KindAgent || event.Key != key {
+				t.Errorf("Expected %s event for %s, got %s %s %s", eventType, key, event.Type, event.Kind, event.Key)
+			}
+		case <-ctx.Done():
+			t.Fatalf("No %s event for %s", eventType, key)
+		}
+	}
+	expect(client.EventAdded, existing.UUID)
+
+	created, err := clt.CreateAgent(&client.CreateAgentRequest{AgentUpdateRequest: client.AgentUpdateRequest{Name: "created"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect(client.EventAdded, created.UUID)
+	// Telemetry refreshed by status reports is not a modification
+	_ = ctrl.UpdateAgent(existing.UUID, func(agent *client.AgentInfo) {
+		agent.LastActive = time.Now().UnixMilli()
+		agent.UptimeMs = 1000
+		agent.CPUUsage = 12.5
+	})
+	time.Sleep(50 * time.Millisecond)
+	select {
+	case event := <-watcher.Events():
+		t.Errorf("Unexpected %s event for %s after a telemetry update", event.Type, event.Key)
+	default:
+	}
+	_ = ctrl.UpdateAgent(existing.UUID, func(agent *client.AgentInfo) {
+		agent.DaemonStatus = client.AgentStatusRunning
+	})
+	expect(client.EventModified, existing.UUID)
+	if agent, ok := watcher.GetAgent(existing.UUID); !ok || agent.DaemonStatus != client.AgentStatusRunning {
+		t.Errorf("Cache was not updated: %+v", agent)
+	}
+	if err := clt.DeleteAgent(created.UUID); err != nil {
+		t.Fatal(err)
+	}
+	expect(client.EventDeleted, created.UUID)
+
+	cancel()
+	for range watcher.Events() {
+	}
+}
+
+func TestWatchKinds(t *testing.T) {
+	_, clt := faketest.NewLoggedIn(t, client.Options{})
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if _, err := clt.Watch(ctx, client.WatchOptions{Kinds: []client.ResourceKind{client.KindAgent, "Volume"}}); !errors.Is(err, client.ErrInput) {
+		t.Errorf("Expected unknown kind to be rejected, got %v", err)
+	}
+
+	// Duplicated kinds are watched once
+	watcher, err := clt.Watch(ctx, client.WatchOptions{
+		Kinds:    []client.ResourceKind{client.KindAgent, client.KindAgent},
+		Interval: 5 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := watcher.WaitForSync(ctx); err != nil {
+		t.Errorf("WaitForSync failed: %v", err)
+	}
+	cancel()
+	for range watcher.Events() {
+	}
+}
+
+func TestWatchErrorsPerKind(t *testing.T) {
+	ctrl, clt := faketest.NewLoggedIn(t, client.Options{})
+	ctrl.Intercept(func(w http.ResponseWriter, r *http.Request) bool {
+		if r.Method != http.MethodGet || r.URL.Path != "/api/v3/application" {
+			return false
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+		return true
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	// Agents are polled after Applications and keep succeeding
+	watcher, err := clt.Watch(ctx, client.WatchOptions{
+		Kinds:    []client.ResourceKind{client.KindApplication, client.KindAgent},
+		Interval: 5 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(50 * time.Millisecond)
+	if watcher.Err() == nil {
+		t.Error("Expected the Application error to be reported while Agents are polled successfully")
+	}
+	cancel()
+	for range watcher.Events() {
+	}
+}
+
+func TestWatchPartialMicroservices(t *testing.T) {
+	ctrl, clt := faketest.NewLoggedIn(t, client.Options{})
+	// Controllers before 2.0.2 list microservices flow by flow
+	ctrl.SetVersion("2.0.0")
+	clt.ResetCapabilities()
+	flowIDs := []string{}
+	for _, name := range []string{"flow-1", "flow-2"} {
+		flow, err := clt.CreateFlow(name, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		flowIDs = append(flowIDs, fmt.Sprint(flow.ID))
+	}
+	var failing atomic.Value
+	failing.Store(flowIDs[1])
+	ctrl.Intercept(func(w http.ResponseWriter, r *http.Request) bool {
+		flowID := r.URL.Query().Get("flowId")
+		if !strings.HasSuffix(r.URL.Path, "/microservices") || flowID == "" {
+			return false
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if flowID == failing.Load().(string) {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"name":"NotFoundError","message":"Invalid flow id"}`))
+			return true
+		}
+		_, _ = fmt.Fprintf(w, `{"microservices":[{"uuid":"msvc-%s","name":"msvc-%s","flowId":%s}]}`, flowID, flowID, flowID)
+		return true
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	watcher, err := clt.Watch(ctx, client.WatchOptions{Kinds: []client.ResourceKind{client.KindMicroservice}, Interval: 5 * time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The Microservices of the other flows are watched despite the failing flow
+	if err := watcher.WaitForSync(ctx); err != nil {
+		t.Fatal(err)
+	}
+	var listErr *client.ListError
+	if !errors.As(watcher.Err(), &listErr) {
+		t.Errorf("Expected the failing flow to be reported, got %v", watcher.Err())
+	}
+	if msvcs := watcher.ListMicroservices(""); len(msvcs) != 1 || msvcs[0].UUID != "msvc-"+flowIDs[0] {
+		t.Errorf("Expected the microservice of the first flow, got %+v", msvcs)
+	}
+
+	// Once listed again, the Microservices of the failing flow are added
+	failing.Store("")
+	for event := range watcher.Events() {
+		if event.Type == client.EventAdded && event.Key == "msvc-"+flowIDs[1] {
+			break
+		}
+	}
+	if ctx.Err() != nil {
+		t.Fatal("No event for the microservice of the second flow")
+	}
+	cancel()
+	for range watcher.Events() {
+	}
+}
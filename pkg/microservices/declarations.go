@@ -13,8 +13,6 @@
 package microservices
 
 import (
-	"log"
-	"os"
 	"time"
 )
 
@@ -54,10 +52,6 @@ const (
 	DEFAULT_RECEIPT_BUFFER_SIZE = 200
 )
 
-var (
-	logger = log.New(os.Stderr, "", log.LstdFlags)
-)
-
 type getConfigResponse struct {
 	Config string `json:"config"`
 }
@@ -29,6 +29,12 @@ type IoFogClient struct {
 func (client *IoFogClient) initClient(host string, port int, ssl bool) {
 	client.httpClient = newIoFogHttpClient(client.id, ssl, host, port)
 	client.wsClient = newIoFogWsClient(client.id, ssl, host, port)
+	client.wsClient.logger = defaultLogger
+}
+
+// SetLogger sets the Logger used by the WebSocket connections. It must be called before establishing them
+func (client *IoFogClient) SetLogger(logger Logger) {
+	client.wsClient.logger = logger
 }
 
 func NewIoFogClient(id string, ssl bool, host string, port int) (*IoFogClient, error) {
@@ -47,13 +53,13 @@ func NewDefaultIoFogClient() (*IoFogClient, error) {
 	}
 	ssl, err := strconv.ParseBool(os.Getenv(SSL))
 	if err != nil {
-		logger.Println("Empty or malformed", SSL, "environment variable. Using default value of", SSL_DEFAULT)
+		defaultLogger.Warn("Empty or malformed environment variable, using default value", "variable", SSL, "default", SSL_DEFAULT)
 		ssl = SSL_DEFAULT
 	}
 
 	host := IOFOG
 	if cmd := exec.Command("ping", "-c", "3", host); cmd.Run() != nil {
-		logger.Println("Host", host, "is unreachable. Switching to", HOST_DEFAULT)
+		defaultLogger.Warn("Host is unreachable, switching to default host", "host", host, "default", HOST_DEFAULT)
 		host = HOST_DEFAULT
 	}
 
@@ -0,0 +1,69 @@
+/*
+ *******************************************************************************
+ * Copyright (c) 2018 Edgeworx, Inc.
+ *
+ * This program and the accompanying materials are made available under the
+ * terms of the Eclipse Public License v. 2.0 which is available at
+ * http://www.eclipse.org/legal/epl-2.0
+ *
+ * SPDX-License-Identifier: EPL-2.0
+ *******************************************************************************
+ */
+
+package microservices
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/eclipse-iofog/iofog-go-sdk/v3/pkg/client"
+)
+
+// Logger receives structured records, args being alternating keys and values.
+// It is client.Logger, so the loggers of the Controller client, e.g. client.NewLogrLogger(...), can be used as is.
+type Logger = client.Logger
+
+var defaultLogger Logger = NewStdLogger(log.New(os.Stderr, "", log.LstdFlags))
+
+// SetDefaultLogger sets the Logger of clients created afterwards, stderr by default
+func SetDefaultLogger(logger Logger) {
+	defaultLogger = logger
+}
+
+// NewStdLogger adapts a standard library log.Logger, printing the level, message and key=value pairs
+func NewStdLogger(logger *log.Logger) Logger {
+	return stdLogger{logger: logger}
+}
+
+type stdLogger struct {
+	logger *log.Logger
+}
+
+func (l stdLogger) Debug(msg string, args ...interface{}) {
+	l.print("DEBUG", msg, args)
+}
+
+func (l stdLogger) Info(msg string, args ...interface{}) {
+	l.print("INFO", msg, args)
+}
+
+func (l stdLogger) Warn(msg string, args ...interface{}) {
+	l.print("WARN", msg, args)
+}
+
+func (l stdLogger) Error(msg string, args ...interface{}) {
+	l.print("ERROR", msg, args)
+}
+
+func (l stdLogger) print(level, msg string, args []interface{}) {
+	var builder strings.Builder
+	builder.WriteString(level)
+	builder.WriteString(" ")
+	builder.WriteString(msg)
+	for idx := 0; idx+1 < len(args); idx += 2 {
+		builder.WriteString(fmt.Sprintf(" %v=%v", args[idx], args[idx+1]))
+	}
+	l.logger.Println(builder.String())
+}
@@ -29,6 +29,7 @@ type ioFogWsClient struct {
 	wsControlAttempt    uint
 	wsMessageAttempt    uint
 	writeMessageChannel chan<- interface{}
+	logger              Logger
 }
 
 func newIoFogWsClient(id string, ssl bool, host string, port int) *ioFogWsClient {
@@ -53,7 +54,7 @@ func (client *ioFogWsClient) sendMessage(msg *IoMessage) (e error) {
 	}
 	defer func() {
 		if r := recover(); r != nil {
-			logger.Println(r)
+			client.logger.Error("Error while sending message", "error", r)
 			e = errors.New("Error while sending message")
 		}
 	}()
@@ -68,14 +69,14 @@ func (client *ioFogWsClient) connectToControlWs(signalChannel chan<- byte) {
 		}
 		conn, _, err := ws.DefaultDialer.Dial(client.url_get_control_ws, nil)
 		if conn == nil {
-			logger.Println(err.Error(), "Reconnecting to control ws...")
+			client.logger.Warn("Reconnecting to control ws", "error", err)
 			sleepTime := 1 << client.wsControlAttempt * WS_CONNECT_TIMEOUT
 			if client.wsControlAttempt < WS_ATTEMPT_LIMIT {
 				client.wsControlAttempt++
 			}
 			time.Sleep(sleepTime)
 		} else {
-			logger.Println("Control ws connection has been established.")
+			client.logger.Info("Control ws connection has been established")
 			client.wsControlAttempt = 0
 			client.wsControl = conn
 			setCustomPingHandler(client.wsControl)
@@ -87,7 +88,7 @@ func (client *ioFogWsClient) connectToControlWs(signalChannel chan<- byte) {
 			for {
 				select {
 				case <-errChanel:
-					logger.Println("Reconnecting after control ws corruption")
+					client.logger.Warn("Reconnecting after control ws corruption")
 					client.wsControl.Close()
 					break loop
 				}
@@ -103,14 +104,14 @@ func (client *ioFogWsClient) connectToMessageWs(messageChannel chan<- interface{
 		}
 		conn, _, err := ws.DefaultDialer.Dial(client.url_get_message_ws, nil)
 		if conn == nil {
-			logger.Println(err.Error(), "Reconnecting to message ws...")
+			client.logger.Warn("Reconnecting to message ws", "error", err)
 			sleepTime := 1 << client.wsMessageAttempt * WS_CONNECT_TIMEOUT
 			if client.wsMessageAttempt < WS_ATTEMPT_LIMIT {
 				client.wsMessageAttempt++
 			}
 			time.Sleep(sleepTime)
 		} else {
-			logger.Println("Message ws connection has been established.")
+			client.logger.Info("Message ws connection has been established")
 			client.wsMessageAttempt = 0
 			client.wsMessage = conn
 			setCustomPingHandler(client.wsMessage)
@@ -123,7 +124,7 @@ func (client *ioFogWsClient) connectToMessageWs(messageChannel chan<- interface{
 			for {
 				select {
 				case <-errChannel:
-					logger.Println("Reconnecting after message ws corruption")
+					client.logger.Warn("Reconnecting after message ws corruption")
 					client.wsMessage.Close()
 					break loop
 				}
@@ -136,7 +137,7 @@ func (client *ioFogWsClient) listenControlSocket(errChanel chan<- byte, signalCh
 	for {
 		_, p, err := client.wsControl.ReadMessage()
 		if err != nil {
-			logger.Println("Control ws read error:", err.Error())
+			client.logger.Warn("Control ws read error", "error", err)
 			errChanel <- 0
 			close(writeChannel)
 			return
@@ -152,7 +153,7 @@ func (client *ioFogWsClient) writeControlSocket(errChanel chan<- byte, writeChan
 	for data := range writeChannel {
 		err := client.wsControl.WriteMessage(ws.BinaryMessage, data)
 		if err != nil {
-			logger.Println("Control ws write error:", err.Error())
+			client.logger.Warn("Control ws write error", "error", err)
 			errChanel <- 0
 			return
 		}
@@ -163,7 +164,7 @@ func (client *ioFogWsClient) listenMessageSocket(errChanel chan<- byte, messageC
 	for {
 		_, p, err := client.wsMessage.ReadMessage()
 		if err != nil {
-			logger.Println("Message ws read error:", err.Error())
+			client.logger.Warn("Message ws read error", "error", err)
 			errChanel <- 0
 			close(writeChannel)
 			return
@@ -171,14 +172,14 @@ func (client *ioFogWsClient) listenMessageSocket(errChanel chan<- byte, messageC
 		if p[0] == CODE_MSG {
 			msg, err := GetMessageReceivedViaSocket(p)
 			if err != nil {
-				logger.Println(err.Error())
+				client.logger.Error("Failed to decode message", "error", err)
 			}
 			messageChannel <- msg
 			writeChannel <- []byte{CODE_ACK}
 		} else if p[0] == CODE_RECEIPT {
 			receiptResponse, err := getReceiptReceivedViaSocket(p)
 			if err != nil {
-				logger.Println(err.Error())
+				client.logger.Error("Failed to decode receipt", "error", err)
 			}
 			receiptChannel <- receiptResponse
 			writeChannel <- []byte{CODE_ACK}
@@ -190,7 +191,7 @@ func (client *ioFogWsClient) writeMessageSocket(errChanel chan<- byte, writeChan
 	for data := range writeChannel {
 		err := client.wsMessage.WriteMessage(ws.BinaryMessage, data.([]byte))
 		if err != nil {
-			logger.Println("Message ws write error:", err.Error())
+			client.logger.Warn("Message ws write error", "error", err)
 			errChanel <- 0
 			return
 		}